@@ -3,27 +3,46 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/verigate/verigate-server/internal/app/audit"
 	"github.com/verigate/verigate-server/internal/app/auth"
 	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/key"
 	"github.com/verigate/verigate-server/internal/app/oauth"
 	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/app/session"
 	"github.com/verigate/verigate-server/internal/app/token"
 	"github.com/verigate/verigate-server/internal/app/user"
 	"github.com/verigate/verigate-server/internal/pkg/config"
 	"github.com/verigate/verigate-server/internal/pkg/db/postgres"
 	"github.com/verigate/verigate-server/internal/pkg/db/redis"
+	"github.com/verigate/verigate-server/internal/pkg/health"
+	"github.com/verigate/verigate-server/internal/pkg/jobs"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
 	"github.com/verigate/verigate-server/internal/pkg/middleware"
+	"github.com/verigate/verigate-server/internal/pkg/server"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
 	"github.com/verigate/verigate-server/internal/pkg/utils/jwt"
 
 	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
 
+// tracingShutdownTimeout bounds how long the last spans may take to be exported on shutdown.
+const tracingShutdownTimeout = 5 * time.Second
+
 // main is the entry point for the Verigate Server API.
-// It initializes all components and starts the HTTP server.
+// It initializes all components and runs the HTTP server until the process is asked to stop,
+// then shuts down the server, the background workers and the database connections in that order.
 func main() {
 	// Configuration and logging
 	config.Load()
@@ -40,18 +59,28 @@ func main() {
 		sugar.Fatalf("Failed to initialize JWT keys: %v", err)
 	}
 
+	if err := config.AppConfig.ValidateTokenHashPepper(); err != nil {
+		sugar.Fatalf("Invalid configuration: %v", err)
+	} else if config.AppConfig.TokenHashPepper == "" {
+		sugar.Warn("TOKEN_HASH_PEPPER is not set; stored token digests are not keyed")
+	}
+
+	// Tracing, set up before the connections so that their calls are traced
+	shutdownTracing, err := tracing.Setup(context.Background(), logger)
+	if err != nil {
+		sugar.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
 	// Database connections
 	redisClient, err := redis.NewConnection()
 	if err != nil {
 		sugar.Fatalf("Failed to connect to Redis: %v", err)
 	}
-	defer redisClient.Close()
 
 	postgresDB, err := postgres.NewConnection()
 	if err != nil {
 		sugar.Fatalf("Failed to connect to PostgreSQL: %v", err)
 	}
-	defer postgresDB.Close()
 
 	// Repositories
 	userRepo := postgres.NewUserRepository(postgresDB)
@@ -59,30 +88,108 @@ func main() {
 	oauthRepo := postgres.NewOAuthRepository(postgresDB)
 	tokenRepo := postgres.NewTokenRepository(postgresDB)
 	scopeRepo := postgres.NewScopeRepository(postgresDB)
+	keyRepo := postgres.NewKeyRepository(postgresDB)
+	auditRepo := postgres.NewAuditRepository(postgresDB)
 	cacheRepo := redis.NewCacheRepository(redisClient)
 	authRepo := redis.NewAuthRepository(redisClient) // Added
+	flowRepo := redis.NewFlowRepository(redisClient)
+	sessionRepo := redis.NewSessionRepository(redisClient)
+
+	// Signing keys: replace the static key with the persisted key set when rotation is enabled
+	keyService := key.NewService(keyRepo)
+	if err := keyService.Initialize(context.Background()); err != nil {
+		sugar.Fatalf("Failed to initialize signing keys: %v", err)
+	}
+
+	keyCtx, stopKeyMaintenance := context.WithCancel(context.Background())
+	keyMaintenanceDone := make(chan struct{})
+	go func() {
+		defer close(keyMaintenanceDone)
+		keyService.Run(keyCtx, func(err error) {
+			sugar.Errorf("Signing key maintenance failed: %v", err)
+		})
+	}()
 
 	// Services
-	authService := auth.NewService(authRepo)                    // Added
+	sessionService := session.NewService(sessionRepo)
+	authService := auth.NewService(authRepo, sessionService)    // Added
 	userService := user.NewService(userRepo, authService)       // Modified
 	clientService := client.NewService(clientRepo, authService) // Modified
 	scopeService := scope.NewService(scopeRepo)
-	tokenService := token.NewService(tokenRepo, cacheRepo, authService, clientService)                               // Modified
-	oauthService := oauth.NewService(oauthRepo, userService, clientService, tokenService, scopeService, authService) // Modified
+	auditService := audit.NewService(auditRepo)
+	tokenService := token.NewService(tokenRepo, cacheRepo, authService, clientService, scopeService)                                                         // Modified
+	oauthService := oauth.NewService(oauthRepo, flowRepo, userService, clientService, tokenService, scopeService, authService, sessionService, auditService) // Modified
+
+	// Background maintenance jobs, run by one replica at a time
+	scheduler := setupJobs(logger, postgres.NewAdvisoryLocker(postgresDB), postgres.NewJobRunRepository(postgresDB), oauthService, tokenService, authService)
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		scheduler.Run(jobCtx)
+	}()
+
+	// Metrics of the connection pools and background jobs
+	metrics.RegisterPostgres(postgresDB)
+	metrics.RegisterRedis(redisClient)
+	metrics.RegisterJobs(scheduler)
+
+	// Readiness checks of the dependencies
+	healthChecker := setupHealthChecks(logger, postgresDB, redisClient)
 
 	// Handlers
 	userHandler := user.NewHandler(userService)
 	clientHandler := client.NewHandler(clientService)
 	tokenHandler := token.NewHandler(tokenService)
 	oauthHandler := oauth.NewHandler(oauthService)
+	keyHandler := key.NewHandler(keyService)
+	auditHandler := audit.NewHandler(auditService)
+	jobsHandler := jobs.NewHandler(scheduler)
+	healthHandler := health.NewHandler(healthChecker)
 
 	// Router setup
-	router := setupRouter(logger, userHandler, clientHandler, tokenHandler, oauthHandler)
+	router := setupRouter(logger, userHandler, clientHandler, tokenHandler, oauthHandler, keyHandler, auditHandler, jobsHandler, healthHandler)
+
+	srv, err := server.New(router, logger)
+	if err != nil {
+		sugar.Fatalf("Failed to configure server: %v", err)
+	}
+	srv.OnDrain(healthChecker.Drain)
+
+	// Serve until SIGINT or SIGTERM, then let in-flight requests finish
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	exitCode := 0
+	if err := srv.Run(signalCtx); err != nil {
+		sugar.Errorf("Server error: %v", err)
+		exitCode = 1
+	}
+
+	// Stop background work before closing the connections it uses
+	stopJobs()
+	<-jobsDone
+	stopKeyMaintenance()
+	<-keyMaintenanceDone
+
+	if err := postgresDB.Close(); err != nil {
+		sugar.Errorf("Failed to close PostgreSQL connection: %v", err)
+	}
+	if err := redisClient.Close(); err != nil {
+		sugar.Errorf("Failed to close Redis connection: %v", err)
+	}
 
-	// Start server
-	sugar.Infof("Starting server on port %s", config.AppConfig.AppPort)
-	if err := router.Run(":" + config.AppConfig.AppPort); err != nil {
-		sugar.Fatalf("Failed to start server: %v", err)
+	// Send the spans still buffered
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	if err := shutdownTracing(tracingCtx); err != nil {
+		sugar.Errorf("Failed to flush traces: %v", err)
+	}
+	cancelTracing()
+
+	sugar.Info("Server stopped")
+	if exitCode != 0 {
+		logger.Sync()
+		os.Exit(exitCode)
 	}
 }
 
@@ -101,6 +208,76 @@ func setupLogger() (*zap.Logger, error) {
 	return zapConfig.Build()
 }
 
+// setupJobs registers the background maintenance jobs with their configured intervals.
+// It exits the application if an interval or the token retention is not a valid duration.
+func setupJobs(logger *zap.Logger, locker jobs.Locker, runs jobs.RunRecorder, oauthService *oauth.Service, tokenService *token.Service, authService *auth.Service) *jobs.Scheduler {
+	sugar := logger.Sugar()
+	parse := func(name, value string) time.Duration {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			sugar.Fatalf("Invalid %s: %v", name, err)
+		}
+		return duration
+	}
+
+	tokenRetention := parse("TOKEN_RETENTION", config.AppConfig.TokenRetention)
+
+	scheduler := jobs.NewScheduler(locker, runs, logger)
+	scheduler.Add(jobs.Job{
+		Name:     "purge_authorization_codes",
+		Interval: parse("CODE_CLEANUP_INTERVAL", config.AppConfig.CodeCleanupInterval),
+		Run:      oauthService.PurgeExpiredCodes,
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "purge_tokens",
+		Interval: parse("TOKEN_CLEANUP_INTERVAL", config.AppConfig.TokenCleanupInterval),
+		Run: func(ctx context.Context) (int64, error) {
+			return tokenService.PurgeTokens(ctx, tokenRetention)
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "prune_web_session_tokens",
+		Interval: parse("SESSION_CLEANUP_INTERVAL", config.AppConfig.SessionCleanupInterval),
+		Run:      authService.DeleteExpiredTokens,
+	})
+
+	return scheduler
+}
+
+// setupHealthChecks creates the readiness checker with checks for PostgreSQL, Redis,
+// the database schema version and the signing keys.
+// It exits the application if the checks cannot be configured.
+func setupHealthChecks(logger *zap.Logger, postgresDB *sql.DB, redisClient *goredis.Client) *health.Checker {
+	sugar := logger.Sugar()
+
+	timeout, err := time.ParseDuration(config.AppConfig.HealthCheckTimeout)
+	if err != nil {
+		sugar.Fatalf("Invalid HEALTH_CHECK_TIMEOUT: %v", err)
+	}
+
+	schemaVersion, err := postgres.LatestMigrationVersion()
+	if err != nil {
+		sugar.Fatalf("Failed to determine the expected schema version: %v", err)
+	}
+
+	checker := health.NewChecker(timeout, logger)
+	checker.Add("postgres", postgresDB.PingContext)
+	checker.Add("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	checker.Add("migrations", func(ctx context.Context) error {
+		return postgres.CheckSchemaVersion(ctx, postgresDB, schemaVersion)
+	})
+	checker.Add("signing_keys", func(ctx context.Context) error {
+		if !jwt.HasKeys() {
+			return errors.New("no active signing key")
+		}
+		return nil
+	})
+
+	return checker
+}
+
 // setupRouter configures the HTTP router with all routes and middleware.
 // It registers all handlers, sets up middleware for logging, error handling, rate limiting,
 // CORS, and recovery from panics.
@@ -111,6 +288,10 @@ func setupRouter(
 	clientHandler *client.Handler,
 	tokenHandler *token.Handler,
 	oauthHandler *oauth.Handler,
+	keyHandler *key.Handler,
+	auditHandler *audit.Handler,
+	jobsHandler *jobs.Handler,
+	healthHandler *health.Handler,
 ) *gin.Engine {
 	if config.AppConfig.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -119,8 +300,10 @@ func setupRouter(
 	router := gin.New()
 
 	// Middleware
+	router.Use(middleware.Tracing())
 	router.Use(middleware.RequestLogger(logger))
 	router.Use(middleware.Recovery(logger))
+	router.Use(middleware.Metrics())
 	router.Use(middleware.CORS())
 	router.Use(middleware.ErrorHandler())
 
@@ -138,6 +321,9 @@ func setupRouter(
 		config.AppConfig.IPBlacklist,
 	)
 
+	// Probes are registered before IP control, so that they are reachable from the orchestrator
+	healthHandler.RegisterRoutes(router)
+
 	// Apply middleware
 	router.Use(middleware.IPControlMiddleware(ipControl))
 
@@ -155,6 +341,7 @@ func setupRouter(
 		userGroup := api.Group("/users")
 		{
 			userHandler.RegisterRoutes(userGroup)
+			oauthHandler.RegisterConsentRoutes(userGroup.Group("/me/consents"))
 		}
 
 		// Client endpoints
@@ -168,8 +355,27 @@ func setupRouter(
 		{
 			tokenHandler.RegisterRoutes(tokenGroup)
 		}
+
+		// Administrative endpoints (admin API key required)
+		adminGroup := api.Group("/admin")
+		adminGroup.Use(middleware.AdminAuth(config.AppConfig.AdminAPIKey))
+		{
+			keyHandler.RegisterRoutes(adminGroup.Group("/keys"))
+			clientHandler.RegisterAdminRoutes(adminGroup.Group("/clients"))
+			auditHandler.RegisterRoutes(adminGroup.Group("/audit-logs"))
+			jobsHandler.RegisterRoutes(adminGroup.Group("/jobs"))
+		}
 	}
 
+	// Authorization server metadata (OpenID Connect Discovery and RFC 8414)
+	wellKnownGroup := router.Group("/.well-known")
+	{
+		oauthHandler.RegisterWellKnownRoutes(wellKnownGroup)
+	}
+
+	// Prometheus metrics, reachable only from addresses allowed by IP control
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -0,0 +1,21 @@
+// Package audit records security-relevant events, such as consent decisions,
+// so that administrators and support staff can reconstruct what happened and why.
+package audit
+
+// ListRequest filters the audit log. Empty fields match all entries.
+type ListRequest struct {
+	ActorID      *uint  `form:"actor_id"`      // Only entries of this user
+	Action       string `form:"action"`        // Only entries with this action
+	ResourceType string `form:"resource_type"` // Only entries for this kind of resource
+	ResourceID   string `form:"resource_id"`   // Only entries for this resource
+	Page         int    `form:"page"`          // Page number, starting at 1
+	Limit        int    `form:"limit"`         // Number of entries per page
+}
+
+// ListResponse wraps a paginated list of audit log entries, most recent first.
+type ListResponse struct {
+	Logs    []Log `json:"logs"`     // Audit log entries
+	Total   int64 `json:"total"`    // Total number of entries matching the filter
+	Page    int   `json:"page"`     // Current page number
+	PerPage int   `json:"per_page"` // Number of entries per page
+}
@@ -0,0 +1,50 @@
+// Package audit records security-relevant events, such as consent decisions,
+// so that administrators and support staff can reconstruct what happened and why.
+package audit
+
+import (
+	"net/http"
+
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages administrative HTTP requests for the audit log.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new audit log handler with the given service.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the audit log routes on the provided router group.
+// The group is expected to be protected by admin authentication.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("", h.List) // Search the audit log
+}
+
+// List handles the GET request to search the audit log.
+//
+// Route: GET /admin/audit-logs
+// Query parameters:
+//   - actor_id, action, resource_type, resource_id: Optional filters
+//   - page: Page number (default: 1)
+//   - limit: Number of entries per page (default: 50, max: 200)
+func (h *Handler) List(c *gin.Context) {
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
+		return
+	}
+
+	logs, err := h.service.List(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
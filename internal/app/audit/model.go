@@ -0,0 +1,30 @@
+// Package audit records security-relevant events, such as consent decisions,
+// so that administrators and support staff can reconstruct what happened and why.
+package audit
+
+import (
+	"time"
+)
+
+// Actor types recorded with audit log entries
+const (
+	ActorTypeUser   = "user"   // End user acting through the web app or the hosted pages
+	ActorTypeClient = "client" // OAuth client acting on its own behalf
+	ActorTypeAdmin  = "admin"  // Administrator using the admin API
+)
+
+// Log represents an entry of the audit log.
+type Log struct {
+	ID             uint                   `json:"id"`                        // Primary key
+	ActorID        *uint                  `json:"actor_id,omitempty"`        // User who performed the action, if any
+	ActorType      string                 `json:"actor_type,omitempty"`      // Kind of actor (user, client or admin)
+	Action         string                 `json:"action"`                    // What happened, e.g. "consent.decision"
+	ResourceType   string                 `json:"resource_type"`             // Kind of resource acted on, e.g. "client"
+	ResourceID     string                 `json:"resource_id,omitempty"`     // Identifier of the resource
+	Description    string                 `json:"description,omitempty"`     // Human-readable explanation
+	IPAddress      string                 `json:"ip_address,omitempty"`      // Client IP address of the request
+	UserAgent      string                 `json:"user_agent,omitempty"`      // User agent of the request
+	Status         string                 `json:"status"`                    // Outcome of the action
+	AdditionalData map[string]interface{} `json:"additional_data,omitempty"` // Action-specific details
+	CreatedAt      time.Time              `json:"created_at"`                // When the action happened
+}
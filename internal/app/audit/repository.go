@@ -0,0 +1,16 @@
+// Package audit records security-relevant events, such as consent decisions,
+// so that administrators and support staff can reconstruct what happened and why.
+package audit
+
+import (
+	"context"
+)
+
+// Repository defines the interface for audit log storage.
+type Repository interface {
+	// Save appends an entry to the audit log
+	Save(ctx context.Context, log *Log) error
+
+	// Find retrieves a page of entries matching the filter, most recent first, and the total number of matches
+	Find(ctx context.Context, filter ListRequest) ([]Log, int64, error)
+}
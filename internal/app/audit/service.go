@@ -0,0 +1,47 @@
+// Package audit records security-relevant events, such as consent decisions,
+// so that administrators and support staff can reconstruct what happened and why.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Service records and retrieves audit log entries.
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new audit service.
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record appends an entry to the audit log, stamped with the current time.
+func (s *Service) Record(ctx context.Context, log *Log) error {
+	log.CreatedAt = time.Now()
+	return s.repo.Save(ctx, log)
+}
+
+// List returns a page of audit log entries matching the request.
+// The page defaults to 1 and the limit to 50, with at most 200 entries per page.
+func (s *Service) List(ctx context.Context, req ListRequest) (*ListResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 200 {
+		req.Limit = 50
+	}
+
+	logs, total, err := s.repo.Find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResponse{
+		Logs:    logs,
+		Total:   total,
+		Page:    req.Page,
+		PerPage: req.Limit,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/session"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
+)
+
+// newTestService creates an auth service backed by in-memory repositories and a fresh signing key.
+func newTestService(t *testing.T) (*Service, *fakeAuthRepository, *session.Service) {
+	t.Helper()
+
+	config.AppConfig.JWTAccessExpiry = "15m"
+	config.AppConfig.JWTRefreshExpiry = "168h"
+	config.AppConfig.SessionLifetime = "24h"
+	config.AppConfig.TokenHashPepper = "test-pepper"
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwtutil.NewSigningKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jwtutil.SetKeys([]*jwtutil.SigningKey{key}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newFakeAuthRepository()
+	sessionService := session.NewService(newFakeSessionRepository())
+	return NewService(repo, sessionService), repo, sessionService
+}
+
+// fakeAuthRepository is an in-memory Repository that ignores expiration.
+type fakeAuthRepository struct {
+	mu              sync.Mutex
+	tokens          map[string]*RefreshToken
+	revokedSessions map[string]bool
+}
+
+func newFakeAuthRepository() *fakeAuthRepository {
+	return &fakeAuthRepository{
+		tokens:          make(map[string]*RefreshToken),
+		revokedSessions: make(map[string]bool),
+	}
+}
+
+func (r *fakeAuthRepository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *token
+	r.tokens[token.ID] = &saved
+	return nil
+}
+
+func (r *fakeAuthRepository) FindRefreshToken(ctx context.Context, tokenID string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.tokens[tokenID]; ok {
+		found := *token
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeAuthRepository) FindRefreshTokenByToken(ctx context.Context, plainTextToken string) (*RefreshToken, error) {
+	return nil, nil
+}
+
+func (r *fakeAuthRepository) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenID]
+	if !ok {
+		return errors.NotFound(errors.ErrMsgTokenNotFound)
+	}
+	token.IsRevoked = true
+	return nil
+}
+
+func (r *fakeAuthRepository) FindUserRefreshTokens(ctx context.Context, userID uint) ([]RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeAuthRepository) MarkSessionRevoked(ctx context.Context, sessionID string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedSessions[sessionID] = true
+	return nil
+}
+
+func (r *fakeAuthRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.revokedSessions[sessionID], nil
+}
+
+func (r *fakeAuthRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeAuthRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeAuthRepository) IsRefreshTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenID]
+	return !ok || token.IsRevoked, nil
+}
+
+// fakeSessionRepository is an in-memory session.Repository.
+type fakeSessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]*session.Session
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{sessions: make(map[string]*session.Session)}
+}
+
+func (r *fakeSessionRepository) Save(ctx context.Context, s *session.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *s
+	r.sessions[s.ID] = &saved
+	return nil
+}
+
+func (r *fakeSessionRepository) FindByID(ctx context.Context, id string) (*session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		found := *s
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeSessionRepository) FindByUserID(ctx context.Context, userID uint) ([]session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []session.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	return nil
+}
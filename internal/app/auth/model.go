@@ -10,15 +10,47 @@ import (
 // RefreshToken defines a refresh token used for the web app authentication system.
 // This is kept separate from the OAuth token system to provide independent
 // authentication mechanisms for platform users versus OAuth clients.
+// Every rotation issues a new token within the same session.
 type RefreshToken struct {
-	ID        string    `json:"id"`                   // Unique identifier for the token
-	UserID    uint      `json:"user_id"`              // User the token was issued to
-	Token     string    `json:"token"`                // Hashed token value, stored in Redis but not returned to clients
-	ExpiresAt time.Time `json:"expires_at"`           // Expiration timestamp
-	CreatedAt time.Time `json:"created_at"`           // Creation timestamp
-	IsRevoked bool      `json:"is_revoked"`           // Whether the token has been revoked
-	UserAgent string    `json:"user_agent,omitempty"` // Client user agent for audit
-	IPAddress string    `json:"ip_address,omitempty"` // Client IP address for audit
+	ID               string    `json:"id"`                           // Unique identifier for the token
+	UserID           uint      `json:"user_id"`                      // User the token was issued to
+	Token            string    `json:"token"`                        // Digest of the token secret (bcrypt hash for legacy tokens), stored in Redis but not returned to clients
+	SessionID        string    `json:"session_id,omitempty"`         // Login session the token belongs to; kept across rotations
+	SessionCreatedAt time.Time `json:"session_created_at,omitempty"` // When the user logged in to start the session
+	ExpiresAt        time.Time `json:"expires_at"`                   // Expiration timestamp
+	CreatedAt        time.Time `json:"created_at"`                   // Creation timestamp
+	IsRevoked        bool      `json:"is_revoked"`                   // Whether the token has been revoked
+	UserAgent        string    `json:"user_agent,omitempty"`         // Client user agent for audit
+	IPAddress        string    `json:"ip_address,omitempty"`         // Client IP address for audit
+}
+
+// session returns the ID and start time of the login session of a token.
+// Tokens issued before sessions were tracked form a session of their own.
+func (t *RefreshToken) session() (string, time.Time) {
+	if t.SessionID == "" {
+		return t.ID, t.CreatedAt
+	}
+	return t.SessionID, t.SessionCreatedAt
+}
+
+// Session kinds
+const (
+	SessionKindToken   = "token"   // Web app login that keeps refreshing its tokens
+	SessionKindBrowser = "browser" // Hosted login held in a session cookie
+)
+
+// Session describes an active login of a user. A token session is one device or browser that
+// logged in to the web app and keeps refreshing its tokens; it is derived from the session's
+// latest refresh token. A browser session is a login at the hosted login pages.
+type Session struct {
+	ID         string    // Login session identifier; the sid claim of access tokens, or the public ID of a browser session
+	Kind       string    // SessionKindToken or SessionKindBrowser
+	UserID     uint      // User who logged in
+	UserAgent  string    // User agent of the most recent login or refresh
+	IPAddress  string    // IP address of the most recent login or refresh
+	CreatedAt  time.Time // When the user logged in
+	LastUsedAt time.Time // When tokens were last issued for the session
+	ExpiresAt  time.Time // When the session ends unless its tokens are refreshed
 }
 
 // TokenPair represents an access token and refresh token pair
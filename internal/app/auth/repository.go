@@ -5,6 +5,7 @@ package auth
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for authentication-related data storage and retrieval.
@@ -19,8 +20,9 @@ type Repository interface {
 	FindRefreshToken(ctx context.Context, tokenID string) (*RefreshToken, error)
 
 	// FindRefreshTokenByToken finds a refresh token by its plain text token value.
-	// It scans all tokens and compares the input with stored hashed values.
-	// This is used during token refresh operations.
+	// It scans all tokens and compares the input with stored bcrypt hashes.
+	// This is only used for legacy tokens issued before refresh tokens carried their ID;
+	// current tokens are fetched with FindRefreshToken.
 	// Returns nil if the token doesn't exist.
 	FindRefreshTokenByToken(ctx context.Context, plainTextToken string) (*RefreshToken, error)
 
@@ -28,13 +30,23 @@ type Repository interface {
 	// It should return an error if the token doesn't exist.
 	RevokeRefreshToken(ctx context.Context, tokenID string) error
 
+	// FindUserRefreshTokens retrieves all unexpired refresh tokens of a user, including revoked ones.
+	FindUserRefreshTokens(ctx context.Context, userID uint) ([]RefreshToken, error)
+
+	// MarkSessionRevoked records that a login session has ended, so that access tokens
+	// issued for it are rejected. The mark only needs to outlive those tokens.
+	MarkSessionRevoked(ctx context.Context, sessionID string, ttl time.Duration) error
+
+	// IsSessionRevoked checks whether a login session has been marked as revoked.
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+
 	// RevokeAllUserRefreshTokens revokes all refresh tokens for a user.
 	// This is typically used during logout or password change operations.
 	RevokeAllUserRefreshTokens(ctx context.Context, userID uint) error
 
-	// DeleteExpiredTokens removes expired tokens.
+	// DeleteExpiredTokens removes expired tokens and returns the number of tokens removed.
 	// This is a maintenance operation that should be performed periodically.
-	DeleteExpiredTokens(ctx context.Context) error
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
 
 	// IsRefreshTokenRevoked checks if a refresh token has been revoked.
 	// Returns true if the token is revoked or doesn't exist.
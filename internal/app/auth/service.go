@@ -6,10 +6,14 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/verigate/verigate-server/internal/app/session"
 	"github.com/verigate/verigate-server/internal/pkg/config"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 	"github.com/verigate/verigate-server/internal/pkg/utils/hash"
@@ -21,16 +25,25 @@ import (
 // as well as other authentication-related operations.
 type Service struct {
 	repo              Repository
+	sessionService    *session.Service // Browser login sessions of the hosted login pages
 	accessExpiry      time.Duration
 	refreshExpiry     time.Duration
 	accessTokenIssuer string
+	tokenHashPepper   string // Key of the refresh token digests
+	legacyLookup      bool   // Whether refresh tokens in the old format are still accepted
 }
 
+// refreshTokenSeparator separates the token ID from the secret in a refresh token.
+// It does not occur in legacy tokens, which are plain base64url strings.
+const refreshTokenSeparator = "."
+
 // NewService creates a new authentication service instance.
 // It initializes the service with token expiration settings
 // loaded from the application configuration.
+// The session service gives access to the users' hosted login sessions, so that they
+// are listed and revoked together with their web app sessions.
 // Note: The RSA keys are managed centrally by the JWT utility package.
-func NewService(repo Repository) *Service {
+func NewService(repo Repository, sessionService *session.Service) *Service {
 	// JWT keys are now initialized in main.go via jwt.InitKeys()
 
 	// Parse expiry durations
@@ -46,52 +59,62 @@ func NewService(repo Repository) *Service {
 
 	return &Service{
 		repo:              repo,
+		sessionService:    sessionService,
 		accessExpiry:      accessExpiry,
 		refreshExpiry:     refreshExpiry,
 		accessTokenIssuer: "verigate-web", // Distinct from OAuth tokens
+		tokenHashPepper:   config.AppConfig.TokenHashPepper,
+		legacyLookup:      config.AppConfig.LegacyRefreshTokenLookup,
 	}
 }
 
-// CreateTokenPair generates an access token and refresh token pair for a user.
-// The access token is a JWT with user identity claims, and the refresh token
-// is a secure random string that can be exchanged for a new token pair.
+// CreateTokenPair starts a new login session and generates its first access token and
+// refresh token pair. The access token is a JWT with user identity claims, and the refresh token
+// is the token ID and a secure random secret, joined by a dot, that can be exchanged for a new
+// token pair.
 // User agent and IP address are stored for audit purposes.
 func (s *Service) CreateTokenPair(ctx context.Context, userID uint, userAgent, ipAddress string) (*TokenPair, error) {
+	return s.issueTokenPair(ctx, userID, uuid.New().String(), time.Now(), userAgent, ipAddress)
+}
+
+// issueTokenPair generates an access token and refresh token pair within a login session.
+// The session ID is carried by the refresh token and as the sid claim of the access token.
+func (s *Service) issueTokenPair(ctx context.Context, userID uint, sessionID string, sessionCreatedAt time.Time, userAgent, ipAddress string) (*TokenPair, error) {
 	// Generate access token
 	tokenID := uuid.New().String()
 	now := time.Now()
 
 	// Use the GenerateCustomToken function from JWT utility package
-	accessToken, err := jwtutil.GenerateCustomToken(userID, s.accessTokenIssuer, jwtutil.TokenTypeAccess, tokenID, s.accessExpiry)
+	accessToken, err := jwtutil.GenerateCustomToken(userID, s.accessTokenIssuer, jwtutil.TokenTypeAccess, tokenID, sessionID, s.accessExpiry)
 	if err != nil {
 		return nil, errors.Internal(errors.ErrMsgFailedToGenerateAccessToken)
 	}
 
-	// Generate refresh token
+	// Generate refresh token; the ID in front of the secret finds it without a scan
 	refreshTokenID := uuid.New().String()
 	refreshTokenBytes := make([]byte, 32)
 	if _, err := rand.Read(refreshTokenBytes); err != nil {
 		return nil, errors.Internal(errors.ErrMsgFailedToGenerateRefreshToken)
 	}
-	refreshToken := base64.URLEncoding.EncodeToString(refreshTokenBytes)
+	secret := base64.RawURLEncoding.EncodeToString(refreshTokenBytes)
+	refreshToken := refreshTokenID + refreshTokenSeparator + secret
 	refreshExpiry := now.Add(s.refreshExpiry)
 
-	// Hash the refresh token
-	hashedRefreshToken, err := hash.HashPassword(refreshToken)
-	if err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToHashRefreshToken)
-	}
+	// Only a keyed digest of the secret is stored
+	hashedRefreshToken := hash.Token(secret, s.tokenHashPepper)
 
 	// Store the refresh token
 	refreshTokenModel := &RefreshToken{
-		ID:        refreshTokenID,
-		UserID:    userID,
-		Token:     hashedRefreshToken,
-		ExpiresAt: refreshExpiry,
-		CreatedAt: now,
-		IsRevoked: false,
-		UserAgent: userAgent,
-		IPAddress: ipAddress,
+		ID:               refreshTokenID,
+		UserID:           userID,
+		Token:            hashedRefreshToken,
+		SessionID:        sessionID,
+		SessionCreatedAt: sessionCreatedAt,
+		ExpiresAt:        refreshExpiry,
+		CreatedAt:        now,
+		IsRevoked:        false,
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
 	}
 
 	if err := s.repo.SaveRefreshToken(ctx, refreshTokenModel); err != nil {
@@ -107,10 +130,11 @@ func (s *Service) CreateTokenPair(ctx context.Context, userID uint, userAgent, i
 }
 
 // RefreshTokens uses a refresh token to issue a new token pair (Refresh Token Rotation pattern).
-// It validates the provided refresh token, revokes it, and generates a new token pair.
+// It validates the provided refresh token, revokes it, and generates a new token pair
+// within the same login session.
 func (s *Service) RefreshTokens(ctx context.Context, refreshToken, userAgent, ipAddress string) (*TokenPair, error) {
 	// Find the refresh token
-	token, err := s.repo.FindRefreshTokenByToken(ctx, refreshToken)
+	token, err := s.findRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -119,10 +143,15 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken, userAgent, ip
 		return nil, errors.Unauthorized(errors.ErrMsgInvalidToken)
 	}
 
+	sessionID, sessionCreatedAt := token.session()
+
 	// Validate token
 	if token.IsRevoked {
-		// If token is revoked, revoke all user tokens for security
-		s.repo.RevokeAllUserRefreshTokens(ctx, token.UserID)
+		// A revoked token being reused may have been stolen, and the thief may have used it
+		// to log in elsewhere, so end all of the user's sessions for security
+		if err := s.RevokeAllUserRefreshTokens(ctx, token.UserID); err != nil {
+			// Not critical, continue
+		}
 		return nil, errors.Unauthorized(errors.ErrMsgTokenRevoked)
 	}
 
@@ -136,14 +165,147 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken, userAgent, ip
 	}
 
 	// Create new token pair
-	return s.CreateTokenPair(ctx, token.UserID, userAgent, ipAddress)
+	return s.issueTokenPair(ctx, token.UserID, sessionID, sessionCreatedAt, userAgent, ipAddress)
 }
 
-// ValidateAccessToken validates an access token and returns the user ID.
-// It checks the token's signature, expiration, issuer, and type.
-func (s *Service) ValidateAccessToken(tokenString string) (uint, error) {
+// findRefreshToken looks up a refresh token by its plain text value.
+// Tokens in the current format are fetched by their ID and verified against the stored digest
+// in constant time. Tokens issued before that format have no ID and are searched for
+// by comparing bcrypt hashes, unless the legacy lookup has been turned off.
+// Returns nil if the token doesn't exist or the secret doesn't match.
+func (s *Service) findRefreshToken(ctx context.Context, plainTextToken string) (*RefreshToken, error) {
+	tokenID, secret, ok := strings.Cut(plainTextToken, refreshTokenSeparator)
+	if !ok {
+		if !s.legacyLookup {
+			return nil, nil
+		}
+		return s.repo.FindRefreshTokenByToken(ctx, plainTextToken)
+	}
+
+	token, err := s.repo.FindRefreshToken(ctx, tokenID)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if !hash.CompareToken(token.Token, secret, s.tokenHashPepper) {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// ValidateAccessToken validates an access token and returns the user ID and login session ID.
+// It checks the token's signature, expiration, issuer, and type, and that its session
+// has not been revoked. Tokens issued before sessions were tracked have no session ID.
+func (s *Service) ValidateAccessToken(ctx context.Context, tokenString string) (uint, string, error) {
 	// Use the common JWT utility for consistent token validation
-	return jwtutil.ValidateAccessTokenWithClaims(tokenString, s.accessTokenIssuer)
+	userID, sessionID, err := jwtutil.ValidateAccessTokenWithClaims(tokenString, s.accessTokenIssuer)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if sessionID != "" {
+		revoked, err := s.repo.IsSessionRevoked(ctx, sessionID)
+		if err != nil {
+			return 0, "", err
+		}
+		if revoked {
+			return 0, "", errors.Unauthorized(errors.ErrMsgWebSessionRevoked)
+		}
+	}
+
+	return userID, sessionID, nil
+}
+
+// ListSessions returns the active login sessions of a user, most recently used first.
+// A token session is active while its latest refresh token is neither revoked nor expired,
+// a browser session until it expires. Browser sessions were last used when the user logged in.
+func (s *Service) ListSessions(ctx context.Context, userID uint) ([]Session, error) {
+	tokens, err := s.repo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	latest := make(map[string]Session)
+	for _, token := range tokens {
+		if token.IsRevoked || now.After(token.ExpiresAt) {
+			continue
+		}
+
+		sessionID, sessionCreatedAt := token.session()
+		if current, ok := latest[sessionID]; ok && !token.CreatedAt.After(current.LastUsedAt) {
+			continue
+		}
+		latest[sessionID] = Session{
+			ID:         sessionID,
+			Kind:       SessionKindToken,
+			UserID:     token.UserID,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  sessionCreatedAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+		}
+	}
+
+	browserSessions, err := s.browserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(latest)+len(browserSessions))
+	for _, session := range latest {
+		sessions = append(sessions, session)
+	}
+	for _, browserSession := range browserSessions {
+		sessions = append(sessions, Session{
+			ID:         browserSession.PublicID(),
+			Kind:       SessionKindBrowser,
+			UserID:     browserSession.UserID,
+			UserAgent:  browserSession.UserAgent,
+			IPAddress:  browserSession.IPAddress,
+			CreatedAt:  browserSession.CreatedAt,
+			LastUsedAt: browserSession.AuthTime,
+			ExpiresAt:  browserSession.ExpiresAt,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession ends one login session of a user. For a token session its refresh tokens are
+// revoked and its access tokens are rejected from now on; a browser session, identified by its
+// public ID, is deleted. Returns a not found error if the user has no active session with the given ID.
+func (s *Service) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	tokens, err := s.repo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var active []string
+	for _, token := range tokens {
+		if id, _ := token.session(); id == sessionID && !token.IsRevoked {
+			active = append(active, token.ID)
+		}
+	}
+	if len(active) == 0 {
+		return s.revokeBrowserSession(ctx, userID, sessionID)
+	}
+
+	// Reject access tokens first, so the session cannot be used while its tokens are revoked
+	if err := s.repo.MarkSessionRevoked(ctx, sessionID, s.accessExpiry); err != nil {
+		return err
+	}
+	for _, tokenID := range active {
+		if err := s.repo.RevokeRefreshToken(ctx, tokenID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // RevokeRefreshToken revokes a specific refresh token.
@@ -153,7 +315,63 @@ func (s *Service) RevokeRefreshToken(ctx context.Context, tokenID string) error
 }
 
 // RevokeAllUserRefreshTokens revokes all refresh tokens for a user.
-// It marks all tokens associated with the user as revoked in the repository.
+// It marks all tokens associated with the user as revoked in the repository,
+// and all of the user's login sessions, so that their access tokens are rejected too.
+// The user's browser sessions are deleted as well.
 func (s *Service) RevokeAllUserRefreshTokens(ctx context.Context, userID uint) error {
+	tokens, err := s.repo.FindUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	marked := make(map[string]bool)
+	for _, token := range tokens {
+		sessionID, _ := token.session()
+		if token.IsRevoked || marked[sessionID] {
+			continue
+		}
+		if err := s.repo.MarkSessionRevoked(ctx, sessionID, s.accessExpiry); err != nil {
+			return err
+		}
+		marked[sessionID] = true
+	}
+
+	if s.sessionService != nil {
+		if err := s.sessionService.DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+
 	return s.repo.RevokeAllUserRefreshTokens(ctx, userID)
 }
+
+// browserSessions returns the active browser sessions of a user.
+func (s *Service) browserSessions(ctx context.Context, userID uint) ([]session.Session, error) {
+	if s.sessionService == nil {
+		return nil, nil
+	}
+	return s.sessionService.ListByUser(ctx, userID)
+}
+
+// revokeBrowserSession deletes the browser session of a user with the given public ID.
+// Returns a not found error if the user has no such session.
+func (s *Service) revokeBrowserSession(ctx context.Context, userID uint, publicID string) error {
+	browserSessions, err := s.browserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, browserSession := range browserSessions {
+		if subtle.ConstantTimeCompare([]byte(browserSession.PublicID()), []byte(publicID)) == 1 {
+			return s.sessionService.Delete(ctx, browserSession.ID)
+		}
+	}
+
+	return errors.NotFound(errors.ErrMsgWebSessionNotFound)
+}
+
+// DeleteExpiredTokens removes what is left of expired refresh tokens from storage.
+// Returns the number of entries removed.
+func (s *Service) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredTokens(ctx)
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+	"github.com/verigate/verigate-server/internal/pkg/utils/hash"
+)
+
+// errorMessage returns the message of a CustomError, or an empty string for any other error.
+func errorMessage(err error) string {
+	if customErr, ok := err.(errors.CustomError); ok {
+		return customErr.Message
+	}
+	return ""
+}
+
+func TestListSessionsIncludesBrowserSessions(t *testing.T) {
+	service, _, sessionService := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := service.CreateTokenPair(ctx, 1, "web-app", "192.0.2.1"); err != nil {
+		t.Fatalf("CreateTokenPair: %v", err)
+	}
+	browserSession, err := sessionService.Create(ctx, 1, "browser", "192.0.2.2")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	sessions, err := service.ListSessions(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("listed %d sessions, want 2", len(sessions))
+	}
+
+	kinds := make(map[string]Session)
+	for _, s := range sessions {
+		kinds[s.Kind] = s
+	}
+	listed, ok := kinds[SessionKindBrowser]
+	if !ok {
+		t.Fatalf("browser session not listed: %+v", sessions)
+	}
+	if _, ok := kinds[SessionKindToken]; !ok {
+		t.Fatalf("token session not listed: %+v", sessions)
+	}
+
+	// The cookie value is never listed
+	if listed.ID == browserSession.ID || listed.ID != browserSession.PublicID() {
+		t.Errorf("browser session listed as %q, want its public ID", listed.ID)
+	}
+	if listed.UserAgent != "browser" || listed.IPAddress != "192.0.2.2" {
+		t.Errorf("unexpected browser session: %+v", listed)
+	}
+}
+
+func TestRevokeBrowserSession(t *testing.T) {
+	service, _, sessionService := newTestService(t)
+	ctx := context.Background()
+
+	browserSession, err := sessionService.Create(ctx, 1, "browser", "192.0.2.2")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Neither another user nor the cookie value can end the session
+	if err := service.RevokeSession(ctx, 2, browserSession.PublicID()); errorMessage(err) != errors.ErrMsgWebSessionNotFound {
+		t.Errorf("revoke by another user: got %v, want not found", err)
+	}
+	if err := service.RevokeSession(ctx, 1, browserSession.ID); errorMessage(err) != errors.ErrMsgWebSessionNotFound {
+		t.Errorf("revoke by session ID: got %v, want not found", err)
+	}
+	if found, _ := sessionService.Get(ctx, browserSession.ID); found == nil {
+		t.Fatal("session ended by a failed revocation")
+	}
+
+	if err := service.RevokeSession(ctx, 1, browserSession.PublicID()); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if found, _ := sessionService.Get(ctx, browserSession.ID); found != nil {
+		t.Error("revoked browser session still valid")
+	}
+}
+
+func TestRevokeAllUserRefreshTokensEndsBrowserSessions(t *testing.T) {
+	service, _, sessionService := newTestService(t)
+	ctx := context.Background()
+
+	pair, err := service.CreateTokenPair(ctx, 1, "web-app", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateTokenPair: %v", err)
+	}
+	browserSession, err := sessionService.Create(ctx, 1, "browser", "192.0.2.2")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	otherSession, err := sessionService.Create(ctx, 2, "browser", "192.0.2.3")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := service.RevokeAllUserRefreshTokens(ctx, 1); err != nil {
+		t.Fatalf("RevokeAllUserRefreshTokens: %v", err)
+	}
+
+	if found, _ := sessionService.Get(ctx, browserSession.ID); found != nil {
+		t.Error("browser session still valid")
+	}
+	if found, _ := sessionService.Get(ctx, otherSession.ID); found == nil {
+		t.Error("another user's browser session was ended")
+	}
+	if _, _, err := service.ValidateAccessToken(ctx, pair.AccessToken); err == nil {
+		t.Error("access token of a revoked session still valid")
+	}
+	if sessions, _ := service.ListSessions(ctx, 1); len(sessions) != 0 {
+		t.Errorf("sessions left after revoking all: %+v", sessions)
+	}
+}
+
+func TestRefreshTokenReuseRevokesAllSessions(t *testing.T) {
+	service, _, sessionService := newTestService(t)
+	ctx := context.Background()
+
+	stolen, err := service.CreateTokenPair(ctx, 1, "laptop", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateTokenPair: %v", err)
+	}
+	other, err := service.CreateTokenPair(ctx, 1, "phone", "192.0.2.2")
+	if err != nil {
+		t.Fatalf("CreateTokenPair: %v", err)
+	}
+	browserSession, err := sessionService.Create(ctx, 1, "browser", "192.0.2.3")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	rotated, err := service.RefreshTokens(ctx, stolen.RefreshToken, "laptop", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+
+	// The rotated token is presented again
+	_, err = service.RefreshTokens(ctx, stolen.RefreshToken, "attacker", "198.51.100.1")
+	if errorMessage(err) != errors.ErrMsgTokenRevoked {
+		t.Fatalf("reuse: got %v, want token revoked", err)
+	}
+
+	for name, refreshToken := range map[string]string{"rotated": rotated.RefreshToken, "other": other.RefreshToken} {
+		_, err := service.RefreshTokens(ctx, refreshToken, "laptop", "192.0.2.1")
+		if customErr, ok := err.(errors.CustomError); !ok || customErr.Status != http.StatusUnauthorized {
+			t.Errorf("%s session still refreshable after reuse: %v", name, err)
+		}
+	}
+	for name, accessToken := range map[string]string{"rotated": rotated.AccessToken, "other": other.AccessToken} {
+		if _, _, err := service.ValidateAccessToken(ctx, accessToken); err == nil {
+			t.Errorf("%s access token still valid after reuse", name)
+		}
+	}
+	if found, _ := sessionService.Get(ctx, browserSession.ID); found != nil {
+		t.Error("browser session still valid after reuse")
+	}
+}
+
+func TestRefreshTokenLookupByID(t *testing.T) {
+	service, repo, _ := newTestService(t)
+	ctx := context.Background()
+
+	pair, err := service.CreateTokenPair(ctx, 1, "web-app", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("CreateTokenPair: %v", err)
+	}
+
+	tokenID, secret, ok := strings.Cut(pair.RefreshToken, refreshTokenSeparator)
+	if !ok {
+		t.Fatalf("refresh token %q has no ID", pair.RefreshToken)
+	}
+	stored, _ := repo.FindRefreshToken(ctx, tokenID)
+	if stored == nil {
+		t.Fatal("refresh token not stored under its ID")
+	}
+	if stored.Token == secret || stored.Token != hash.Token(secret, "test-pepper") {
+		t.Errorf("stored %q, want the keyed digest of the secret", stored.Token)
+	}
+
+	// A wrong secret is rejected without revoking the token
+	if _, err := service.RefreshTokens(ctx, tokenID+refreshTokenSeparator+"wrong", "web-app", "192.0.2.1"); errorMessage(err) != errors.ErrMsgInvalidToken {
+		t.Errorf("wrong secret: got %v, want invalid token", err)
+	}
+	if _, err := service.RefreshTokens(ctx, pair.RefreshToken, "web-app", "192.0.2.1"); err != nil {
+		t.Errorf("RefreshTokens: %v", err)
+	}
+}
+
+func TestLegacyRefreshTokenLookupDisabled(t *testing.T) {
+	service, _, _ := newTestService(t)
+	service.legacyLookup = false
+
+	// Tokens without an ID are not searched for
+	_, err := service.RefreshTokens(context.Background(), "bGVnYWN5LXRva2Vu", "web-app", "192.0.2.1")
+	if errorMessage(err) != errors.ErrMsgInvalidToken {
+		t.Errorf("legacy token: got %v, want invalid token", err)
+	}
+}
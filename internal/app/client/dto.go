@@ -2,82 +2,101 @@
 // including registration, configuration, and permission management.
 package client
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // CreateClientRequest represents the data required to create a new OAuth client.
 // It contains all the client metadata required for OAuth 2.0 client registration.
 type CreateClientRequest struct {
-	ClientName              string   `json:"client_name" binding:"required"`
-	Description             string   `json:"description"`
-	ClientURI               string   `json:"client_uri"`
-	LogoURI                 string   `json:"logo_uri"`
-	RedirectURIs            []string `json:"redirect_uris" binding:"required,min=1"`
-	GrantTypes              []string `json:"grant_types" binding:"required,min=1"`
-	ResponseTypes           []string `json:"response_types"`
-	Scope                   string   `json:"scope" binding:"required"`
-	TOSUri                  string   `json:"tos_uri"`
-	PolicyURI               string   `json:"policy_uri"`
-	JwksURI                 string   `json:"jwks_uri"`
-	Jwks                    string   `json:"jwks"`
-	Contacts                []string `json:"contacts"`
-	SoftwareID              string   `json:"software_id"`
-	SoftwareVersion         string   `json:"software_version"`
-	IsConfidential          bool     `json:"is_confidential"`
-	PKCERequired            bool     `json:"pkce_required"`
-	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
-	AccessTokenLifetime     int      `json:"access_token_lifetime"`  // in seconds
-	RefreshTokenLifetime    int      `json:"refresh_token_lifetime"` // in seconds
+	ClientName                         string              `json:"client_name" binding:"required"`
+	Description                        string              `json:"description"`
+	ClientURI                          string              `json:"client_uri"`
+	LogoURI                            string              `json:"logo_uri"`
+	RedirectURIs                       []string            `json:"redirect_uris" binding:"required,min=1"`
+	GrantTypes                         []string            `json:"grant_types" binding:"required,min=1"`
+	ResponseTypes                      []string            `json:"response_types"`
+	Scope                              string              `json:"scope" binding:"required"`
+	RequiredScopes                     []string            `json:"required_scopes"`
+	TOSUri                             string              `json:"tos_uri"`
+	PolicyURI                          string              `json:"policy_uri"`
+	JwksURI                            string              `json:"jwks_uri"`
+	Jwks                               string              `json:"jwks"`
+	Contacts                           []string            `json:"contacts"`
+	SoftwareID                         string              `json:"software_id"`
+	SoftwareVersion                    string              `json:"software_version"`
+	IsConfidential                     bool                `json:"is_confidential"`
+	PKCERequired                       bool                `json:"pkce_required"`
+	RequirePushedAuthorizationRequests bool                `json:"require_pushed_authorization_requests"`
+	TokenEndpointAuthMethod            string              `json:"token_endpoint_auth_method"`
+	AccessTokenLifetime                int                 `json:"access_token_lifetime"`  // in seconds
+	RefreshTokenLifetime               int                 `json:"refresh_token_lifetime"` // in seconds
+	ConsentLifetime                    *int                `json:"consent_lifetime"`       // in seconds; 0 never expires, null for the server default
+	IDTokenSignedResponseAlg           string              `json:"id_token_signed_response_alg"`
+	TokenExchangePolicy                TokenExchangePolicy `json:"token_exchange_policy"`
 }
 
 // UpdateClientRequest represents the data used to update an existing OAuth client.
 // All fields are optional - only non-empty fields will be updated.
 type UpdateClientRequest struct {
-	ClientName              string   `json:"client_name"`
-	Description             string   `json:"description"`
-	ClientURI               string   `json:"client_uri"`
-	LogoURI                 string   `json:"logo_uri"`
-	RedirectURIs            []string `json:"redirect_uris"`
-	GrantTypes              []string `json:"grant_types"`
-	ResponseTypes           []string `json:"response_types"`
-	Scope                   string   `json:"scope"`
-	TOSUri                  string   `json:"tos_uri"`
-	PolicyURI               string   `json:"policy_uri"`
-	JwksURI                 string   `json:"jwks_uri"`
-	Jwks                    string   `json:"jwks"`
-	Contacts                []string `json:"contacts"`
-	SoftwareID              string   `json:"software_id"`
-	SoftwareVersion         string   `json:"software_version"`
-	PKCERequired            bool     `json:"pkce_required"`
-	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
-	AccessTokenLifetime     int      `json:"access_token_lifetime"`  // in seconds
-	RefreshTokenLifetime    int      `json:"refresh_token_lifetime"` // in seconds
+	ClientName                         string               `json:"client_name"`
+	Description                        string               `json:"description"`
+	ClientURI                          string               `json:"client_uri"`
+	LogoURI                            string               `json:"logo_uri"`
+	RedirectURIs                       []string             `json:"redirect_uris"`
+	GrantTypes                         []string             `json:"grant_types"`
+	ResponseTypes                      []string             `json:"response_types"`
+	Scope                              string               `json:"scope"`
+	RequiredScopes                     *[]string            `json:"required_scopes"`
+	TOSUri                             string               `json:"tos_uri"`
+	PolicyURI                          string               `json:"policy_uri"`
+	JwksURI                            string               `json:"jwks_uri"`
+	Jwks                               string               `json:"jwks"`
+	Contacts                           []string             `json:"contacts"`
+	SoftwareID                         string               `json:"software_id"`
+	SoftwareVersion                    string               `json:"software_version"`
+	PKCERequired                       *bool                `json:"pkce_required"`
+	RequirePushedAuthorizationRequests *bool                `json:"require_pushed_authorization_requests"`
+	TokenEndpointAuthMethod            string               `json:"token_endpoint_auth_method"`
+	AccessTokenLifetime                int                  `json:"access_token_lifetime"`  // in seconds
+	RefreshTokenLifetime               int                  `json:"refresh_token_lifetime"` // in seconds
+	ConsentLifetime                    NullableInt          `json:"consent_lifetime"`       // in seconds; 0 never expires, null restores the server default
+	IDTokenSignedResponseAlg           string               `json:"id_token_signed_response_alg"`
+	TokenExchangePolicy                *TokenExchangePolicy `json:"token_exchange_policy"`
 }
 
 // ClientResponse represents an OAuth client response returned to API consumers.
 // It contains all client metadata but only includes the client secret when
 // initially created (it cannot be retrieved later).
 type ClientResponse struct {
-	ID                      uint      `json:"id"`
-	ClientID                string    `json:"client_id"`
-	ClientSecret            string    `json:"client_secret,omitempty"`
-	ClientName              string    `json:"client_name"`
-	Description             string    `json:"description,omitempty"`
-	ClientURI               string    `json:"client_uri,omitempty"`
-	LogoURI                 string    `json:"logo_uri,omitempty"`
-	RedirectURIs            []string  `json:"redirect_uris"`
-	GrantTypes              []string  `json:"grant_types"`
-	ResponseTypes           []string  `json:"response_types,omitempty"`
-	Scope                   string    `json:"scope"`
-	TOSUri                  string    `json:"tos_uri,omitempty"`
-	PolicyURI               string    `json:"policy_uri,omitempty"`
-	IsConfidential          bool      `json:"is_confidential"`
-	PKCERequired            bool      `json:"pkce_required"`
-	TokenEndpointAuthMethod string    `json:"token_endpoint_auth_method"`
-	AccessTokenLifetime     int       `json:"access_token_lifetime"`  // in seconds
-	RefreshTokenLifetime    int       `json:"refresh_token_lifetime"` // in seconds
-	IsActive                bool      `json:"is_active"`
-	CreatedAt               time.Time `json:"created_at"`
-	UpdatedAt               time.Time `json:"updated_at"`
+	ID                                 uint                `json:"id"`
+	ClientID                           string              `json:"client_id"`
+	ClientSecret                       string              `json:"client_secret,omitempty"`
+	ClientName                         string              `json:"client_name"`
+	Description                        string              `json:"description,omitempty"`
+	ClientURI                          string              `json:"client_uri,omitempty"`
+	LogoURI                            string              `json:"logo_uri,omitempty"`
+	RedirectURIs                       []string            `json:"redirect_uris"`
+	GrantTypes                         []string            `json:"grant_types"`
+	ResponseTypes                      []string            `json:"response_types,omitempty"`
+	Scope                              string              `json:"scope"`
+	RequiredScopes                     []string            `json:"required_scopes,omitempty"`
+	TOSUri                             string              `json:"tos_uri,omitempty"`
+	PolicyURI                          string              `json:"policy_uri,omitempty"`
+	IsConfidential                     bool                `json:"is_confidential"`
+	PKCERequired                       bool                `json:"pkce_required"`
+	RequirePushedAuthorizationRequests bool                `json:"require_pushed_authorization_requests"`
+	TokenEndpointAuthMethod            string              `json:"token_endpoint_auth_method"`
+	AccessTokenLifetime                int                 `json:"access_token_lifetime"`  // in seconds
+	RefreshTokenLifetime               int                 `json:"refresh_token_lifetime"` // in seconds
+	ConsentLifetime                    *int                `json:"consent_lifetime"`       // in seconds; 0 never expires, null for the server default
+	IDTokenSignedResponseAlg           string              `json:"id_token_signed_response_alg,omitempty"`
+	TokenExchangePolicy                TokenExchangePolicy `json:"token_exchange_policy"`
+	IsTrusted                          bool                `json:"is_trusted"`
+	IsActive                           bool                `json:"is_active"`
+	CreatedAt                          time.Time           `json:"created_at"`
+	UpdatedAt                          time.Time           `json:"updated_at"`
 }
 
 // ClientListResponse represents a paginated list of OAuth clients.
@@ -88,3 +107,23 @@ type ClientListResponse struct {
 	Page    int              `json:"page"`     // The current page number (1-indexed)
 	PerPage int              `json:"per_page"` // The number of items per page
 }
+
+// SetTrustedRequest marks a client as a trusted first-party application, or removes the mark.
+// Only administrators may change it, since trusted clients never show the consent page.
+type SetTrustedRequest struct {
+	IsTrusted *bool `json:"is_trusted" binding:"required"`
+}
+
+// NullableInt is an integer field of an update request that tells an omitted field,
+// which leaves the value unchanged, apart from an explicit null, which clears it.
+type NullableInt struct {
+	Set   bool // Whether the field was present in the request
+	Value *int // The new value; nil for null
+}
+
+// UnmarshalJSON records that the field was present and decodes its value, which may be null.
+func (n *NullableInt) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	n.Value = nil
+	return json.Unmarshal(data, &n.Value)
+}
@@ -43,6 +43,14 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 	r.DELETE("/:id", h.Delete)
 }
 
+// RegisterAdminRoutes sets up the administrative client routes on the provided router group.
+// The group is expected to be protected by admin authentication.
+// Routes include:
+// - PUT /admin/clients/:client_id/trusted - Mark a client as a trusted first-party application
+func (h *Handler) RegisterAdminRoutes(r *gin.RouterGroup) {
+	r.PUT("/:client_id/trusted", h.SetTrusted)
+}
+
 // Create handles requests to register a new OAuth client.
 // It extracts client details from the JSON request body, validates them,
 // and creates a new client associated with the authenticated user.
@@ -161,3 +169,22 @@ func (h *Handler) List(c *gin.Context) {
 
 	c.JSON(http.StatusOK, clients)
 }
+
+// SetTrusted handles requests to mark a client as trusted or untrusted.
+// Users of trusted clients are not asked for consent.
+// Returns 200 OK with the updated client, or 404 Not Found if the client doesn't exist.
+func (h *Handler) SetTrusted(c *gin.Context) {
+	var req SetTrustedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat + ": " + err.Error()))
+		return
+	}
+
+	client, err := h.service.SetTrusted(c.Request.Context(), c.Param("client_id"), *req.IsTrusted)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
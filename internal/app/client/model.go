@@ -6,34 +6,126 @@ import (
 	"time"
 )
 
+// Grant types a client can be registered for
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// Response types a client can be registered for
+const (
+	ResponseTypeCode = "code"
+)
+
+// Token endpoint authentication methods (RFC 7591).
+// client_secret_jwt is not supported: its assertions are signed with the client secret,
+// which is only stored as a hash, so the server has no key to verify them with.
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic" // Secret in the HTTP Basic Authorization header
+	AuthMethodClientSecretPost  = "client_secret_post"  // Secret in the form body
+	AuthMethodPrivateKeyJWT     = "private_key_jwt"     // JWT assertion signed with a key from the client's JWKS (RFC 7523)
+	AuthMethodNone              = "none"                // Public client, no secret
+)
+
+// Values accepted at client registration
+var (
+	supportedGrantTypes    = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials, GrantTypeDeviceCode, GrantTypeTokenExchange}
+	supportedResponseTypes = []string{ResponseTypeCode}
+	supportedAuthMethods   = []string{AuthMethodClientSecretBasic, AuthMethodClientSecretPost, AuthMethodPrivateKeyJWT, AuthMethodNone}
+)
+
 // Client represents an OAuth client application registered with the system.
 // It stores all metadata required for OAuth 2.0 operations and client authentication.
 type Client struct {
-	ID                      uint      `json:"id"`                         // Internal unique identifier
-	ClientID                string    `json:"client_id"`                  // Public unique identifier for the client
-	ClientSecret            string    `json:"client_secret,omitempty"`    // Hashed client secret for confidential clients
-	ClientName              string    `json:"client_name"`                // Human-readable name of the client
-	Description             string    `json:"description,omitempty"`      // Optional description of the client
-	ClientURI               string    `json:"client_uri,omitempty"`       // URI of the client's homepage
-	LogoURI                 string    `json:"logo_uri,omitempty"`         // URI of the client's logo
-	RedirectURIs            []string  `json:"redirect_uris"`              // Authorized redirect URIs for authorization code flow
-	GrantTypes              []string  `json:"grant_types"`                // Allowed OAuth grant types for this client
-	ResponseTypes           []string  `json:"response_types,omitempty"`   // Allowed OAuth response types
-	Scope                   string    `json:"scope"`                      // Default scope string for the client
-	TOSUri                  string    `json:"tos_uri,omitempty"`          // URI to the client's terms of service
-	PolicyURI               string    `json:"policy_uri,omitempty"`       // URI to the client's privacy policy
-	JwksURI                 string    `json:"jwks_uri,omitempty"`         // URI to the client's JSON Web Key Set
-	Jwks                    string    `json:"jwks,omitempty"`             // JSON Web Key Set as a string
-	Contacts                []string  `json:"contacts,omitempty"`         // Contact information for the client
-	SoftwareID              string    `json:"software_id,omitempty"`      // Software identifier
-	SoftwareVersion         string    `json:"software_version,omitempty"` // Software version
-	IsConfidential          bool      `json:"is_confidential"`            // Whether the client is confidential (can keep a secret)
-	PKCERequired            bool      `json:"pkce_required"`              // Whether PKCE is required for this client
-	TokenEndpointAuthMethod string    `json:"token_endpoint_auth_method"` // Method for token endpoint authentication
-	AccessTokenLifetime     int       `json:"access_token_lifetime"`      // Access token lifetime in seconds
-	RefreshTokenLifetime    int       `json:"refresh_token_lifetime"`     // Refresh token lifetime in seconds
-	IsActive                bool      `json:"is_active"`                  // Whether the client is active and allowed to be used
-	CreatedAt               time.Time `json:"created_at"`                 // When the client was created
-	UpdatedAt               time.Time `json:"updated_at"`                 // When the client was last updated
-	OwnerID                 uint      `json:"owner_id"`                   // User ID of the client owner
+	ID                                 uint                `json:"id"`                                     // Internal unique identifier
+	ClientID                           string              `json:"client_id"`                              // Public unique identifier for the client
+	ClientSecret                       string              `json:"client_secret,omitempty"`                // Hashed client secret for confidential clients
+	ClientName                         string              `json:"client_name"`                            // Human-readable name of the client
+	Description                        string              `json:"description,omitempty"`                  // Optional description of the client
+	ClientURI                          string              `json:"client_uri,omitempty"`                   // URI of the client's homepage
+	LogoURI                            string              `json:"logo_uri,omitempty"`                     // URI of the client's logo
+	RedirectURIs                       []string            `json:"redirect_uris"`                          // Authorized redirect URIs for authorization code flow
+	GrantTypes                         []string            `json:"grant_types"`                            // Allowed OAuth grant types for this client
+	ResponseTypes                      []string            `json:"response_types,omitempty"`               // Allowed OAuth response types
+	Scope                              string              `json:"scope"`                                  // Default scope string for the client
+	RequiredScopes                     []string            `json:"required_scopes,omitempty"`              // Scopes the user cannot deselect on the consent page
+	TOSUri                             string              `json:"tos_uri,omitempty"`                      // URI to the client's terms of service
+	PolicyURI                          string              `json:"policy_uri,omitempty"`                   // URI to the client's privacy policy
+	JwksURI                            string              `json:"jwks_uri,omitempty"`                     // URI to the client's JSON Web Key Set
+	Jwks                               string              `json:"jwks,omitempty"`                         // JSON Web Key Set as a string
+	Contacts                           []string            `json:"contacts,omitempty"`                     // Contact information for the client
+	SoftwareID                         string              `json:"software_id,omitempty"`                  // Software identifier
+	SoftwareVersion                    string              `json:"software_version,omitempty"`             // Software version
+	IsConfidential                     bool                `json:"is_confidential"`                        // Whether the client is confidential (can keep a secret)
+	PKCERequired                       bool                `json:"pkce_required"`                          // Whether PKCE is required for this client
+	RequirePushedAuthorizationRequests bool                `json:"require_pushed_authorization_requests"`  // Whether authorization requests must be pushed first (RFC 9126)
+	TokenEndpointAuthMethod            string              `json:"token_endpoint_auth_method"`             // Method for token endpoint authentication
+	AccessTokenLifetime                int                 `json:"access_token_lifetime"`                  // Access token lifetime in seconds
+	RefreshTokenLifetime               int                 `json:"refresh_token_lifetime"`                 // Refresh token lifetime in seconds
+	ConsentLifetime                    *int                `json:"consent_lifetime"`                       // How long a user's consent stays valid in seconds; 0 never expires, nil for the server default
+	IsTrusted                          bool                `json:"is_trusted"`                             // Whether the client is a trusted first-party application that skips the consent page
+	IDTokenSignedResponseAlg           string              `json:"id_token_signed_response_alg,omitempty"` // Algorithm for signing ID tokens; empty for the server default
+	TokenExchangePolicy                TokenExchangePolicy `json:"token_exchange_policy"`                  // What the client may do with the token exchange grant
+	IsActive                           bool                `json:"is_active"`                              // Whether the client is active and allowed to be used
+	CreatedAt                          time.Time           `json:"created_at"`                             // When the client was created
+	UpdatedAt                          time.Time           `json:"updated_at"`                             // When the client was last updated
+	OwnerID                            uint                `json:"owner_id"`                               // User ID of the client owner
+}
+
+// RequiresScope reports whether the user must grant the named scope to use the client.
+func (c *Client) RequiresScope(name string) bool {
+	return contains(c.RequiredScopes, name)
+}
+
+// TokenExchangePolicy controls how a client may use the token exchange grant (RFC 8693).
+// A client may only exchange tokens if its policy lists the clients whose tokens it accepts
+// or the audiences it may request tokens for; an empty policy refuses every exchange.
+// By default an exchanged token records the client as the acting party (delegation);
+// with impersonation allowed the token is indistinguishable from one issued to the user.
+type TokenExchangePolicy struct {
+	AllowedSubjectClients []string `json:"allowed_subject_clients,omitempty"` // Client IDs whose tokens the client may exchange, besides its own
+	AllowedAudiences      []string `json:"allowed_audiences,omitempty"`       // Client IDs the client may request tokens for
+	AllowImpersonation    bool     `json:"allow_impersonation"`               // Whether exchanged tokens omit the act claim
+}
+
+// AllowsExchange reports whether the policy permits the client to exchange tokens at all.
+func (p TokenExchangePolicy) AllowsExchange() bool {
+	return len(p.AllowedSubjectClients) > 0 || len(p.AllowedAudiences) > 0
+}
+
+// AllowsSubjectClient reports whether the policy permits exchanging tokens issued to the given client.
+func (p TokenExchangePolicy) AllowsSubjectClient(clientID string) bool {
+	return contains(p.AllowedSubjectClients, clientID)
+}
+
+// AllowsAudience reports whether the policy permits exchanging tokens for the given audience.
+func (p TokenExchangePolicy) AllowsAudience(audience string) bool {
+	return contains(p.AllowedAudiences, audience)
+}
+
+// AllowsGrantType reports whether the client is registered for the given grant type.
+func (c *Client) AllowsGrantType(grantType string) bool {
+	return contains(c.GrantTypes, grantType)
+}
+
+// AllowsResponseType reports whether the client is registered for the given response type.
+// Clients registered without response types default to "code", as in RFC 7591.
+func (c *Client) AllowsResponseType(responseType string) bool {
+	if len(c.ResponseTypes) == 0 {
+		return responseType == ResponseTypeCode
+	}
+	return contains(c.ResponseTypes, responseType)
+}
+
+// contains reports whether values includes value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
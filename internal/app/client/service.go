@@ -6,27 +6,79 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/verigate/verigate-server/internal/app/auth"
+	"github.com/verigate/verigate-server/internal/pkg/config"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 	"github.com/verigate/verigate-server/internal/pkg/utils/hash"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
 )
 
+// Limits for fetching client JWK sets from their jwks_uri
+const (
+	jwksFetchTimeout       = 5 * time.Second
+	jwksMaxResponseSize    = 64 << 10
+	jwksMinRefreshInterval = time.Minute // Earliest refetch when an assertion names an unknown key
+)
+
+// nonPublicPrefixes are address ranges that are not covered by the netip classification
+// methods but must not be reachable through a jwks_uri either.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which may translate to private IPv4 addresses
+}
+
+// errJWKSRedirect is returned when a jwks_uri responds with a redirect, which is not followed.
+var errJWKSRedirect = stderrors.New("jwks_uri redirects are not followed")
+
 // Service provides business logic for managing OAuth clients.
 // It handles client creation, retrieval, updating, deletion, and authentication.
 type Service struct {
 	repo        Repository
 	authService *auth.Service
+	httpClient  *http.Client
+	jwksTTL     time.Duration
+
+	jwksMu    sync.Mutex
+	jwksCache map[string]cachedJWKS // Fetched JWK sets by jwks_uri
+}
+
+// cachedJWKS is a JWK set fetched from a client's jwks_uri.
+type cachedJWKS struct {
+	keys      jwtutil.JWKS
+	fetchedAt time.Time
 }
 
 // NewService creates a new client service instance.
 // It requires a client repository for data access and an auth service for authentication operations.
 func NewService(repo Repository, authService *auth.Service) *Service {
+	jwksTTL, err := time.ParseDuration(config.AppConfig.ClientJWKSCacheTTL)
+	if err != nil {
+		panic("invalid client JWKS cache TTL: " + err.Error())
+	}
+
 	return &Service{
 		repo:        repo,
 		authService: authService,
+		httpClient:  newJWKSHTTPClient(),
+		jwksTTL:     jwksTTL,
+		jwksCache:   make(map[string]cachedJWKS),
 	}
 }
 
@@ -35,45 +87,78 @@ func NewService(repo Repository, authService *auth.Service) *Service {
 // then saves the client to the repository and returns the created client details.
 // The client secret is only returned once at creation time.
 func (s *Service) Create(ctx context.Context, ownerID uint, req CreateClientRequest) (*ClientResponse, error) {
+	if err := validateIDTokenSigningAlg(req.IDTokenSignedResponseAlg); err != nil {
+		return nil, err
+	}
+
 	// Generate client ID and secret
 	clientID, err := s.generateClientID()
 	if err != nil {
 		return nil, errors.Internal("Failed to generate client ID: " + err.Error())
 	}
 
+	// Public clients cannot authenticate, confidential clients default to HTTP Basic
+	authMethod := req.TokenEndpointAuthMethod
+	if authMethod == "" {
+		authMethod = AuthMethodNone
+		if req.IsConfidential {
+			authMethod = AuthMethodClientSecretBasic
+		}
+	}
+
+	// Clients authenticating with their own keys have no shared secret
 	var clientSecret string
 	var hashedSecret string
-	if req.IsConfidential {
+	if req.IsConfidential && authMethod != AuthMethodPrivateKeyJWT {
 		clientSecret, hashedSecret, err = s.generateClientSecret()
 		if err != nil {
 			return nil, errors.Internal("Failed to generate client secret: " + err.Error())
 		}
 	}
 
+	// Clients that use the authorization code flow default to the "code" response type
+	responseTypes := req.ResponseTypes
+	if len(responseTypes) == 0 && contains(req.GrantTypes, GrantTypeAuthorizationCode) {
+		responseTypes = []string{ResponseTypeCode}
+	}
+
 	// Create client model
 	client := &Client{
-		ClientID:        clientID,
-		ClientSecret:    hashedSecret,
-		ClientName:      req.ClientName,
-		Description:     req.Description,
-		ClientURI:       req.ClientURI,
-		LogoURI:         req.LogoURI,
-		RedirectURIs:    req.RedirectURIs,
-		GrantTypes:      req.GrantTypes,
-		ResponseTypes:   req.ResponseTypes,
-		Scope:           req.Scope,
-		TOSUri:          req.TOSUri,
-		PolicyURI:       req.PolicyURI,
-		JwksURI:         req.JwksURI,
-		Jwks:            req.Jwks,
-		Contacts:        req.Contacts,
-		SoftwareID:      req.SoftwareID,
-		SoftwareVersion: req.SoftwareVersion,
-		IsConfidential:  req.IsConfidential,
-		IsActive:        true,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-		OwnerID:         ownerID,
+		ClientID:                           clientID,
+		ClientSecret:                       hashedSecret,
+		ClientName:                         req.ClientName,
+		Description:                        req.Description,
+		ClientURI:                          req.ClientURI,
+		LogoURI:                            req.LogoURI,
+		RedirectURIs:                       req.RedirectURIs,
+		GrantTypes:                         req.GrantTypes,
+		ResponseTypes:                      responseTypes,
+		Scope:                              req.Scope,
+		RequiredScopes:                     req.RequiredScopes,
+		TOSUri:                             req.TOSUri,
+		PolicyURI:                          req.PolicyURI,
+		JwksURI:                            req.JwksURI,
+		Jwks:                               req.Jwks,
+		Contacts:                           req.Contacts,
+		SoftwareID:                         req.SoftwareID,
+		SoftwareVersion:                    req.SoftwareVersion,
+		IsConfidential:                     req.IsConfidential,
+		PKCERequired:                       req.PKCERequired,
+		RequirePushedAuthorizationRequests: req.RequirePushedAuthorizationRequests,
+		TokenEndpointAuthMethod:            authMethod,
+		AccessTokenLifetime:                req.AccessTokenLifetime,
+		RefreshTokenLifetime:               req.RefreshTokenLifetime,
+		ConsentLifetime:                    req.ConsentLifetime,
+		IsActive:                           true,
+		IDTokenSignedResponseAlg:           req.IDTokenSignedResponseAlg,
+		TokenExchangePolicy:                req.TokenExchangePolicy,
+		CreatedAt:                          time.Now(),
+		UpdatedAt:                          time.Now(),
+		OwnerID:                            ownerID,
+	}
+
+	if err := validateRegistration(client); err != nil {
+		return nil, err
 	}
 
 	// Save to repository
@@ -89,25 +174,9 @@ func (s *Service) Create(ctx context.Context, ownerID uint, req CreateClientRequ
 	}
 
 	// Return response with unhashed secret (only time it's available)
-	return &ClientResponse{
-		ID:             client.ID,
-		ClientID:       client.ClientID,
-		ClientSecret:   clientSecret, // Return unhashed secret
-		ClientName:     client.ClientName,
-		Description:    client.Description,
-		ClientURI:      client.ClientURI,
-		LogoURI:        client.LogoURI,
-		RedirectURIs:   client.RedirectURIs,
-		GrantTypes:     client.GrantTypes,
-		ResponseTypes:  client.ResponseTypes,
-		Scope:          client.Scope,
-		TOSUri:         client.TOSUri,
-		PolicyURI:      client.PolicyURI,
-		IsConfidential: client.IsConfidential,
-		IsActive:       client.IsActive,
-		CreatedAt:      client.CreatedAt,
-		UpdatedAt:      client.UpdatedAt,
-	}, nil
+	response := s.toResponse(client)
+	response.ClientSecret = clientSecret
+	return response, nil
 }
 
 // GetByID retrieves a client by its internal ID.
@@ -167,6 +236,10 @@ func (s *Service) Update(ctx context.Context, id uint, ownerID uint, req UpdateC
 		return errors.Forbidden(errors.ErrMsgNotAuthorizedForClient)
 	}
 
+	if err := validateIDTokenSigningAlg(req.IDTokenSignedResponseAlg); err != nil {
+		return err
+	}
+
 	// Update fields if provided
 	if req.ClientName != "" {
 		client.ClientName = req.ClientName
@@ -192,6 +265,27 @@ func (s *Service) Update(ctx context.Context, id uint, ownerID uint, req UpdateC
 	if req.Scope != "" {
 		client.Scope = req.Scope
 	}
+	if req.RequiredScopes != nil {
+		client.RequiredScopes = *req.RequiredScopes
+	}
+	if req.PKCERequired != nil {
+		client.PKCERequired = *req.PKCERequired
+	}
+	if req.RequirePushedAuthorizationRequests != nil {
+		client.RequirePushedAuthorizationRequests = *req.RequirePushedAuthorizationRequests
+	}
+	if req.TokenEndpointAuthMethod != "" {
+		client.TokenEndpointAuthMethod = req.TokenEndpointAuthMethod
+	}
+	if req.AccessTokenLifetime != 0 {
+		client.AccessTokenLifetime = req.AccessTokenLifetime
+	}
+	if req.RefreshTokenLifetime != 0 {
+		client.RefreshTokenLifetime = req.RefreshTokenLifetime
+	}
+	if req.ConsentLifetime.Set {
+		client.ConsentLifetime = req.ConsentLifetime.Value
+	}
 	client.TOSUri = req.TOSUri
 	client.PolicyURI = req.PolicyURI
 	client.JwksURI = req.JwksURI
@@ -199,8 +293,16 @@ func (s *Service) Update(ctx context.Context, id uint, ownerID uint, req UpdateC
 	client.Contacts = req.Contacts
 	client.SoftwareID = req.SoftwareID
 	client.SoftwareVersion = req.SoftwareVersion
+	client.IDTokenSignedResponseAlg = req.IDTokenSignedResponseAlg
+	if req.TokenExchangePolicy != nil {
+		client.TokenExchangePolicy = *req.TokenExchangePolicy
+	}
 	client.UpdatedAt = time.Now()
 
+	if err := validateRegistration(client); err != nil {
+		return err
+	}
+
 	if err := s.repo.Update(ctx, client); err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
 			return errors.NotFound(errors.ErrMsgClientNotFound)
@@ -263,6 +365,28 @@ func (s *Service) List(ctx context.Context, ownerID uint, page, limit int) (*Cli
 	}, nil
 }
 
+// SetTrusted marks a client as trusted or untrusted. Trusted clients are first-party
+// applications whose users are never asked for consent. Only administrators may call this,
+// so ownership is not checked.
+func (s *Service) SetTrusted(ctx context.Context, clientID string, trusted bool) (*ClientResponse, error) {
+	client, err := s.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	client.IsTrusted = trusted
+	client.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, client); err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "no rows") {
+			return nil, errors.NotFound(errors.ErrMsgClientNotFound)
+		}
+		return nil, errors.Internal(errors.ErrMsgFailedToUpdateClient)
+	}
+
+	return s.toResponse(client), nil
+}
+
 // ValidateClient verifies client credentials for authentication purposes.
 // For confidential clients, it checks that the provided secret matches the stored hash.
 // For public clients, it just verifies the client exists and is active.
@@ -285,7 +409,7 @@ func (s *Service) ValidateClient(ctx context.Context, clientID, clientSecret str
 
 	// For confidential clients, verify secret
 	if client.IsConfidential {
-		if err := hash.CompareHashAndPassword(client.ClientSecret, clientSecret); err != nil {
+		if err := hash.CompareHashAndPasswordContext(ctx, client.ClientSecret, clientSecret); err != nil {
 			return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
 		}
 	}
@@ -293,8 +417,240 @@ func (s *Service) ValidateClient(ctx context.Context, clientID, clientSecret str
 	return client, nil
 }
 
+// VerifyAssertion checks the signature of a JWT assertion presented by a client
+// (private_key_jwt, RFC 7523) against the client's registered JWK set and returns its claims.
+// Only the signature and the standard time-based claims are verified here; the caller checks
+// the issuer, subject, audience and token ID.
+// Keys from a jwks_uri are cached; an assertion signed with an unknown key triggers a refetch,
+// so clients can rotate keys without waiting for the cache to expire.
+func (s *Service) VerifyAssertion(ctx context.Context, client *Client, assertion string) (jwt.MapClaims, error) {
+	keys, err := s.clientKeys(ctx, client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(assertion, jwtutil.KeyFuncForJWKS(keys))
+	if err != nil && client.JwksURI != "" && stderrors.Is(err, jwtutil.ErrKeyNotFound) {
+		if keys, err = s.clientKeys(ctx, client, true); err != nil {
+			return nil, err
+		}
+		token, err = jwt.Parse(assertion, jwtutil.KeyFuncForJWKS(keys))
+	}
+	if err != nil {
+		return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	return claims, nil
+}
+
 // Helper methods
 
+// clientKeys returns the JWK set of a client, either registered inline or fetched from its jwks_uri.
+// With refresh set, a cached set older than jwksMinRefreshInterval is fetched again.
+func (s *Service) clientKeys(ctx context.Context, client *Client, refresh bool) (jwtutil.JWKS, error) {
+	if client.Jwks != "" {
+		keys, err := jwtutil.ParseJWKS([]byte(client.Jwks))
+		if err != nil {
+			return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+		}
+		return keys, nil
+	}
+	if client.JwksURI == "" {
+		return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	s.jwksMu.Lock()
+	cached, ok := s.jwksCache[client.JwksURI]
+	s.jwksMu.Unlock()
+
+	age := time.Since(cached.fetchedAt)
+	if ok && age < s.jwksTTL && (!refresh || age < jwksMinRefreshInterval) {
+		return cached.keys, nil
+	}
+
+	keys, err := s.fetchJWKS(ctx, client.JwksURI)
+	if err != nil {
+		return jwtutil.JWKS{}, err
+	}
+
+	s.jwksMu.Lock()
+	s.jwksCache[client.JwksURI] = cachedJWKS{keys: keys, fetchedAt: time.Now()}
+	s.jwksMu.Unlock()
+
+	return keys, nil
+}
+
+// newJWKSHTTPClient creates the HTTP client that fetches client JWK sets.
+// A jwks_uri is chosen by whoever registers the client, so the client only connects to
+// public addresses and does not follow redirects; otherwise a registration could make the
+// server send requests to internal services. The check is made on the resolved address
+// of every connection, so a host name cannot be re-resolved to an internal address in
+// between. Proxies are not used, as they would connect on the server's behalf.
+func newJWKSHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: jwksFetchTimeout,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("jwks_uri resolves to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   jwksFetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return errJWKSRedirect
+		},
+	}
+}
+
+// isPublicAddress reports whether the address is a globally routable unicast address.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchJWKS downloads and parses a JWK set.
+func (s *Service) fetchJWKS(ctx context.Context, uri string) (jwtutil.JWKS, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return jwtutil.JWKS{}, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFetchClientJWKS, err.Error()))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxResponseSize))
+	if err != nil {
+		return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	keys, err := jwtutil.ParseJWKS(data)
+	if err != nil {
+		return jwtutil.JWKS{}, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+
+	return keys, nil
+}
+
+// validateIDTokenSigningAlg checks that ID tokens can be signed with the requested algorithm.
+// An empty value is valid and selects the server's default algorithm.
+func validateIDTokenSigningAlg(algorithm string) error {
+	if algorithm == "" {
+		return nil
+	}
+	for _, supported := range jwtutil.SigningAlgorithms() {
+		if supported == algorithm {
+			return nil
+		}
+	}
+	return errors.BadRequest(errors.ErrMsgUnsupportedIDTokenSigningAlg)
+}
+
+// validateRegistration checks the grant types, response types, authentication method
+// and token lifetimes of a client against the values supported by the server.
+// Public clients must use the "none" authentication method, confidential clients a secret-based one.
+func validateRegistration(client *Client) error {
+	for _, grantType := range client.GrantTypes {
+		if !contains(supportedGrantTypes, grantType) {
+			return errors.BadRequest(fmt.Sprintf(errors.ErrMsgUnsupportedClientGrantType, grantType))
+		}
+	}
+
+	for _, responseType := range client.ResponseTypes {
+		if !contains(supportedResponseTypes, responseType) {
+			return errors.BadRequest(fmt.Sprintf(errors.ErrMsgUnsupportedClientResponseType, responseType))
+		}
+	}
+	if contains(client.ResponseTypes, ResponseTypeCode) && !contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return errors.BadRequest(fmt.Sprintf(errors.ErrMsgResponseTypeRequiresGrantType, ResponseTypeCode, GrantTypeAuthorizationCode))
+	}
+
+	authMethod := client.TokenEndpointAuthMethod
+	if !contains(supportedAuthMethods, authMethod) {
+		return errors.BadRequest(fmt.Sprintf(errors.ErrMsgUnsupportedTokenEndpointAuthMethod, authMethod))
+	}
+	if client.IsConfidential == (authMethod == AuthMethodNone) {
+		return errors.BadRequest(fmt.Sprintf(errors.ErrMsgAuthMethodNotAllowedForClientType, authMethod))
+	}
+
+	if authMethod == AuthMethodPrivateKeyJWT {
+		if err := validateClientKeys(client); err != nil {
+			return err
+		}
+	}
+
+	if client.AccessTokenLifetime < 0 || client.RefreshTokenLifetime < 0 {
+		return errors.BadRequest(errors.ErrMsgInvalidTokenLifetime)
+	}
+	if client.ConsentLifetime != nil && *client.ConsentLifetime < 0 {
+		return errors.BadRequest(errors.ErrMsgInvalidConsentLifetime)
+	}
+
+	// Required scopes must be among the scopes the client may request
+	allowedScopes := strings.Fields(client.Scope)
+	for _, required := range client.RequiredScopes {
+		if !contains(allowedScopes, required) {
+			return errors.BadRequest(fmt.Sprintf(errors.ErrMsgRequiredScopeNotAllowed, required))
+		}
+	}
+
+	return nil
+}
+
+// validateClientKeys checks that a client authenticating with private_key_jwt registered
+// its keys either inline or by an https jwks_uri, as RFC 7591 does not allow both.
+// Plain http is tolerated in development.
+func validateClientKeys(client *Client) error {
+	if (client.Jwks == "") == (client.JwksURI == "") {
+		return errors.BadRequest(fmt.Sprintf(errors.ErrMsgClientKeysRequired, client.TokenEndpointAuthMethod))
+	}
+
+	if client.Jwks != "" {
+		if _, err := jwtutil.ParseJWKS([]byte(client.Jwks)); err != nil {
+			return errors.BadRequest(fmt.Sprintf(errors.ErrMsgInvalidClientJWKS, err.Error()))
+		}
+		return nil
+	}
+
+	uri, err := url.Parse(client.JwksURI)
+	if err != nil || uri.Host == "" || (uri.Scheme != "https" && !(uri.Scheme == "http" && config.AppConfig.Environment == "development")) {
+		return errors.BadRequest(errors.ErrMsgInvalidClientJWKSURI)
+	}
+	return nil
+}
+
 // generateClientID creates a cryptographically secure random client ID.
 // The ID is generated as a URL-safe base64 encoded string of 16 random bytes,
 // resulting in a 22-character string.
@@ -330,21 +686,31 @@ func (s *Service) generateClientSecret() (string, string, error) {
 
 func (s *Service) toResponse(client *Client) *ClientResponse {
 	return &ClientResponse{
-		ID:             client.ID,
-		ClientID:       client.ClientID,
-		ClientName:     client.ClientName,
-		Description:    client.Description,
-		ClientURI:      client.ClientURI,
-		LogoURI:        client.LogoURI,
-		RedirectURIs:   client.RedirectURIs,
-		GrantTypes:     client.GrantTypes,
-		ResponseTypes:  client.ResponseTypes,
-		Scope:          client.Scope,
-		TOSUri:         client.TOSUri,
-		PolicyURI:      client.PolicyURI,
-		IsConfidential: client.IsConfidential,
-		IsActive:       client.IsActive,
-		CreatedAt:      client.CreatedAt,
-		UpdatedAt:      client.UpdatedAt,
+		ID:                                 client.ID,
+		ClientID:                           client.ClientID,
+		ClientName:                         client.ClientName,
+		Description:                        client.Description,
+		ClientURI:                          client.ClientURI,
+		LogoURI:                            client.LogoURI,
+		RedirectURIs:                       client.RedirectURIs,
+		GrantTypes:                         client.GrantTypes,
+		ResponseTypes:                      client.ResponseTypes,
+		Scope:                              client.Scope,
+		RequiredScopes:                     client.RequiredScopes,
+		TOSUri:                             client.TOSUri,
+		PolicyURI:                          client.PolicyURI,
+		IsConfidential:                     client.IsConfidential,
+		PKCERequired:                       client.PKCERequired,
+		RequirePushedAuthorizationRequests: client.RequirePushedAuthorizationRequests,
+		TokenEndpointAuthMethod:            client.TokenEndpointAuthMethod,
+		AccessTokenLifetime:                client.AccessTokenLifetime,
+		RefreshTokenLifetime:               client.RefreshTokenLifetime,
+		ConsentLifetime:                    client.ConsentLifetime,
+		IDTokenSignedResponseAlg:           client.IDTokenSignedResponseAlg,
+		TokenExchangePolicy:                client.TokenExchangePolicy,
+		IsTrusted:                          client.IsTrusted,
+		IsActive:                           client.IsActive,
+		CreatedAt:                          client.CreatedAt,
+		UpdatedAt:                          client.UpdatedAt,
 	}
 }
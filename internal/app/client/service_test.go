@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublicAddress(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("isPublicAddress(%s) = %v, want %v", tt.addr, got, tt.public)
+		}
+	}
+}
+
+func TestFetchJWKSRefusesLoopback(t *testing.T) {
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+	service := NewService(nil, nil)
+
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer server.Close()
+
+	if _, err := service.fetchJWKS(context.Background(), server.URL); err == nil {
+		t.Error("JWK set fetched from a loopback address")
+	}
+	if requested {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestJWKSHTTPClientRefusesRedirects(t *testing.T) {
+	httpClient := newJWKSHTTPClient()
+
+	req := httptest.NewRequest(http.MethodGet, "https://jwks.example.com/keys", nil)
+	if err := httpClient.CheckRedirect(req, []*http.Request{req}); err == nil {
+		t.Error("redirect of a jwks_uri was allowed")
+	}
+}
+
+func TestUpdateClientRequestConsentLifetime(t *testing.T) {
+	tests := []struct {
+		body  string
+		set   bool
+		value *int
+	}{
+		{`{}`, false, nil},
+		{`{"consent_lifetime":null}`, true, nil},
+		{`{"consent_lifetime":0}`, true, new(int)},
+	}
+
+	for _, tt := range tests {
+		var req UpdateClientRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatalf("%s: %v", tt.body, err)
+		}
+		got := req.ConsentLifetime
+		if got.Set != tt.set || (got.Value == nil) != (tt.value == nil) || (got.Value != nil && *got.Value != *tt.value) {
+			t.Errorf("%s: got %+v", tt.body, got)
+		}
+	}
+}
+
+func TestValidateRegistrationRejectsClientSecretJWT(t *testing.T) {
+	c := &Client{
+		IsConfidential:          true,
+		GrantTypes:              []string{GrantTypeClientCredentials},
+		TokenEndpointAuthMethod: AuthMethodClientSecretBasic,
+	}
+	if err := validateRegistration(c); err != nil {
+		t.Fatalf("client_secret_basic: %v", err)
+	}
+
+	// Secrets are stored as hashes, so HMAC assertions keyed with them cannot be verified
+	c.TokenEndpointAuthMethod = "client_secret_jwt"
+	err := validateRegistration(c)
+	want := fmt.Sprintf(errors.ErrMsgUnsupportedTokenEndpointAuthMethod, "client_secret_jwt")
+	if customErr, ok := err.(errors.CustomError); !ok || customErr.Message != want {
+		t.Errorf("client_secret_jwt: got %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,22 @@
+// Package key provides management of the JWT signing key set,
+// including persistence, scheduled rotation and retirement of keys.
+package key
+
+import "time"
+
+// KeyResponse represents a signing key in admin API responses.
+type KeyResponse struct {
+	KID           string     `json:"kid"`                      // Key identifier
+	Algorithm     string     `json:"algorithm"`                // JWS algorithm
+	Status        Status     `json:"status"`                   // Lifecycle state
+	CreatedAt     time.Time  `json:"created_at"`               // Creation timestamp
+	ActivatedAt   *time.Time `json:"activated_at,omitempty"`   // When the key started signing tokens
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"` // When the key stopped signing tokens
+	RetiredAt     *time.Time `json:"retired_at,omitempty"`     // When the key stopped being accepted for verification
+}
+
+// KeyListResponse wraps the list of signing keys for API responses.
+type KeyListResponse struct {
+	Keys       []KeyResponse     `json:"keys"`        // All known keys, newest first
+	ActiveKeys map[string]string `json:"active_keys"` // Identifier of the key currently signing tokens, by algorithm
+}
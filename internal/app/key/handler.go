@@ -0,0 +1,54 @@
+// Package key provides management of the JWT signing key set,
+// including persistence, scheduled rotation and retirement of keys.
+package key
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages administrative HTTP requests for signing keys.
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new signing key handler with the given service.
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the signing key management routes on the provided router group.
+// The group is expected to be protected by admin authentication.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("", h.List)           // List signing keys
+	r.POST("/rotate", h.Rotate) // Rotate the active signing key
+}
+
+// List handles the GET request to list all signing keys and their lifecycle state.
+// Private key material is never included in the response.
+//
+// Route: GET /admin/keys
+func (h *Handler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// Rotate handles the POST request to rotate the signing key immediately.
+// Tokens signed with the replaced key remain valid until they expire.
+//
+// Route: POST /admin/keys/rotate
+func (h *Handler) Rotate(c *gin.Context) {
+	keys, err := h.service.Rotate(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
@@ -0,0 +1,35 @@
+// Package key provides management of the JWT signing key set,
+// including persistence, scheduled rotation and retirement of keys.
+package key
+
+import (
+	"time"
+)
+
+// Status describes where a signing key is in its lifecycle.
+type Status string
+
+// Signing key lifecycle states. A key is created as next, becomes active on
+// rotation, stays verifiable as previous for the retention period, and is
+// finally retired, at which point its private key is discarded.
+const (
+	StatusNext     Status = "next"     // Published for verification, signs after the next rotation
+	StatusActive   Status = "active"   // Signs all newly issued tokens
+	StatusPrevious Status = "previous" // Replaced by a newer key, still accepted for verification
+	StatusRetired  Status = "retired"  // No longer accepted for verification
+)
+
+// SigningKey represents a JWT signing key stored in the database.
+// The private key is stored encrypted and is never returned through the API.
+type SigningKey struct {
+	ID                  uint       `json:"id"`                       // Primary key
+	KID                 string     `json:"kid"`                      // Key identifier stamped in the JWT "kid" header
+	Algorithm           string     `json:"algorithm"`                // JWS algorithm (e.g., "RS256")
+	Status              Status     `json:"status"`                   // Lifecycle state of the key
+	EncryptedPrivateKey []byte     `json:"-"`                        // PEM-encoded private key encrypted with the key encryption key
+	PublicKey           string     `json:"public_key"`               // PEM-encoded public key
+	CreatedAt           time.Time  `json:"created_at"`               // Creation timestamp
+	ActivatedAt         *time.Time `json:"activated_at,omitempty"`   // When the key started signing tokens
+	DeactivatedAt       *time.Time `json:"deactivated_at,omitempty"` // When the key stopped signing tokens
+	RetiredAt           *time.Time `json:"retired_at,omitempty"`     // When the key stopped being accepted for verification
+}
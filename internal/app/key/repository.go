@@ -0,0 +1,30 @@
+// Package key provides management of the JWT signing key set,
+// including persistence, scheduled rotation and retirement of keys.
+package key
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for signing key data access operations.
+type Repository interface {
+	// Save persists a new signing key to the data store
+	Save(ctx context.Context, key *SigningKey) error
+
+	// FindUsable retrieves all keys that have not been retired
+	FindUsable(ctx context.Context) ([]SigningKey, error)
+
+	// FindAll retrieves all keys, including retired ones
+	FindAll(ctx context.Context) ([]SigningKey, error)
+
+	// Rotate demotes the algorithm's active key to previous, promotes its next key to active
+	// and stores newNext as the next key in a single transaction.
+	// If no next key exists, newNext is activated directly and no next key is stored.
+	// Returns a conflict error if the active key is no longer expectedActiveKID.
+	Rotate(ctx context.Context, algorithm, expectedActiveKID string, newNext *SigningKey, now time.Time) error
+
+	// RetirePrevious retires previous keys deactivated before the given time
+	// and discards their private keys. Returns the number of keys retired.
+	RetirePrevious(ctx context.Context, deactivatedBefore time.Time) (int64, error)
+}
@@ -0,0 +1,392 @@
+// Package key provides management of the JWT signing key set,
+// including persistence, scheduled rotation and retirement of keys.
+package key
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/encrypt"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
+)
+
+// Service manages the lifecycle of JWT signing keys.
+// Keys are stored encrypted in the repository and loaded into the JWT utility
+// package, which signs with the active key and verifies against every usable key.
+// When no key encryption key is configured the service is disabled and the
+// static key pair from the environment is used as the only signing key.
+type Service struct {
+	repo             Repository
+	encryptionKey    []byte
+	algorithms       []string // Algorithms with managed keys; the first is the default
+	rotationInterval time.Duration
+	retention        time.Duration
+	refreshInterval  time.Duration
+	mu               sync.Mutex // Serializes rotations started by this instance
+}
+
+// NewService creates a new signing key service using the key rotation settings
+// from the application configuration.
+// The retention period is extended to cover the longest configured token lifetime,
+// refresh tokens and the tokens of the web application included, so that replacing
+// a key never invalidates tokens that have not yet expired.
+func NewService(repo Repository) *Service {
+	s := &Service{repo: repo}
+	if config.AppConfig.JWTKeyEncryptionKey == "" {
+		// Without managed keys the algorithm follows the static key, so a list of algorithms would be ignored
+		if len(config.AppConfig.JWTSigningAlgorithms) > 0 {
+			panic("JWT_SIGNING_ALGORITHMS requires JWT_KEY_ENCRYPTION_KEY")
+		}
+		return s
+	}
+
+	encryptionKey, err := encrypt.ParseKey(config.AppConfig.JWTKeyEncryptionKey)
+	if err != nil {
+		panic("invalid key encryption key: " + err.Error())
+	}
+	s.encryptionKey = encryptionKey
+
+	for _, algorithm := range config.AppConfig.JWTSigningAlgorithms {
+		if !jwtutil.IsSupportedAlgorithm(algorithm) {
+			panic("unsupported signing algorithm: " + algorithm)
+		}
+	}
+	s.algorithms = config.AppConfig.JWTSigningAlgorithms
+	if len(s.algorithms) == 0 {
+		s.algorithms = []string{jwtutil.AlgorithmRS256}
+	}
+
+	s.rotationInterval = mustParseDuration(config.AppConfig.JWTKeyRotationInterval, "key rotation interval")
+	s.retention = mustParseDuration(config.AppConfig.JWTKeyRetention, "key retention")
+	s.refreshInterval = mustParseDuration(config.AppConfig.JWTKeyRefreshInterval, "key refresh interval")
+	if s.refreshInterval <= 0 {
+		panic("invalid key refresh interval: must be positive")
+	}
+
+	lifetimes := []string{
+		config.AppConfig.JWTAccessExpiry,
+		config.AppConfig.JWTRefreshExpiry,
+		config.AppConfig.JWTIDTokenExpiry,
+	}
+	for _, lifetime := range lifetimes {
+		if d, err := time.ParseDuration(lifetime); err == nil && d > s.retention {
+			s.retention = d
+		}
+	}
+
+	return s
+}
+
+// Enabled reports whether keys are managed by this service.
+func (s *Service) Enabled() bool {
+	return s.encryptionKey != nil
+}
+
+// Initialize prepares the persisted key set and loads it into the JWT utility package.
+// On first start the key pair from the environment is imported as the active key
+// for its algorithm, so tokens issued before key rotation was enabled stay valid.
+// An active key and a next key are generated for every other configured algorithm.
+func (s *Service) Initialize(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	keys, err := s.repo.FindUsable(ctx)
+	if err != nil {
+		return err
+	}
+
+	bootstrap, err := jwtutil.BootstrapKey()
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToLoadSigningKeys + ": " + err.Error())
+	}
+
+	now := time.Now()
+
+	for _, algorithm := range s.algorithms {
+		if findByStatus(keys, algorithm, StatusActive) == nil {
+			active := bootstrap
+			if bootstrap.Algorithm != algorithm {
+				if active, err = jwtutil.GenerateSigningKey(algorithm); err != nil {
+					return errors.Internal(errors.ErrMsgFailedToGenerateSigningKey)
+				}
+			}
+			// Another instance may have initialized the key set concurrently
+			if err := s.save(ctx, active, StatusActive, now); err != nil && !isConflict(err) {
+				return err
+			}
+		}
+
+		if findByStatus(keys, algorithm, StatusNext) == nil {
+			next, err := jwtutil.GenerateSigningKey(algorithm)
+			if err != nil {
+				return errors.Internal(errors.ErrMsgFailedToGenerateSigningKey)
+			}
+			if err := s.save(ctx, next, StatusNext, now); err != nil && !isConflict(err) {
+				return err
+			}
+		}
+	}
+
+	return s.Reload(ctx)
+}
+
+// Reload reads all usable keys from the repository and replaces the in-memory key set.
+// Only the private keys of active keys for configured algorithms are decrypted;
+// keys of algorithms that are no longer configured are kept for verification only.
+func (s *Service) Reload(ctx context.Context) error {
+	keys, err := s.repo.FindUsable(ctx)
+	if err != nil {
+		return err
+	}
+
+	activeByAlg := make(map[string]*jwtutil.SigningKey)
+	var verification []*jwtutil.SigningKey
+
+	for _, k := range keys {
+		signs := k.Status == StatusActive && s.isConfigured(k.Algorithm)
+
+		var privateKeyPEM []byte
+		if signs {
+			privateKeyPEM, err = encrypt.Decrypt(s.encryptionKey, k.EncryptedPrivateKey)
+			if err != nil {
+				return errors.Internal(errors.ErrMsgFailedToDecryptSigningKey + ": " + k.KID)
+			}
+		}
+
+		signingKey, err := jwtutil.ParseSigningKey(k.KID, k.Algorithm, k.PublicKey, privateKeyPEM)
+		if err != nil {
+			return errors.Internal(errors.ErrMsgFailedToLoadSigningKeys + ": " + err.Error())
+		}
+
+		if signs {
+			activeByAlg[k.Algorithm] = signingKey
+		} else {
+			verification = append(verification, signingKey)
+		}
+	}
+
+	// Order active keys by configuration so the first configured algorithm is the default
+	var active []*jwtutil.SigningKey
+	for _, algorithm := range s.algorithms {
+		signingKey, ok := activeByAlg[algorithm]
+		if !ok {
+			return errors.Internal(errors.ErrMsgNoActiveSigningKey + ": " + algorithm)
+		}
+		active = append(active, signingKey)
+	}
+
+	if err := jwtutil.SetKeys(active, verification); err != nil {
+		return errors.Internal(errors.ErrMsgFailedToLoadSigningKeys + ": " + err.Error())
+	}
+
+	return nil
+}
+
+// Rotate promotes the next key of every configured algorithm to active and generates new next keys.
+// The replaced keys remain valid for verification for the retention period.
+// Because the promoted key was already published as the next key, tokens it
+// signs are accepted by every instance and JWKS consumer immediately.
+func (s *Service) Rotate(ctx context.Context) (*KeyListResponse, error) {
+	if !s.Enabled() {
+		return nil, errors.BadRequest(errors.ErrMsgKeyRotationDisabled)
+	}
+
+	for _, algorithm := range s.algorithms {
+		if err := s.rotate(ctx, algorithm); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.List(ctx)
+}
+
+// Run performs periodic key maintenance until the context is cancelled.
+// On every tick it retires expired keys, rotates the active key when the rotation
+// interval has elapsed and reloads the key set to pick up changes made by other instances.
+// Errors are passed to onError and do not stop the loop.
+func (s *Service) Run(ctx context.Context, onError func(error)) {
+	if !s.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.maintain(ctx); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// List returns all signing keys known to the repository, including retired ones.
+func (s *Service) List(ctx context.Context) (*KeyListResponse, error) {
+	keys, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &KeyListResponse{
+		Keys:       make([]KeyResponse, 0, len(keys)),
+		ActiveKeys: make(map[string]string),
+	}
+	for _, algorithm := range jwtutil.SigningAlgorithms() {
+		response.ActiveKeys[algorithm] = jwtutil.ActiveKeyID(algorithm)
+	}
+	for _, k := range keys {
+		response.Keys = append(response.Keys, KeyResponse{
+			KID:           k.KID,
+			Algorithm:     k.Algorithm,
+			Status:        k.Status,
+			CreatedAt:     k.CreatedAt,
+			ActivatedAt:   k.ActivatedAt,
+			DeactivatedAt: k.DeactivatedAt,
+			RetiredAt:     k.RetiredAt,
+		})
+	}
+
+	return response, nil
+}
+
+// maintain runs a single round of scheduled key maintenance.
+func (s *Service) maintain(ctx context.Context) error {
+	now := time.Now()
+
+	if _, err := s.repo.RetirePrevious(ctx, now.Add(-s.retention)); err != nil {
+		return err
+	}
+
+	if s.rotationInterval > 0 {
+		keys, err := s.repo.FindUsable(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, algorithm := range s.algorithms {
+			active := findByStatus(keys, algorithm, StatusActive)
+			if active == nil || active.ActivatedAt == nil || now.Sub(*active.ActivatedAt) < s.rotationInterval {
+				continue
+			}
+			// Losing the race to another instance is expected; the reload below picks up its result
+			if err := s.rotate(ctx, algorithm); err != nil && !isConflict(err) {
+				return err
+			}
+		}
+	}
+
+	return s.Reload(ctx)
+}
+
+// rotate performs a rotation of one algorithm's keys against the latest persisted state.
+func (s *Service) rotate(ctx context.Context, algorithm string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Pick up rotations made by other instances before deciding which key to replace
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+
+	next, err := jwtutil.GenerateSigningKey(algorithm)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToGenerateSigningKey)
+	}
+
+	now := time.Now()
+	record, err := s.newRecord(next, StatusNext, now)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Rotate(ctx, algorithm, jwtutil.ActiveKeyID(algorithm), record, now); err != nil {
+		return err
+	}
+
+	return s.Reload(ctx)
+}
+
+// save encrypts and persists a signing key with the given status.
+func (s *Service) save(ctx context.Context, signingKey *jwtutil.SigningKey, status Status, now time.Time) error {
+	record, err := s.newRecord(signingKey, status, now)
+	if err != nil {
+		return err
+	}
+	return s.repo.Save(ctx, record)
+}
+
+// newRecord builds the persisted form of a signing key, encrypting its private key.
+func (s *Service) newRecord(signingKey *jwtutil.SigningKey, status Status, now time.Time) (*SigningKey, error) {
+	privateKeyPEM, err := jwtutil.MarshalPrivateKey(signingKey)
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToEncryptSigningKey + ": " + err.Error())
+	}
+
+	encrypted, err := encrypt.Encrypt(s.encryptionKey, privateKeyPEM)
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToEncryptSigningKey + ": " + err.Error())
+	}
+
+	publicKeyPEM, err := jwtutil.MarshalPublicKey(signingKey)
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToEncryptSigningKey + ": " + err.Error())
+	}
+
+	record := &SigningKey{
+		KID:                 signingKey.ID,
+		Algorithm:           signingKey.Algorithm,
+		Status:              status,
+		EncryptedPrivateKey: encrypted,
+		PublicKey:           publicKeyPEM,
+		CreatedAt:           now,
+	}
+	if status == StatusActive {
+		record.ActivatedAt = &now
+	}
+
+	return record, nil
+}
+
+// isConfigured reports whether the algorithm is one of the configured signing algorithms.
+func (s *Service) isConfigured(algorithm string) bool {
+	for _, configured := range s.algorithms {
+		if configured == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// findByStatus returns the first key of the algorithm with the given status, or nil if none exists.
+func findByStatus(keys []SigningKey, algorithm string, status Status) *SigningKey {
+	for i := range keys {
+		if keys[i].Algorithm == algorithm && keys[i].Status == status {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// isConflict reports whether the error is a 409 Conflict error.
+func isConflict(err error) bool {
+	customErr, ok := err.(errors.CustomError)
+	return ok && customErr.Status == http.StatusConflict
+}
+
+// mustParseDuration parses a configured duration and panics if it is invalid.
+func mustParseDuration(value, name string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		panic("invalid " + name + ": " + err.Error())
+	}
+	return d
+}
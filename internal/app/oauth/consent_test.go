@@ -0,0 +1,149 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/audit"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// newConsentTestService creates a service that can authorize requests of the given client,
+// with a server consent lifetime of one hour.
+func newConsentTestService(t *testing.T, c *client.Client) (*Service, *fakeOAuthRepository, *fakeAuditRepository) {
+	t.Helper()
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+
+	oauthRepo := newFakeOAuthRepository()
+	auditRepo := &fakeAuditRepository{}
+	service := &Service{
+		oauthRepo:       oauthRepo,
+		flowRepo:        newFakeFlowRepository(),
+		clientService:   client.NewService(newFakeClientRepository(c), nil),
+		scopeService:    scope.NewService(&fakeScopeRepository{names: []string{"openid", "profile"}}),
+		auditService:    audit.NewService(auditRepo),
+		consentLifetime: time.Hour,
+	}
+	return service, oauthRepo, auditRepo
+}
+
+// consentTestClient returns an active client that may request the openid and profile scopes.
+func consentTestClient() *client.Client {
+	return &client.Client{
+		ClientID:     "client-a",
+		IsActive:     true,
+		Scope:        "openid profile",
+		RedirectURIs: []string{"https://client.example.com/callback"},
+	}
+}
+
+// consentTestRequest returns an authorization request of the consent test client.
+func consentTestRequest() AuthorizeRequest {
+	return AuthorizeRequest{
+		ResponseType: ResponseTypeCode,
+		ClientID:     "client-a",
+		RedirectURI:  "https://client.example.com/callback",
+		Scope:        "openid",
+	}
+}
+
+func TestTrustedClientConsentIsStored(t *testing.T) {
+	trusted := consentTestClient()
+	trusted.IsTrusted = true
+	service, oauthRepo, auditRepo := newConsentTestService(t, trusted)
+	ctx := context.Background()
+
+	if _, err := service.Authorize(ctx, consentTestRequest(), 1, time.Now()); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	consent, _ := oauthRepo.FindUserConsent(ctx, 1, "client-a")
+	if consent == nil || consent.Scope != "openid" {
+		t.Fatalf("trusted client authorization stored consent %+v", consent)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Status != ConsentOutcomeGranted {
+		t.Fatalf("audit log after the first authorization: %+v", auditRepo.logs)
+	}
+
+	// Later authorizations reuse the consent without another audit entry
+	if _, err := service.Authorize(ctx, consentTestRequest(), 1, time.Now()); err != nil {
+		t.Fatalf("second Authorize: %v", err)
+	}
+	if len(auditRepo.logs) != 1 {
+		t.Errorf("reused consent was audited: %+v", auditRepo.logs)
+	}
+}
+
+func TestConsentRequiredIsEvaluatedOnce(t *testing.T) {
+	service, _, auditRepo := newConsentTestService(t, consentTestClient())
+	ctx := context.Background()
+
+	_, err := service.Authorize(ctx, consentTestRequest(), 1, time.Now())
+	customErr, ok := err.(errors.CustomError)
+	if !ok || customErr.Message != errors.ErrMsgConsentRequired {
+		t.Fatalf("got %v, want consent_required", err)
+	}
+	policy, _ := customErr.Details.(ConsentPolicy)
+	if policy != ConsentPolicyNoConsent {
+		t.Fatalf("policy = %q, want %q", policy, ConsentPolicyNoConsent)
+	}
+
+	session, err := service.CreateAuthorizationSession(ctx, consentTestRequest(), 1, time.Now(), policy)
+	if err != nil {
+		t.Fatalf("CreateAuthorizationSession: %v", err)
+	}
+	if session.Policy != ConsentPolicyNoConsent {
+		t.Errorf("session policy = %q, want %q", session.Policy, ConsentPolicyNoConsent)
+	}
+	if len(auditRepo.logs) != 1 || auditRepo.logs[0].Status != ConsentOutcomeRequired {
+		t.Errorf("audit log after asking for consent: %+v", auditRepo.logs)
+	}
+}
+
+func TestExistingConsentIsNotAudited(t *testing.T) {
+	service, oauthRepo, auditRepo := newConsentTestService(t, consentTestClient())
+	ctx := context.Background()
+
+	oauthRepo.SaveUserConsent(ctx, &UserConsent{UserID: 1, ClientID: "client-a", Scope: "openid profile", UpdatedAt: time.Now()})
+
+	if _, err := service.Authorize(ctx, consentTestRequest(), 1, time.Now()); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if len(auditRepo.logs) != 0 {
+		t.Errorf("silent re-authorization was audited: %+v", auditRepo.logs)
+	}
+}
+
+func TestClientConsentLifetime(t *testing.T) {
+	zero, day := 0, 24*60*60
+	tests := []struct {
+		name     string
+		lifetime *int
+		expired  bool
+	}{
+		{"server default", nil, true},
+		{"never expires", &zero, false},
+		{"client lifetime", &day, false},
+	}
+
+	for _, tt := range tests {
+		c := consentTestClient()
+		c.ConsentLifetime = tt.lifetime
+		service, oauthRepo, _ := newConsentTestService(t, c)
+		ctx := context.Background()
+
+		// The consent is older than the server default of one hour
+		oauthRepo.SaveUserConsent(ctx, &UserConsent{UserID: 1, ClientID: "client-a", Scope: "openid", UpdatedAt: time.Now().Add(-2 * time.Hour)})
+
+		policy, err := service.evaluateConsent(ctx, 1, "client-a", "openid", "")
+		if err != nil {
+			t.Fatalf("%s: evaluateConsent: %v", tt.name, err)
+		}
+		if expired := policy == ConsentPolicyConsentExpired; expired != tt.expired {
+			t.Errorf("%s: policy = %q, want expired %v", tt.name, policy, tt.expired)
+		}
+	}
+}
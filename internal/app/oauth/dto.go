@@ -2,16 +2,34 @@
 // including authorization code, implicit, password, and client credentials.
 package oauth
 
+import "time"
+
 // AuthorizeRequest represents an OAuth 2.0 authorization request.
 // This request initiates the authorization flow as defined in RFC 6749.
+// A request URI obtained from the pushed authorization request endpoint (RFC 9126)
+// takes the place of all other parameters except the client ID.
+// Prompt and MaxAge control re-authentication as defined in OpenID Connect Core 1.0.
 type AuthorizeRequest struct {
-	ResponseType        string `form:"response_type" binding:"required"` // Response type (code, token)
-	ClientID            string `form:"client_id" binding:"required"`     // OAuth client identifier
-	RedirectURI         string `form:"redirect_uri" binding:"required"`  // URI to redirect after authorization
-	Scope               string `form:"scope"`                            // Requested permission scopes
-	State               string `form:"state"`                            // Client state value for CSRF protection
-	CodeChallenge       string `form:"code_challenge"`                   // PKCE code challenge
-	CodeChallengeMethod string `form:"code_challenge_method"`            // PKCE challenge method (plain or S256)
+	ResponseType        string `form:"response_type" json:"response_type"`                           // Response type (code, token)
+	ClientID            string `form:"client_id" json:"client_id"`                                   // OAuth client identifier
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`                             // URI to redirect after authorization
+	Scope               string `form:"scope" json:"scope,omitempty"`                                 // Requested permission scopes
+	State               string `form:"state" json:"state,omitempty"`                                 // Client state value for CSRF protection
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge,omitempty"`               // PKCE code challenge
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method,omitempty"` // PKCE challenge method (plain or S256)
+	Nonce               string `form:"nonce" json:"nonce,omitempty"`                                 // OpenID Connect nonce bound to the ID token
+	Prompt              string `form:"prompt" json:"prompt,omitempty"`                               // Space-separated prompt values (none, login, consent)
+	MaxAge              *int   `form:"max_age" json:"max_age,omitempty"`                             // Maximum age of the user's login in seconds
+	RequestURI          string `form:"request_uri" json:"-"`                                         // Reference to a pushed authorization request
+
+	pushedAt  time.Time // When the request was stored server-side; zero for inline requests
+	consented bool      // Whether the user has just approved the request on the consent page
+}
+
+// PushedAuthorizationResponse is returned from the pushed authorization request endpoint (RFC 9126).
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"` // Reference to pass to the authorization endpoint
+	ExpiresIn  int    `json:"expires_in"`  // Lifetime of the request URI in seconds
 }
 
 // TokenRequest represents an OAuth 2.0 token request.
@@ -26,6 +44,15 @@ type TokenRequest struct {
 	RefreshToken string `form:"refresh_token"`                 // Refresh token (for refresh_token grant)
 	Scope        string `form:"scope"`                         // Requested permission scopes
 	CodeVerifier string `form:"code_verifier"`                 // PKCE code verifier
+	DeviceCode   string `form:"device_code"`                   // Device code (for the device_code grant)
+
+	// Token exchange parameters (RFC 8693)
+	SubjectToken       string `form:"subject_token"`        // Token representing the user
+	SubjectTokenType   string `form:"subject_token_type"`   // Type of the subject token
+	ActorToken         string `form:"actor_token"`          // Token representing the acting party
+	ActorTokenType     string `form:"actor_token_type"`     // Type of the actor token
+	Audience           string `form:"audience"`             // Client ID the requested token is intended for
+	RequestedTokenType string `form:"requested_token_type"` // Type of the requested token
 }
 
 // TokenResponse represents an OAuth 2.0 token response.
@@ -36,6 +63,18 @@ type TokenResponse struct {
 	ExpiresIn    int    `json:"expires_in"`              // Token lifetime in seconds
 	RefreshToken string `json:"refresh_token,omitempty"` // Optional refresh token
 	Scope        string `json:"scope,omitempty"`         // Scope of the access token
+	IDToken      string `json:"id_token,omitempty"`      // OpenID Connect ID token (when openid scope is granted)
+
+	IssuedTokenType string `json:"issued_token_type,omitempty"` // Type of the issued token (token exchange only)
+}
+
+// ClientCredentials holds the credentials a client authenticated with at the token,
+// revocation, introspection or device authorization endpoint.
+type ClientCredentials struct {
+	ClientID        string // OAuth client identifier; may be empty with a client assertion
+	ClientSecret    string // Shared secret (client_secret_basic and client_secret_post)
+	ClientAssertion string // Signed JWT assertion (private_key_jwt)
+	AuthMethod      string // token_endpoint_auth_method the credentials were presented with
 }
 
 type RevokeRequest struct {
@@ -43,6 +82,41 @@ type RevokeRequest struct {
 	TokenTypeHint string `form:"token_type_hint"`
 }
 
+// DeviceAuthorizationRequest represents a device authorization request (RFC 8628).
+// The client is authenticated the same way as at the token endpoint.
+type DeviceAuthorizationRequest struct {
+	Scope string `form:"scope"` // Requested permission scopes
+}
+
+// DeviceAuthorizationResponse contains the codes a device shows to the user and polls with.
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`               // Code the device polls the token endpoint with
+	UserCode                string `json:"user_code"`                 // Code the user enters on the verification page
+	VerificationURI         string `json:"verification_uri"`          // Page where the user enters the code
+	VerificationURIComplete string `json:"verification_uri_complete"` // Verification page with the user code filled in
+	ExpiresIn               int    `json:"expires_in"`                // Lifetime of the codes in seconds
+	Interval                int    `json:"interval"`                  // Minimum polling interval in seconds
+}
+
+// DeviceVerificationData contains the information shown to the user on the device verification page.
+type DeviceVerificationData struct {
+	ConsentPageData
+	UserCode string `json:"user_code"` // Normalized user code being verified
+}
+
+// DeviceVerificationRequest represents the user's decision on the device verification page.
+type DeviceVerificationRequest struct {
+	UserCode  string `json:"user_code" binding:"required"`  // Code shown on the device
+	Consent   bool   `json:"consent"`                       // Whether the user approves the request
+	CSRFToken string `json:"csrf_token" binding:"required"` // Token issued with the verification page
+}
+
+// IntrospectRequest represents a token introspection request (RFC 7662).
+type IntrospectRequest struct {
+	Token         string `form:"token" binding:"required"` // Token to introspect
+	TokenTypeHint string `form:"token_type_hint"`          // Optional hint: access_token or refresh_token
+}
+
 type UserInfoResponse struct {
 	Sub               string `json:"sub"`
 	Name              string `json:"name,omitempty"`
@@ -58,9 +132,97 @@ type ErrorResponse struct {
 }
 
 type ConsentPageData struct {
-	ClientName     string   `json:"client_name"`
-	ClientID       string   `json:"client_id"`
-	RequestedScope string   `json:"requested_scope"`
-	ScopeList      []string `json:"scope_list"`
-	State          string   `json:"state"`
+	ClientName     string             `json:"client_name"`
+	ClientID       string             `json:"client_id"`
+	ClientURI      string             `json:"client_uri,omitempty"` // Homepage of the client
+	LogoURI        string             `json:"logo_uri,omitempty"`   // Logo of the client
+	TOSURI         string             `json:"tos_uri,omitempty"`    // Terms of service of the client
+	PolicyURI      string             `json:"policy_uri,omitempty"` // Privacy policy of the client
+	RequestedScope string             `json:"requested_scope"`
+	ScopeList      []string           `json:"scope_list"`
+	Scopes         []ScopeDescription `json:"scopes"` // Requested scopes with their descriptions
+	State          string             `json:"state"`
+	SessionID      string             `json:"session_id,omitempty"` // Authorization session the decision refers to
+	CSRFToken      string             `json:"csrf_token,omitempty"` // Token to echo with the consent decision
+}
+
+// ScopeDescription describes a requested scope to the user.
+type ScopeDescription struct {
+	Name        string `json:"name"`                  // Scope name
+	Description string `json:"description,omitempty"` // Human-readable description; empty for unregistered scopes
+	Required    bool   `json:"required,omitempty"`    // Whether the client requires the scope, so it cannot be deselected
+}
+
+// ConsentInfo describes an application the user has authorized.
+type ConsentInfo struct {
+	ClientID       string     `json:"client_id"`            // OAuth client identifier
+	ClientName     string     `json:"client_name"`          // Name of the client; empty if the client no longer exists
+	ClientURI      string     `json:"client_uri,omitempty"` // Homepage of the client
+	LogoURI        string     `json:"logo_uri,omitempty"`   // Logo of the client
+	Scope          string     `json:"scope"`                // Space-separated list of granted scopes
+	FirstGrantedAt time.Time  `json:"first_granted_at"`     // When the user first authorized the client
+	LastGrantedAt  time.Time  `json:"last_granted_at"`      // When the user last authorized the client
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"` // When the user will be asked again; absent if the consent does not expire
+}
+
+// ConsentListResponse lists the applications a user has authorized.
+type ConsentListResponse struct {
+	Consents []ConsentInfo `json:"consents"` // Authorized applications, most recently granted first
+}
+
+// ConsentDecisionRequest represents the user's decision on the consent page.
+// It refers to the authorization session only; the request parameters are
+// taken from the session. It is accepted as JSON or as a posted form.
+// When approving, the user may select a subset of the requested scopes;
+// without a selection all of them are approved.
+type ConsentDecisionRequest struct {
+	SessionID string   `form:"session_id" json:"session_id" binding:"required"` // Authorization session shown on the consent page
+	CSRFToken string   `form:"csrf_token" json:"csrf_token" binding:"required"` // Token issued with the consent page
+	Consent   bool     `form:"consent" json:"consent"`                          // Whether the user approves the request
+	Scope     []string `form:"scope" json:"scope"`                              // Approved scopes; nil approves all requested scopes
+}
+
+// LoginRequest represents the form posted from the hosted login page.
+type LoginRequest struct {
+	Email     string `form:"email" binding:"required,email"` // User's email address
+	Password  string `form:"password" binding:"required"`    // User's password
+	ReturnTo  string `form:"return_to"`                      // Local URL to continue with after login
+	CSRFToken string `form:"csrf_token" binding:"required"`  // Token matching the login CSRF cookie
+}
+
+// LoginPageData contains the values rendered into the hosted login page.
+type LoginPageData struct {
+	ReturnTo  string // Local URL to continue with after login
+	CSRFToken string // Token matching the login CSRF cookie
+	Email     string // Email address to fill in again after a failed attempt
+	Error     string // Message shown above the form
+	SignedIn  bool   // Whether the user has just logged in without a page to return to
+}
+
+// DiscoveryResponse represents the authorization server metadata document.
+// It is served both as the OpenID Connect Discovery 1.0 provider configuration
+// and as the OAuth 2.0 Authorization Server Metadata defined in RFC 8414.
+type DiscoveryResponse struct {
+	Issuer                                     string   `json:"issuer"`                                           // Issuer identifier (matches the iss claim)
+	AuthorizationEndpoint                      string   `json:"authorization_endpoint"`                           // URL of the authorization endpoint
+	TokenEndpoint                              string   `json:"token_endpoint"`                                   // URL of the token endpoint
+	UserInfoEndpoint                           string   `json:"userinfo_endpoint"`                                // URL of the UserInfo endpoint
+	JwksURI                                    string   `json:"jwks_uri"`                                         // URL of the JSON Web Key Set
+	RevocationEndpoint                         string   `json:"revocation_endpoint"`                              // URL of the revocation endpoint (RFC 7009)
+	DeviceAuthorizationEndpoint                string   `json:"device_authorization_endpoint"`                    // URL of the device authorization endpoint (RFC 8628)
+	IntrospectionEndpoint                      string   `json:"introspection_endpoint"`                           // URL of the introspection endpoint (RFC 7662)
+	PushedAuthorizationRequestEndpoint         string   `json:"pushed_authorization_request_endpoint"`            // URL of the pushed authorization request endpoint (RFC 9126)
+	RequirePushedAuthorizationRequests         bool     `json:"require_pushed_authorization_requests"`            // Whether every client must use pushed authorization requests
+	ScopesSupported                            []string `json:"scopes_supported"`                                 // Scopes registered on this server
+	ResponseTypesSupported                     []string `json:"response_types_supported"`                         // Supported response_type values
+	ResponseModesSupported                     []string `json:"response_modes_supported"`                         // Supported response_mode values
+	GrantTypesSupported                        []string `json:"grant_types_supported"`                            // Supported grant_type values
+	SubjectTypesSupported                      []string `json:"subject_types_supported"`                          // Supported subject identifier types
+	IDTokenSigningAlgValuesSupported           []string `json:"id_token_signing_alg_values_supported"`            // Algorithms used to sign ID tokens
+	TokenEndpointAuthMethodsSupported          []string `json:"token_endpoint_auth_methods_supported"`            // Client authentication methods at the token endpoint
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported"` // Algorithms accepted for client assertions
+	RevocationEndpointAuthMethodsSupported     []string `json:"revocation_endpoint_auth_methods_supported"`       // Client authentication methods at the revocation endpoint
+	IntrospectionEndpointAuthMethodsSupported  []string `json:"introspection_endpoint_auth_methods_supported"`    // Client authentication methods at the introspection endpoint
+	CodeChallengeMethodsSupported              []string `json:"code_challenge_methods_supported"`                 // Supported PKCE methods
+	ClaimsSupported                            []string `json:"claims_supported"`                                 // Claims that may be returned in ID tokens
 }
@@ -0,0 +1,359 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/audit"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
+)
+
+// fakeScopeRepository is an in-memory scope.Repository of the given scope names.
+type fakeScopeRepository struct {
+	names []string
+}
+
+func (r *fakeScopeRepository) Save(ctx context.Context, s *scope.Scope) error {
+	r.names = append(r.names, s.Name)
+	return nil
+}
+
+func (r *fakeScopeRepository) FindByName(ctx context.Context, name string) (*scope.Scope, error) {
+	for _, n := range r.names {
+		if n == name {
+			return &scope.Scope{Name: n}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeScopeRepository) FindByNames(ctx context.Context, names []string) ([]scope.Scope, error) {
+	var scopes []scope.Scope
+	for _, name := range names {
+		if found, _ := r.FindByName(ctx, name); found != nil {
+			scopes = append(scopes, *found)
+		}
+	}
+	return scopes, nil
+}
+
+func (r *fakeScopeRepository) FindAll(ctx context.Context) ([]scope.Scope, error) {
+	return r.FindByNames(ctx, r.names)
+}
+
+func (r *fakeScopeRepository) FindDefaults(ctx context.Context) ([]scope.Scope, error) {
+	return nil, nil
+}
+
+// fakeAuditRepository is an in-memory audit.Repository.
+type fakeAuditRepository struct {
+	mu   sync.Mutex
+	logs []audit.Log
+}
+
+func (r *fakeAuditRepository) Save(ctx context.Context, log *audit.Log) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *fakeAuditRepository) Find(ctx context.Context, filter audit.ListRequest) ([]audit.Log, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logs, int64(len(r.logs)), nil
+}
+
+// fakeClientRepository is an in-memory client.Repository keyed by client ID.
+type fakeClientRepository struct {
+	clients map[string]*client.Client
+}
+
+func newFakeClientRepository(clients ...*client.Client) *fakeClientRepository {
+	repo := &fakeClientRepository{clients: make(map[string]*client.Client)}
+	for _, c := range clients {
+		repo.clients[c.ClientID] = c
+	}
+	return repo
+}
+
+func (r *fakeClientRepository) Save(ctx context.Context, c *client.Client) error {
+	r.clients[c.ClientID] = c
+	return nil
+}
+
+func (r *fakeClientRepository) Update(ctx context.Context, c *client.Client) error {
+	r.clients[c.ClientID] = c
+	return nil
+}
+
+func (r *fakeClientRepository) FindByID(ctx context.Context, id uint) (*client.Client, error) {
+	for _, c := range r.clients {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeClientRepository) FindByClientID(ctx context.Context, clientID string) (*client.Client, error) {
+	return r.clients[clientID], nil
+}
+
+func (r *fakeClientRepository) FindByOwnerID(ctx context.Context, ownerID uint, page, limit int) ([]client.Client, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeClientRepository) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (r *fakeClientRepository) UpdateStatus(ctx context.Context, id uint, isActive bool) error {
+	return nil
+}
+
+// fakeFlowRepository is an in-memory FlowRepository that ignores expiration.
+type fakeFlowRepository struct {
+	mu             sync.Mutex
+	devices        map[string]*DeviceAuthorization
+	polls          map[string]bool
+	pushedRequests map[string]*PushedAuthorizationRequest
+	sessions       map[string]*AuthorizationSession
+	assertions     map[string]bool
+
+	// afterFindByUserCode, if set, runs after a device authorization was looked up by its user code
+	afterFindByUserCode func()
+}
+
+func newFakeFlowRepository() *fakeFlowRepository {
+	return &fakeFlowRepository{
+		devices:        make(map[string]*DeviceAuthorization),
+		polls:          make(map[string]bool),
+		pushedRequests: make(map[string]*PushedAuthorizationRequest),
+		sessions:       make(map[string]*AuthorizationSession),
+		assertions:     make(map[string]bool),
+	}
+}
+
+func (r *fakeFlowRepository) SaveDeviceAuthorization(ctx context.Context, authorization *DeviceAuthorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *authorization
+	r.devices[authorization.DeviceCode] = &saved
+	return nil
+}
+
+func (r *fakeFlowRepository) FindDeviceAuthorization(ctx context.Context, deviceCode string) (*DeviceAuthorization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if authorization, ok := r.devices[deviceCode]; ok {
+		found := *authorization
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeFlowRepository) FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*DeviceAuthorization, error) {
+	if r.afterFindByUserCode != nil {
+		defer r.afterFindByUserCode()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, authorization := range r.devices {
+		if authorization.UserCode == userCode {
+			found := *authorization
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeFlowRepository) UpdateDeviceAuthorization(ctx context.Context, deviceCode string, update func(*DeviceAuthorization) bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	authorization, ok := r.devices[deviceCode]
+	if !ok {
+		return false, nil
+	}
+	updated := *authorization
+	if !update(&updated) {
+		return false, nil
+	}
+	r.devices[deviceCode] = &updated
+	return true, nil
+}
+
+func (r *fakeFlowRepository) DeleteDeviceAuthorization(ctx context.Context, authorization *DeviceAuthorization) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.devices[authorization.DeviceCode]
+	delete(r.devices, authorization.DeviceCode)
+	delete(r.polls, authorization.DeviceCode)
+	return ok, nil
+}
+
+func (r *fakeFlowRepository) RecordDevicePoll(ctx context.Context, deviceCode string, interval time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.polls[deviceCode] {
+		return false, nil
+	}
+	r.polls[deviceCode] = true
+	return true, nil
+}
+
+func (r *fakeFlowRepository) SavePushedAuthorizationRequest(ctx context.Context, request *PushedAuthorizationRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *request
+	r.pushedRequests[request.RequestURI] = &saved
+	return nil
+}
+
+func (r *fakeFlowRepository) FindPushedAuthorizationRequest(ctx context.Context, requestURI string) (*PushedAuthorizationRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if request, ok := r.pushedRequests[requestURI]; ok {
+		found := *request
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeFlowRepository) DeletePushedAuthorizationRequest(ctx context.Context, requestURI string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pushedRequests, requestURI)
+	return nil
+}
+
+func (r *fakeFlowRepository) SaveAuthorizationSession(ctx context.Context, session *AuthorizationSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *session
+	r.sessions[session.ID] = &saved
+	return nil
+}
+
+func (r *fakeFlowRepository) FindAuthorizationSession(ctx context.Context, id string) (*AuthorizationSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, ok := r.sessions[id]; ok {
+		found := *session
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeFlowRepository) DeleteAuthorizationSession(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.sessions[id]
+	delete(r.sessions, id)
+	return ok, nil
+}
+
+func (r *fakeFlowRepository) RecordClientAssertion(ctx context.Context, clientID, jti string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := clientID + ":" + jti
+	if r.assertions[key] {
+		return false, nil
+	}
+	r.assertions[key] = true
+	return true, nil
+}
+
+// fakeOAuthRepository is an in-memory Repository.
+type fakeOAuthRepository struct {
+	mu       sync.Mutex
+	codes    map[string]*AuthorizationCode
+	consents map[string]*UserConsent
+}
+
+func newFakeOAuthRepository() *fakeOAuthRepository {
+	return &fakeOAuthRepository{
+		codes:    make(map[string]*AuthorizationCode),
+		consents: make(map[string]*UserConsent),
+	}
+}
+
+// consentKey identifies the consent of a user to a client.
+func consentKey(userID uint, clientID string) string {
+	return fmt.Sprintf("%d:%s", userID, clientID)
+}
+
+func (r *fakeOAuthRepository) SaveAuthorizationCode(ctx context.Context, code *AuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *code
+	r.codes[code.Code] = &saved
+	return nil
+}
+
+func (r *fakeOAuthRepository) FindAuthorizationCode(ctx context.Context, code string) (*AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if found, ok := r.codes[code]; ok {
+		copied := *found
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeOAuthRepository) MarkCodeAsUsed(ctx context.Context, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if found, ok := r.codes[code]; ok {
+		found.IsUsed = true
+	}
+	return nil
+}
+
+func (r *fakeOAuthRepository) DeleteExpiredCodes(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeOAuthRepository) SaveUserConsent(ctx context.Context, consent *UserConsent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *consent
+	r.consents[consentKey(consent.UserID, consent.ClientID)] = &saved
+	return nil
+}
+
+func (r *fakeOAuthRepository) FindUserConsent(ctx context.Context, userID uint, clientID string) (*UserConsent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if consent, ok := r.consents[consentKey(userID, clientID)]; ok {
+		found := *consent
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeOAuthRepository) FindUserConsentsByUserID(ctx context.Context, userID uint) ([]UserConsent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var consents []UserConsent
+	for _, consent := range r.consents {
+		if consent.UserID == userID {
+			consents = append(consents, *consent)
+		}
+	}
+	return consents, nil
+}
+
+func (r *fakeOAuthRepository) UpdateUserConsent(ctx context.Context, consent *UserConsent) error {
+	return r.SaveUserConsent(ctx, consent)
+}
+
+func (r *fakeOAuthRepository) DeleteUserConsent(ctx context.Context, userID uint, clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.consents, consentKey(userID, clientID))
+	return nil
+}
@@ -3,59 +3,146 @@
 package oauth
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"encoding/base64"
+	"html/template"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
+	"time"
 
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
 	"github.com/verigate/verigate-server/internal/pkg/middleware"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Route paths registered by the handler, relative to the OAuth route group.
+// They are also used to build the endpoint URLs published in the discovery document.
+const (
+	pathAuthorize           = "/authorize"
+	pathToken               = "/token"
+	pathRevoke              = "/revoke"
+	pathIntrospect          = "/introspect"
+	pathPushedAuthorization = "/par"
+	pathDeviceAuthorization = "/device_authorization"
+	pathDevice              = "/device"
+	pathUserInfo            = "/userinfo"
+	pathJWKS                = "/jwks"
+	pathConsent             = "/consent"
+	pathLogin               = "/login"
+	pathLogout              = "/logout"
+	pathAssets              = "/assets"
+)
+
+// loginCSRFExpiry is how long a login form can be submitted after it was shown.
+// The token is kept in a cookie next to the form (double-submit), since there is no session yet.
+const loginCSRFExpiry = 30 * time.Minute
+
 // Handler manages HTTP requests related to OAuth authorization flows.
 // It handles authorization, token issuance, revocation, and user information endpoints.
 type Handler struct {
-	service *Service
+	service  *Service
+	basePath string                        // Base path of the OAuth route group, set by RegisterRoutes
+	files    fs.FS                         // Page templates and assets, including operator overrides
+	pages    map[string]*template.Template // Parsed page templates by name
 }
 
 // NewHandler creates a new OAuth handler instance.
-// It initializes the handler with the provided service for OAuth operations.
+// It initializes the handler with the provided service for OAuth operations
+// and parses the hosted page templates, which may be overridden from TEMPLATE_DIR.
 func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+	h := &Handler{
+		service: service,
+		files:   newPageFS(config.AppConfig.TemplateDir),
+	}
+
+	pages, err := parsePages(h.files, template.FuncMap{"route": h.route})
+	if err != nil {
+		panic("invalid page templates: " + err.Error())
+	}
+	h.pages = pages
+
+	return h
 }
 
 // RegisterRoutes sets up the OAuth-related routes on the provided router group.
-// Routes are organized into three categories:
-// - Public endpoints: Token issuance and revocation
+// Routes are organized into four categories:
+// - Public endpoints: Token issuance, revocation, introspection, pushed authorization and device authorization
+// - Browser endpoints: Authorization, login and logout, using the login session cookie when present
 // - OAuth protected endpoints: Require OAuth token authorization
-// - Web app protected endpoints: Require web authentication for consent screens
+// - Web app protected endpoints: Require a login session or web authentication for consent and device verification screens
 func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	h.basePath = r.BasePath()
+
 	// Public endpoints
-	r.POST("/token", h.Token)
-	r.POST("/revoke", h.Revoke)
+	r.POST(pathToken, h.Token)
+	r.POST(pathRevoke, h.Revoke)
+	r.POST(pathIntrospect, h.Introspect)
+	r.POST(pathPushedAuthorization, h.PushedAuthorization)
+	r.POST(pathDeviceAuthorization, h.DeviceAuthorization)
+	r.GET(pathJWKS, h.JWKS)
+	r.GET(pathAssets+"/*filepath", h.Asset)
+
+	// Browser endpoints
+	browser := r.Group("")
+	browser.Use(middleware.BrowserSession(h.service.sessionService))
+	{
+		browser.GET(pathAuthorize, h.Authorize)
+		browser.GET(pathLogin, h.ShowLogin)
+		browser.POST(pathLogin, h.HandleLogin)
+		browser.POST(pathLogout, h.Logout)
+	}
 
 	// OAuth protected endpoints
 	oauthProtected := r.Group("")
 	oauthProtected.Use(middleware.Auth())
 	{
-		oauthProtected.GET("/authorize", h.Authorize)
-		oauthProtected.GET("/userinfo", h.UserInfo)
+		oauthProtected.GET(pathUserInfo, h.UserInfo)
 	}
 
-	// Web app protected endpoints (consent screen)
+	// Web app protected endpoints (consent screen and device verification)
 	webProtected := r.Group("")
-	webProtected.Use(middleware.WebAuth(h.service.authService))
+	webProtected.Use(middleware.BrowserSession(h.service.sessionService), middleware.WebAuth(h.service.authService))
 	{
-		webProtected.GET("/consent", h.ShowConsent)
-		webProtected.POST("/consent", h.HandleConsent)
+		webProtected.GET(pathConsent, h.ShowConsent)
+		webProtected.POST(pathConsent, h.HandleConsent)
+		webProtected.GET(pathDevice, h.ShowDeviceVerification)
+		webProtected.POST(pathDevice, h.HandleDeviceVerification)
 	}
 }
 
+// RegisterConsentRoutes sets up the consent management routes on the provided router group,
+// which is expected to be mounted at "/users/me/consents". They let a logged-in user review
+// the applications they have authorized and disconnect them.
+func (h *Handler) RegisterConsentRoutes(r *gin.RouterGroup) {
+	r.Use(middleware.WebAuth(h.service.authService))
+
+	r.GET("", h.ListConsents)                // List authorized applications
+	r.DELETE("/:client_id", h.RevokeConsent) // Disconnect an application
+}
+
+// RegisterWellKnownRoutes sets up the metadata discovery routes on the provided router group,
+// which is expected to be mounted at "/.well-known" on the server root.
+// Both documents are generated from the routes registered by RegisterRoutes.
+func (h *Handler) RegisterWellKnownRoutes(r *gin.RouterGroup) {
+	r.GET("/openid-configuration", h.Discovery)
+	r.GET("/oauth-authorization-server", h.Discovery)
+}
+
 // Authorize handles the OAuth authorization request.
 // This is the entry point for the OAuth authorization code flow.
-// It validates the request, checks if user consent is needed,
-// and either issues an authorization code or redirects to the consent page.
+// It validates the request, checks if the user needs to log in or give consent,
+// and either issues an authorization code or redirects to the login or consent page.
+// The user is identified by the browser login session; with prompt=none, a missing
+// login or consent is reported to the client instead of showing a page.
 func (h *Handler) Authorize(c *gin.Context) {
 	var req AuthorizeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -63,14 +150,55 @@ func (h *Handler) Authorize(c *gin.Context) {
 		return
 	}
 
-	userID := c.GetUint("user_id")
-	code, err := h.service.Authorize(c.Request.Context(), req, userID)
+	// Replace a request URI by the pushed request; its redirect URI is not trusted before that
+	req, err := h.service.ResolveAuthorizeRequest(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	loginSession := middleware.CurrentSession(c)
+	if h.service.RequiresLogin(req, loginSession) {
+		// The redirect URI is not trusted before the request has been validated
+		if _, err := h.service.ValidateAuthorizeRequest(c.Request.Context(), req); err != nil {
+			c.Error(err)
+			return
+		}
+		if hasPrompt(req.Prompt, PromptNone) {
+			c.Redirect(http.StatusFound, h.buildErrorRedirect(req.RedirectURI, req.State, errors.ErrMsgLoginRequired, ""))
+			return
+		}
+
+		// Keep the request server-side and come back to it after login
+		requestURI, err := h.service.SaveAuthorizeRequestForLogin(c.Request.Context(), req)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Redirect(http.StatusFound, h.buildLoginURL(h.buildAuthorizeURL(req.ClientID, requestURI)))
+		return
+	}
+
+	userID := loginSession.UserID
+	authTime := loginSession.AuthTime
+	code, err := h.service.Authorize(c.Request.Context(), req, userID, authTime)
 
 	if err != nil {
 		// Check if consent is required
 		if customErr, ok := err.(errors.CustomError); ok && customErr.Status == 302 {
-			// Redirect to consent page
-			c.Redirect(http.StatusFound, h.buildConsentURL(req))
+			if hasPrompt(req.Prompt, PromptNone) {
+				c.Redirect(http.StatusFound, h.buildErrorRedirect(req.RedirectURI, req.State, errors.ErrMsgConsentRequired, ""))
+				return
+			}
+
+			// Keep the validated request server-side and redirect to the consent page
+			policy, _ := customErr.Details.(ConsentPolicy)
+			session, err := h.service.CreateAuthorizationSession(c.Request.Context(), req, userID, authTime, policy)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.Redirect(http.StatusFound, h.buildConsentURL(session.ID))
 			return
 		}
 
@@ -84,42 +212,119 @@ func (h *Handler) Authorize(c *gin.Context) {
 	c.Redirect(http.StatusFound, redirectURL)
 }
 
+// ShowLogin renders the hosted login page.
+// The return_to parameter names the local page to continue with after login,
+// usually the authorization endpoint; other URLs are ignored.
+func (h *Handler) ShowLogin(c *gin.Context) {
+	csrfToken, err := h.loginCSRFToken(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, loginTemplate, LoginPageData{
+		ReturnTo:  h.safeReturnTo(c.Query("return_to")),
+		CSRFToken: csrfToken,
+	})
+}
+
+// HandleLogin processes the hosted login form.
+// Valid credentials start a new browser login session, replacing any previous one,
+// and the user is sent back to the page they came from.
+// Invalid credentials show the form again with an error message.
+func (h *Handler) HandleLogin(c *gin.Context) {
+	var req LoginRequest
+	bindErr := c.ShouldBind(&req)
+
+	cookieToken, _ := c.Cookie(loginCSRFCookieName())
+	if cookieToken == "" || subtle.ConstantTimeCompare([]byte(cookieToken), []byte(req.CSRFToken)) != 1 {
+		c.Error(errors.Forbidden(errors.ErrMsgInvalidCSRFToken))
+		return
+	}
+
+	data := LoginPageData{
+		ReturnTo:  h.safeReturnTo(req.ReturnTo),
+		CSRFToken: req.CSRFToken,
+		Email:     req.Email,
+	}
+	if bindErr != nil {
+		data.Error = "Enter your email address and password."
+		h.renderPage(c, http.StatusBadRequest, loginTemplate, data)
+		return
+	}
+
+	loginSession, err := h.service.Login(c.Request.Context(), req.Email, req.Password, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok && customErr.Status == http.StatusUnauthorized {
+			data.Error = "The email address or password is incorrect."
+			h.renderPage(c, http.StatusUnauthorized, loginTemplate, data)
+			return
+		}
+		c.Error(err)
+		return
+	}
+
+	// Replace the previous session, e.g. when logging in again for prompt=login
+	if previous := middleware.CurrentSession(c); previous != nil {
+		if err := h.service.Logout(c.Request.Context(), previous.ID); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	middleware.SetSessionCookie(c, loginSession)
+	h.setLoginCSRFCookie(c, "", -1)
+
+	if data.ReturnTo == "" {
+		h.renderPage(c, http.StatusOK, loginTemplate, LoginPageData{SignedIn: true})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, data.ReturnTo)
+}
+
+// Logout ends the browser login session and clears the session cookie.
+// If a local return_to URL is posted, the browser is redirected there.
+func (h *Handler) Logout(c *gin.Context) {
+	if loginSession := middleware.CurrentSession(c); loginSession != nil {
+		if err := h.service.Logout(c.Request.Context(), loginSession.ID); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	middleware.ClearSessionCookie(c)
+
+	if returnTo := h.safeReturnTo(c.PostForm("return_to")); returnTo != "" {
+		c.Redirect(http.StatusSeeOther, returnTo)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // Token handles the OAuth token issuance endpoint.
 // This endpoint supports various grant types including authorization_code,
 // refresh_token, client_credentials, and password grants.
 // It validates the client credentials and issues access and refresh tokens.
 func (h *Handler) Token(c *gin.Context) {
+	defer func() {
+		if len(c.Errors) > 0 {
+			metrics.TokenError(tokenErrorCode(c.Errors.Last().Err))
+		}
+	}()
+
 	var req TokenRequest
 	if err := c.ShouldBind(&req); err != nil {
 		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
 		return
 	}
 
-	// Get client credentials
-	clientID, clientSecret, err := h.getClientCredentials(c, req)
-	if err != nil {
-		c.Error(errors.BadRequest(err.Error()))
+	// Authenticate the client
+	client, ok := h.authenticateClient(c, req)
+	if !ok {
 		return
 	}
 
-	// Validate client if confidential
-	if clientSecret != "" {
-		client, err := h.service.ValidateClient(c.Request.Context(), clientID, clientSecret)
-		if err != nil || client == nil {
-			c.Error(errors.Unauthorized(errors.ErrMsgInvalidClientCredentials))
-			return
-		}
-	} else {
-		// Verify this is a public client
-		isPublic, err := h.service.IsPublicClient(c.Request.Context(), clientID)
-		if err != nil || !isPublic {
-			c.Error(errors.Unauthorized(errors.ErrMsgInvalidClientCredentials))
-			return
-		}
-	}
-
 	// Set client ID in request
-	req.ClientID = clientID
+	req.ClientID = client.ClientID
 
 	token, err := h.service.Token(c.Request.Context(), req)
 	if err != nil {
@@ -127,6 +332,7 @@ func (h *Handler) Token(c *gin.Context) {
 		return
 	}
 
+	metrics.TokenIssued(req.GrantType, req.ClientID)
 	c.JSON(http.StatusOK, token)
 }
 
@@ -141,20 +347,182 @@ func (h *Handler) Revoke(c *gin.Context) {
 		return
 	}
 
-	// Get client credentials
-	clientID, _, err := h.getClientCredentials(c, TokenRequest{})
-	if err != nil {
-		c.Error(errors.Unauthorized(errors.ErrMsgInvalidClientCredentials))
+	client, ok := h.authenticateClient(c, TokenRequest{})
+	if !ok {
 		return
 	}
 
-	if err := h.service.Revoke(c.Request.Context(), req, clientID); err != nil {
+	if err := h.service.Revoke(c.Request.Context(), req, client.ClientID); err != nil {
 		// RFC 7009: Always return success
 	}
 
 	c.Status(http.StatusOK)
 }
 
+// Introspect handles token introspection as specified in RFC 7662.
+// Resource servers use it to check whether an access or refresh token is active.
+// Clients authenticate the same way as at the token endpoint; tokens that are
+// unknown, expired or revoked are reported as inactive rather than as an error.
+func (h *Handler) Introspect(c *gin.Context) {
+	var req IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, TokenRequest{})
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Introspect(c.Request.Context(), req, client)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// PushedAuthorization handles the pushed authorization request endpoint (RFC 9126).
+// An authenticated client posts the parameters of its authorization request and receives
+// a short-lived request URI, which it then passes to the authorization endpoint instead.
+func (h *Handler) PushedAuthorization(c *gin.Context) {
+	var req AuthorizeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, TokenRequest{ClientID: req.ClientID})
+	if !ok {
+		return
+	}
+	if req.ClientID == "" {
+		req.ClientID = client.ClientID
+	}
+
+	response, err := h.service.PushAuthorizationRequest(c.Request.Context(), req, client)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, response)
+}
+
+// DeviceAuthorization handles the device authorization endpoint (RFC 8628).
+// Devices without a browser, such as CLIs and TVs, obtain a device code to poll the token
+// endpoint with and a user code that the user enters on the verification page.
+func (h *Handler) DeviceAuthorization(c *gin.Context) {
+	var req DeviceAuthorizationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, TokenRequest{})
+	if !ok {
+		return
+	}
+
+	response, err := h.service.DeviceAuthorization(c.Request.Context(), req, client, h.basePath)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// ShowDeviceVerification displays the device verification page to the logged-in user.
+// It looks up the pending request for the user code and returns the same information
+// as the consent page, so the user can check which application is asking for access,
+// along with the CSRF token the decision must echo.
+func (h *Handler) ShowDeviceVerification(c *gin.Context) {
+	userCode := c.Query("user_code")
+	if userCode == "" {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidUserCode))
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	data, err := h.service.GetDeviceVerificationData(c.Request.Context(), userCode, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// HandleDeviceVerification processes the user's decision for a device authorization request.
+// Approving records the user's consent; the device then receives tokens on its next poll.
+func (h *Handler) HandleDeviceVerification(c *gin.Context) {
+	var req DeviceVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.service.HandleDeviceVerification(c.Request.Context(), req, userID, middleware.AuthTime(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Discovery serves the authorization server metadata document.
+// It implements both OpenID Connect Discovery 1.0 and RFC 8414 so that
+// standard client libraries can auto-configure against this server.
+func (h *Handler) Discovery(c *gin.Context) {
+	doc, err := h.service.GetDiscoveryDocument(c.Request.Context(), h.basePath)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, doc)
+}
+
+// JWKS serves the JSON Web Key Set containing the public keys used to sign tokens.
+// Resource servers use it to verify access and ID tokens without sharing PEM files.
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=900")
+	c.JSON(http.StatusOK, h.service.GetJWKS())
+}
+
+// Asset serves the static files of the hosted pages, such as the stylesheet.
+// Files in the static/ folder of TEMPLATE_DIR replace the built-in ones.
+func (h *Handler) Asset(c *gin.Context) {
+	name := path.Join("static", strings.TrimPrefix(c.Param("filepath"), "/"))
+	if !fs.ValidPath(name) || !strings.HasPrefix(name, "static/") {
+		c.Error(errors.NotFound(errors.ErrMsgAssetNotFound))
+		return
+	}
+
+	data, err := fs.ReadFile(h.files, name)
+	if err != nil {
+		c.Error(errors.NotFound(errors.ErrMsgAssetNotFound))
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
+
 // UserInfo implements the OpenID Connect UserInfo endpoint.
 // It returns claims about the authenticated user based on the scope
 // of the access token used to access this endpoint.
@@ -172,102 +540,152 @@ func (h *Handler) UserInfo(c *gin.Context) {
 }
 
 // ShowConsent displays the OAuth consent page to the user.
-// This page shows the application name, requested scopes, and allows the user
-// to approve or deny the authorization request.
-// In a production environment, this would typically render an HTML template.
-// ShowConsent displays the consent page to the user.
-// This page presents information about the client application requesting access
-// and the specific permissions (scopes) being requested.
-// The user can then approve or deny these permission requests.
+// This page shows the application and its links, the requested scopes with their
+// descriptions, and allows the user to approve or deny the authorization request.
+// The request is identified by the authorization session created by Authorize,
+// which must belong to the logged-in user.
+// Browsers, which ask for text/html explicitly, get the HTML page; other clients, such as
+// a single-page app rendering its own consent screen, get the page data as JSON, including
+// those that send no Accept header or accept any type.
 func (h *Handler) ShowConsent(c *gin.Context) {
-	clientID := c.Query("client_id")
-	scope := c.Query("scope")
+	userID := c.GetUint("user_id")
 
-	data, err := h.service.GetConsentPageData(c.Request.Context(), clientID, scope)
+	data, err := h.service.GetConsentSessionData(c.Request.Context(), c.Query("session_id"), userID)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	// In a real application, this would render a consent page template
-	c.JSON(http.StatusOK, data)
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEJSON {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, data)
+		return
+	}
+	h.renderPage(c, http.StatusOK, consentTemplate, data)
 }
 
 // HandleConsent processes the user's consent decision for an OAuth authorization request.
-// It receives the user's approval or rejection of the requested permissions
-// and either proceeds with the authorization flow or returns an access_denied error.
+// The decision refers to an authorization session and must carry the CSRF token issued with
+// the consent page. The original request stored in the session is replayed, so nothing but the
+// decision itself is taken from this request. Approving proceeds with the authorization flow
+// for the scopes the user selected; denying, or approving no scope at all, returns an
+// access_denied error to the client.
+// A decision posted from the HTML consent page redirects the browser to the client;
+// a JSON decision gets the redirect URL in the response body.
 func (h *Handler) HandleConsent(c *gin.Context) {
-	type ConsentRequest struct {
-		ClientID string `json:"client_id" binding:"required"`
-		Scope    string `json:"scope" binding:"required"`
-		Consent  bool   `json:"consent" binding:"required"`
-	}
-
-	var req ConsentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	var req ConsentDecisionRequest
+	if err := c.ShouldBind(&req); err != nil {
 		c.Error(errors.BadRequest(errors.ErrMsgInvalidRequestFormat))
 		return
 	}
 
 	userID := c.GetUint("user_id")
 
-	if !req.Consent {
+	session, err := h.service.CompleteAuthorizationSession(c.Request.Context(), req, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	authReq := session.Request
+
+	if !req.Consent || session.Scope == "" {
 		// User denied consent
-		c.JSON(http.StatusOK, gin.H{
-			"redirect": h.buildErrorRedirect(c.Query("redirect_uri"), c.Query("state"), errors.ErrMsgAccessDenied, errors.ErrMsgUserDeniedAccess),
-		})
+		h.consentRedirect(c, h.buildErrorRedirect(authReq.RedirectURI, authReq.State, errors.ErrMsgAccessDenied, errors.ErrMsgUserDeniedAccess))
 		return
 	}
 
-	// Save consent
-	if err := h.service.SaveConsent(c.Request.Context(), userID, req.ClientID, req.Scope); err != nil {
+	// Save consent for the approved scopes, which the authorization code is limited to as well
+	if err := h.service.SaveConsent(c.Request.Context(), userID, authReq.ClientID, session.Scope); err != nil {
 		c.Error(err)
 		return
 	}
+	authReq.Scope = session.Scope
 
-	// Create authorization request to retry
-	authReq := AuthorizeRequest{
-		ResponseType:        "code",
-		ClientID:            req.ClientID,
-		RedirectURI:         c.Query("redirect_uri"),
-		Scope:               req.Scope,
-		State:               c.Query("state"),
-		CodeChallenge:       c.Query("code_challenge"),
-		CodeChallengeMethod: c.Query("code_challenge_method"),
+	code, err := h.service.Authorize(c.Request.Context(), authReq, userID, session.AuthTime)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	code, err := h.service.Authorize(c.Request.Context(), authReq, userID)
+	h.consentRedirect(c, h.buildRedirectURL(authReq.RedirectURI, code, authReq.State))
+}
+
+// ListConsents returns the applications the authenticated user has authorized.
+//
+// Route: GET /users/me/consents
+func (h *Handler) ListConsents(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	consents, err := h.service.ListUserConsents(c.Request.Context(), userID)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"redirect": h.buildRedirectURL(authReq.RedirectURI, code, authReq.State),
-	})
+	c.JSON(http.StatusOK, consents)
+}
+
+// RevokeConsent removes the authenticated user's consent for a client and revokes
+// all tokens the client holds for the user.
+//
+// Route: DELETE /users/me/consents/:client_id
+func (h *Handler) RevokeConsent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.service.RevokeUserConsent(c.Request.Context(), userID, c.Param("client_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // Helper methods
 
+// authenticateClient authenticates the client making a token, revocation or introspection request.
+// Confidential clients must present a valid secret or assertion with their registered
+// authentication method; requests without credentials are only accepted from public clients.
+// On failure the error is added to the context and false is returned.
+func (h *Handler) authenticateClient(c *gin.Context, req TokenRequest) (*client.Client, bool) {
+	credentials, err := h.getClientCredentials(c, req)
+	if err != nil {
+		c.Error(errors.BadRequest(err.Error()))
+		return nil, false
+	}
+
+	client, err := h.service.AuthenticateClient(c.Request.Context(), credentials, h.basePath)
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	return client, true
+}
+
 // getClientCredentials extracts client credentials from the request.
 // It first tries to get credentials from the Authorization header using HTTP Basic auth,
-// and falls back to form parameters if not found in the header.
-// Returns the client ID, client secret (may be empty for public clients), and any error that occurred.
-func (h *Handler) getClientCredentials(c *gin.Context, req TokenRequest) (string, string, error) {
+// then looks for a JWT client assertion (RFC 7523), and falls back to form parameters.
+// The returned credentials record the token_endpoint_auth_method they were presented with.
+// The client ID may be omitted with an assertion, whose subject identifies the client.
+func (h *Handler) getClientCredentials(c *gin.Context, req TokenRequest) (ClientCredentials, error) {
 	// Try Authorization header first
 	authHeader := c.GetHeader("Authorization")
 	if authHeader != "" && strings.HasPrefix(authHeader, "Basic ") {
 		credentials, err := base64.StdEncoding.DecodeString(authHeader[6:])
 		if err != nil {
-			return "", "", errors.BadRequest(errors.ErrMsgInvalidBasicAuthFormat)
+			return ClientCredentials{}, errors.BadRequest(errors.ErrMsgInvalidBasicAuthFormat)
 		}
 
 		parts := strings.SplitN(string(credentials), ":", 2)
 		if len(parts) != 2 {
-			return "", "", errors.BadRequest(errors.ErrMsgInvalidBasicAuthFormat)
+			return ClientCredentials{}, errors.BadRequest(errors.ErrMsgInvalidBasicAuthFormat)
 		}
 
-		return parts[0], parts[1], nil
+		return ClientCredentials{
+			ClientID:     parts[0],
+			ClientSecret: parts[1],
+			AuthMethod:   client.AuthMethodClientSecretBasic,
+		}, nil
 	}
 
 	// Fall back to form parameters
@@ -276,16 +694,35 @@ func (h *Handler) getClientCredentials(c *gin.Context, req TokenRequest) (string
 		clientID = c.PostForm("client_id")
 	}
 
+	if assertionType := c.PostForm("client_assertion_type"); assertionType != "" {
+		assertion := c.PostForm("client_assertion")
+		if assertionType != ClientAssertionTypeJWTBearer || assertion == "" {
+			return ClientCredentials{}, errors.BadRequest(errors.ErrMsgInvalidClientAssertion)
+		}
+		return ClientCredentials{
+			ClientID:        clientID,
+			ClientAssertion: assertion,
+			AuthMethod:      client.AuthMethodPrivateKeyJWT,
+		}, nil
+	}
+
 	clientSecret := req.ClientSecret
 	if clientSecret == "" {
 		clientSecret = c.PostForm("client_secret")
 	}
 
 	if clientID == "" {
-		return "", "", errors.BadRequest(errors.ErrMsgMissingClientId)
+		return ClientCredentials{}, errors.BadRequest(errors.ErrMsgMissingClientId)
 	}
 
-	return clientID, clientSecret, nil
+	if clientSecret == "" {
+		return ClientCredentials{ClientID: clientID, AuthMethod: client.AuthMethodNone}, nil
+	}
+	return ClientCredentials{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthMethod:   client.AuthMethodClientSecretPost,
+	}, nil
 }
 
 // buildRedirectURL constructs the OAuth callback URL with authorization code and state parameters.
@@ -305,6 +742,44 @@ func (h *Handler) buildRedirectURL(redirectURI, code, state string) string {
 	return result
 }
 
+// tokenErrorCodes are the error codes the token endpoint responds with (RFC 6749, RFC 8628, RFC 8693).
+var tokenErrorCodes = map[string]bool{
+	errors.ErrMsgInvalidRequest:       true,
+	errors.ErrMsgInvalidClient:        true,
+	errors.ErrMsgInvalidGrant:         true,
+	errors.ErrMsgUnauthorizedClient:   true,
+	errors.ErrMsgUnsupportedGrantType: true,
+	errors.ErrMsgInvalidScope:         true,
+	errors.ErrMsgInvalidTarget:        true,
+	errors.ErrMsgAuthorizationPending: true,
+	errors.ErrMsgSlowDown:             true,
+	errors.ErrMsgAccessDenied:         true,
+	errors.ErrMsgExpiredToken:         true,
+}
+
+// tokenErrorCode returns the OAuth error code a token endpoint error stands for, for metrics.
+// Errors whose message is not an error code are classified by status: server errors as
+// server_error, 401 responses (rejected tokens and codes) as invalid_grant and any other
+// client error as invalid_request.
+func tokenErrorCode(err error) string {
+	customErr, ok := err.(errors.CustomError)
+	if !ok {
+		return "server_error"
+	}
+	if tokenErrorCodes[customErr.Message] {
+		return customErr.Message
+	}
+
+	switch {
+	case customErr.Status >= http.StatusInternalServerError:
+		return "server_error"
+	case customErr.Status == http.StatusUnauthorized:
+		return errors.ErrMsgInvalidGrant
+	default:
+		return errors.ErrMsgInvalidRequest
+	}
+}
+
 // buildErrorRedirect constructs an OAuth error redirect URL according to the OAuth 2.0 specification.
 // It includes the error code, error description (with spaces replaced by '+'), and preserves the state parameter.
 func (h *Handler) buildErrorRedirect(redirectURI, state, errorCode, errorDesc string) string {
@@ -339,22 +814,106 @@ func (h *Handler) redirectError(c *gin.Context, redirectURI, state, errorCode, e
 	c.Redirect(http.StatusFound, h.buildErrorRedirect(redirectURI, state, errorCode, errorDesc))
 }
 
-// buildConsentURL constructs the URL for the consent page, preserving all the
-// parameters from the original authorization request to use after consent.
-// This ensures the OAuth flow can continue with the same parameters once
-// the user has provided their consent decision.
-func (h *Handler) buildConsentURL(req AuthorizeRequest) string {
-	params := []string{
-		"client_id=" + req.ClientID,
-		"redirect_uri=" + req.RedirectURI,
-		"scope=" + req.Scope,
-		"state=" + req.State,
+// buildAuthorizeURL constructs the authorization endpoint URL for a request stored on the server.
+func (h *Handler) buildAuthorizeURL(clientID, requestURI string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("request_uri", requestURI)
+	return h.basePath + pathAuthorize + "?" + params.Encode()
+}
+
+// buildLoginURL constructs the URL of the hosted login page, which returns to returnTo after login.
+func (h *Handler) buildLoginURL(returnTo string) string {
+	params := url.Values{}
+	params.Set("return_to", returnTo)
+	return h.basePath + pathLogin + "?" + params.Encode()
+}
+
+// safeReturnTo returns returnTo if it is a local URL below the OAuth route group,
+// and an empty string otherwise, so the login page cannot be used as an open redirector.
+func (h *Handler) safeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, h.basePath+"/") || strings.Contains(returnTo, "\\") {
+		return ""
+	}
+	u, err := url.Parse(returnTo)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return ""
+	}
+	return returnTo
+}
+
+// loginCSRFToken returns the CSRF token of the login form, reusing the one in the
+// login CSRF cookie so that several open login pages stay valid.
+func (h *Handler) loginCSRFToken(c *gin.Context) (string, error) {
+	if token, err := c.Cookie(loginCSRFCookieName()); err == nil && token != "" {
+		return token, nil
+	}
+
+	token, err := h.service.generateAuthorizationCode()
+	if err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToCreateSession)
+	}
+	h.setLoginCSRFCookie(c, token, int(loginCSRFExpiry.Seconds()))
+	return token, nil
+}
+
+// setLoginCSRFCookie sets or, with a negative maxAge, removes the login CSRF cookie.
+// It is only sent to the login page.
+func (h *Handler) setLoginCSRFCookie(c *gin.Context, token string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     loginCSRFCookieName(),
+		Value:    token,
+		Path:     h.basePath + pathLogin,
+		MaxAge:   maxAge,
+		Secure:   config.AppConfig.SessionCookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// loginCSRFCookieName returns the name of the login CSRF cookie, derived from the session cookie name.
+func loginCSRFCookieName() string {
+	return config.AppConfig.SessionCookieName + "_csrf"
+}
+
+// consentRedirect sends the user on to the client after a consent decision: with a redirect
+// for a form posted from the consent page, and as JSON for the single-page app.
+func (h *Handler) consentRedirect(c *gin.Context, redirectURL string) {
+	if c.ContentType() == gin.MIMEPOSTForm {
+		c.Redirect(http.StatusSeeOther, redirectURL)
+		return
 	}
 
-	if req.CodeChallenge != "" {
-		params = append(params, "code_challenge="+req.CodeChallenge)
-		params = append(params, "code_challenge_method="+req.CodeChallengeMethod)
+	c.JSON(http.StatusOK, gin.H{
+		"redirect": redirectURL,
+	})
+}
+
+// renderPage renders a hosted page template. Pages are never cached, may not be framed,
+// and only load scripts and styles from this server.
+func (h *Handler) renderPage(c *gin.Context, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := h.pages[name].Execute(&buf, data); err != nil {
+		c.Error(errors.Internal(errors.ErrMsgFailedToRenderPage))
+		return
 	}
 
-	return "/oauth/consent?" + strings.Join(params, "&")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'self'; img-src 'self' https: data:; frame-ancestors 'none'")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// route returns the URL of a path in the OAuth route group; templates use it to link pages and assets.
+func (h *Handler) route(p string) string {
+	return h.basePath + p
+}
+
+// buildConsentURL constructs the URL for the consent page of an authorization session.
+// The request parameters stay on the server, so nothing but the session ID ends up
+// in the browser history.
+func (h *Handler) buildConsentURL(sessionID string) string {
+	params := url.Values{}
+	params.Set("session_id", sessionID)
+	return h.basePath + pathConsent + "?" + params.Encode()
 }
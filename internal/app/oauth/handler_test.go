@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+)
+
+func TestShowConsentNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+
+	flowRepo := newFakeFlowRepository()
+	service := &Service{
+		flowRepo:      flowRepo,
+		clientService: client.NewService(newFakeClientRepository(&client.Client{ClientID: "client-a", ClientName: "Client A"}), nil),
+		scopeService:  scope.NewService(&fakeScopeRepository{names: []string{"openid"}}),
+	}
+	flowRepo.SaveAuthorizationSession(context.Background(), &AuthorizationSession{
+		ID:        "session",
+		UserID:    1,
+		Request:   AuthorizeRequest{ClientID: "client-a"},
+		Scope:     "openid",
+		CSRFToken: "csrf",
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+
+	handler := NewHandler(service)
+	router := gin.New()
+	router.GET("/consent", func(c *gin.Context) { c.Set("user_id", uint(1)) }, handler.ShowConsent)
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/json", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html"},
+		{"text/html", "text/html"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/consent?session_id=session", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Accept %q: status %d", tt.accept, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+			t.Errorf("Accept %q: content type %q, want %s", tt.accept, got, tt.contentType)
+		}
+	}
+}
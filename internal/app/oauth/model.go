@@ -18,6 +18,8 @@ type AuthorizationCode struct {
 	Scope               string    `json:"scope"`                           // Space-separated list of authorized scopes
 	CodeChallenge       string    `json:"code_challenge,omitempty"`        // PKCE code challenge (optional)
 	CodeChallengeMethod string    `json:"code_challenge_method,omitempty"` // PKCE challenge method (plain or S256)
+	Nonce               string    `json:"nonce,omitempty"`                 // OpenID Connect nonce to echo in the ID token
+	AuthTime            time.Time `json:"auth_time"`                       // When the user authenticated
 	ExpiresAt           time.Time `json:"expires_at"`                      // Expiration timestamp
 	CreatedAt           time.Time `json:"created_at"`                      // Creation timestamp
 	IsUsed              bool      `json:"is_used"`                         // Whether the code has been used
@@ -33,3 +35,85 @@ type UserConsent struct {
 	CreatedAt time.Time `json:"created_at"` // When consent was first granted
 	UpdatedAt time.Time `json:"updated_at"` // When consent was last updated
 }
+
+// ConsentPolicy names the rule that decided whether the user is asked for consent.
+// It is recorded in the audit log with every consent decision.
+type ConsentPolicy string
+
+// Consent policies, in the order they are evaluated
+const (
+	ConsentPolicyPromptConsent      ConsentPolicy = "prompt_consent"      // The client sent prompt=consent
+	ConsentPolicyExistingConsent    ConsentPolicy = "existing_consent"    // The stored consent covers the request
+	ConsentPolicyTrustedClient      ConsentPolicy = "trusted_client"      // First-party client that never asks
+	ConsentPolicyNoConsent          ConsentPolicy = "no_consent"          // The user has not authorized the client yet
+	ConsentPolicyConsentExpired     ConsentPolicy = "consent_expired"     // The stored consent is older than the consent lifetime
+	ConsentPolicyScopeNotGranted    ConsentPolicy = "scope_not_granted"   // The request asks for scopes beyond the stored consent
+	ConsentPolicyDeviceVerification ConsentPolicy = "device_verification" // The user approves on the device verification page
+)
+
+// RequiresConsent reports whether the policy sends the user to the consent page.
+func (p ConsentPolicy) RequiresConsent() bool {
+	return p != ConsentPolicyTrustedClient && p != ConsentPolicyExistingConsent
+}
+
+// Outcomes of consent decisions recorded in the audit log
+const (
+	ConsentOutcomeGranted  = "granted"  // Granted to a trusted client without asking the user
+	ConsentOutcomeRequired = "required" // The user was asked, or consent_required was returned for prompt=none
+	ConsentOutcomeApproved = "approved" // The user approved on the consent page
+	ConsentOutcomeDenied   = "denied"   // The user denied, or approved no scope
+)
+
+// DeviceAuthorizationStatus represents the state of a device authorization request.
+type DeviceAuthorizationStatus string
+
+// Device authorization states
+const (
+	DeviceStatusPending  DeviceAuthorizationStatus = "pending"  // Waiting for the user to enter the code
+	DeviceStatusApproved DeviceAuthorizationStatus = "approved" // The user approved the request
+	DeviceStatusDenied   DeviceAuthorizationStatus = "denied"   // The user denied the request
+)
+
+// DeviceAuthorization represents a pending device authorization request (RFC 8628).
+// It is stored in Redis until the device exchanges it for tokens or it expires.
+type DeviceAuthorization struct {
+	DeviceCode string                    `json:"device_code"`            // Code the device polls the token endpoint with
+	UserCode   string                    `json:"user_code"`              // Short code the user enters on the verification page
+	ClientID   string                    `json:"client_id"`              // Client that started the request
+	Scope      string                    `json:"scope"`                  // Space-separated list of requested scopes
+	Status     DeviceAuthorizationStatus `json:"status"`                 // Current state of the request
+	UserID     uint                      `json:"user_id,omitempty"`      // User who approved or denied the request
+	AuthTime   time.Time                 `json:"auth_time"`              // When the approving user authenticated
+	Interval   int                       `json:"interval"`               // Minimum polling interval in seconds
+	CSRFToken  string                    `json:"csrf_token,omitempty"`   // Token the decision on the verification page must echo
+	CSRFUserID uint                      `json:"csrf_user_id,omitempty"` // User the CSRF token was issued to
+	ExpiresAt  time.Time                 `json:"expires_at"`             // Expiration timestamp
+	CreatedAt  time.Time                 `json:"created_at"`             // Creation timestamp
+}
+
+// PushedAuthorizationRequest represents an authorization request a client pushed to the
+// server before redirecting the user (RFC 9126). The user agent only carries its request URI,
+// so the parameters can neither be read from the browser history nor be tampered with.
+type PushedAuthorizationRequest struct {
+	RequestURI string           `json:"request_uri"` // Reference passed to the authorization endpoint
+	Request    AuthorizeRequest `json:"request"`     // The pushed authorization parameters
+	ExpiresAt  time.Time        `json:"expires_at"`  // Expiration timestamp
+	CreatedAt  time.Time        `json:"created_at"`  // Creation timestamp
+}
+
+// AuthorizationSession holds a validated authorization request while the user is asked for
+// consent. The consent page refers to it by its opaque ID only, so the request parameters
+// cannot be altered between the authorization request and the consent decision.
+// A session belongs to the user it was created for and can be completed once.
+type AuthorizationSession struct {
+	ID         string           `json:"id"`                    // Opaque identifier used by the consent page
+	UserID     uint             `json:"user_id"`               // User the request was made for
+	Request    AuthorizeRequest `json:"request"`               // The validated authorization request
+	RequestURI string           `json:"request_uri,omitempty"` // Pushed request the session was created from, if any
+	Scope      string           `json:"scope"`                 // Validated scope the user is asked to approve
+	Policy     ConsentPolicy    `json:"policy"`                // Why the user is asked for consent
+	CSRFToken  string           `json:"csrf_token"`            // Token the consent decision must echo
+	AuthTime   time.Time        `json:"auth_time"`             // When the user authenticated
+	ExpiresAt  time.Time        `json:"expires_at"`            // Expiration timestamp
+	CreatedAt  time.Time        `json:"created_at"`            // Creation timestamp
+}
@@ -4,6 +4,7 @@ package oauth
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for OAuth data storage and retrieval operations.
@@ -20,8 +21,9 @@ type Repository interface {
 	// MarkCodeAsUsed updates an authorization code to indicate it has been exchanged for tokens
 	MarkCodeAsUsed(ctx context.Context, code string) error
 
-	// DeleteExpiredCodes removes expired authorization codes from storage
-	DeleteExpiredCodes(ctx context.Context) error
+	// DeleteExpiredCodes removes authorization codes that expired before the given time,
+	// whether they were used or not, and returns the number of codes removed
+	DeleteExpiredCodes(ctx context.Context, before time.Time) (int64, error)
 
 	// User consent methods
 
@@ -31,9 +33,69 @@ type Repository interface {
 	// FindUserConsent retrieves a user's consent record for a specific client
 	FindUserConsent(ctx context.Context, userID uint, clientID string) (*UserConsent, error)
 
+	// FindUserConsentsByUserID retrieves all consent records of a user, most recently granted first
+	FindUserConsentsByUserID(ctx context.Context, userID uint) ([]UserConsent, error)
+
 	// UpdateUserConsent updates an existing user consent record, typically for scope changes
 	UpdateUserConsent(ctx context.Context, consent *UserConsent) error
 
 	// DeleteUserConsent removes a user's consent for a specific client
 	DeleteUserConsent(ctx context.Context, userID uint, clientID string) error
 }
+
+// FlowRepository defines storage for short-lived authorization flow state.
+// Entries expire on their own, so implementations are expected to be backed by a cache.
+type FlowRepository interface {
+	// Device authorization methods
+
+	// SaveDeviceAuthorization stores a device authorization, indexed by both its device and user code
+	SaveDeviceAuthorization(ctx context.Context, authorization *DeviceAuthorization) error
+
+	// FindDeviceAuthorization retrieves a device authorization by its device code
+	FindDeviceAuthorization(ctx context.Context, deviceCode string) (*DeviceAuthorization, error)
+
+	// FindDeviceAuthorizationByUserCode retrieves a device authorization by its user code
+	FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+
+	// UpdateDeviceAuthorization applies an update to the stored device authorization atomically.
+	// The update is given the current state and returns false to leave it unchanged.
+	// Returns whether the authorization was changed; false if it no longer exists
+	UpdateDeviceAuthorization(ctx context.Context, deviceCode string, update func(*DeviceAuthorization) bool) (bool, error)
+
+	// DeleteDeviceAuthorization removes a device authorization and reports whether it still existed,
+	// so that only one caller can consume it
+	DeleteDeviceAuthorization(ctx context.Context, authorization *DeviceAuthorization) (bool, error)
+
+	// RecordDevicePoll records a token request for a device code and reports false
+	// if the previous request was made less than the interval ago
+	RecordDevicePoll(ctx context.Context, deviceCode string, interval time.Duration) (bool, error)
+
+	// Pushed authorization request methods
+
+	// SavePushedAuthorizationRequest stores a pushed authorization request until it expires
+	SavePushedAuthorizationRequest(ctx context.Context, request *PushedAuthorizationRequest) error
+
+	// FindPushedAuthorizationRequest retrieves a pushed authorization request by its request URI
+	FindPushedAuthorizationRequest(ctx context.Context, requestURI string) (*PushedAuthorizationRequest, error)
+
+	// DeletePushedAuthorizationRequest removes a pushed authorization request once it has been used
+	DeletePushedAuthorizationRequest(ctx context.Context, requestURI string) error
+
+	// Authorization session methods
+
+	// SaveAuthorizationSession stores an authorization session until it expires
+	SaveAuthorizationSession(ctx context.Context, session *AuthorizationSession) error
+
+	// FindAuthorizationSession retrieves an authorization session by its ID
+	FindAuthorizationSession(ctx context.Context, id string) (*AuthorizationSession, error)
+
+	// DeleteAuthorizationSession removes an authorization session and reports whether it still existed,
+	// so that only one consent decision can complete it
+	DeleteAuthorizationSession(ctx context.Context, id string) (bool, error)
+
+	// Client authentication methods
+
+	// RecordClientAssertion records the token ID of a client assertion until it expires
+	// and reports false if the assertion was already used
+	RecordClientAssertion(ctx context.Context, clientID, jti string, expiresAt time.Time) (bool, error)
+}
@@ -3,50 +3,1217 @@ package oauth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/verigate/verigate-server/internal/app/audit"
 	"github.com/verigate/verigate-server/internal/app/auth"
 	"github.com/verigate/verigate-server/internal/app/client"
 	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/app/session"
 	"github.com/verigate/verigate-server/internal/app/token"
 	"github.com/verigate/verigate-server/internal/app/user"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
 	"github.com/verigate/verigate-server/internal/pkg/utils/pkce"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Supported OAuth 2.0 grant types
+const (
+	GrantTypeAuthorizationCode = client.GrantTypeAuthorizationCode
+	GrantTypeRefreshToken      = client.GrantTypeRefreshToken
+	GrantTypeClientCredentials = client.GrantTypeClientCredentials
+	GrantTypeDeviceCode        = client.GrantTypeDeviceCode
+	GrantTypeTokenExchange     = client.GrantTypeTokenExchange
+)
+
+// Client authentication with JWT assertions
+const (
+	// ClientAssertionTypeJWTBearer is the client_assertion_type of JWT client assertions (RFC 7523)
+	ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// maxClientAssertionLifetime bounds how far in the future a client assertion may expire,
+	// which also bounds how long its token ID is remembered for replay detection
+	maxClientAssertionLifetime = time.Hour
+)
+
+// User codes are drawn from consonants only, which avoids ambiguous characters and
+// accidental words (RFC 8628, section 6.1)
+const (
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+)
+
+// deviceSlowDownIncrement is the number of seconds a slow_down response adds to the polling interval
+const deviceSlowDownIncrement = 5
+
+// authorizationSessionExpiry is how long the user has to decide on the consent page
+const authorizationSessionExpiry = 10 * time.Minute
+
+// requestURIPrefix is the URN prefix of request URIs issued for pushed authorization requests (RFC 9126)
+const requestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// Values of the OpenID Connect prompt parameter
+const (
+	PromptNone          = "none"
+	PromptLogin         = "login"
+	PromptConsent       = "consent"
+	PromptSelectAccount = "select_account"
+)
+
+// Audit log action of consent decisions
+const auditActionConsentDecision = "consent.decision"
+
+// Supported OAuth 2.0 response types
+const (
+	ResponseTypeCode = client.ResponseTypeCode
 )
 
 type Service struct {
-	oauthRepo     Repository
-	userService   *user.Service
-	clientService *client.Service
-	tokenService  *token.Service
-	scopeService  *scope.Service
-	authService   *auth.Service
+	oauthRepo          Repository
+	flowRepo           FlowRepository
+	userService        *user.Service
+	clientService      *client.Service
+	tokenService       *token.Service
+	scopeService       *scope.Service
+	authService        *auth.Service
+	sessionService     *session.Service
+	auditService       *audit.Service
+	deviceCodeExpiry   time.Duration
+	devicePollInterval time.Duration
+	parRequestExpiry   time.Duration
+	consentLifetime    time.Duration
 }
 
 func NewService(
 	oauthRepo Repository,
+	flowRepo FlowRepository,
 	userService *user.Service,
 	clientService *client.Service,
 	tokenService *token.Service,
 	scopeService *scope.Service,
 	authService *auth.Service,
+	sessionService *session.Service,
+	auditService *audit.Service,
 ) *Service {
+	deviceCodeExpiry, err := time.ParseDuration(config.AppConfig.DeviceCodeExpiry)
+	if err != nil {
+		panic("invalid device code expiry: " + err.Error())
+	}
+
+	devicePollInterval, err := time.ParseDuration(config.AppConfig.DevicePollInterval)
+	if err != nil {
+		panic("invalid device poll interval: " + err.Error())
+	}
+
+	parRequestExpiry, err := time.ParseDuration(config.AppConfig.PARRequestExpiry)
+	if err != nil {
+		panic("invalid pushed authorization request expiry: " + err.Error())
+	}
+
+	consentLifetime, err := time.ParseDuration(config.AppConfig.ConsentLifetime)
+	if err != nil {
+		panic("invalid consent lifetime: " + err.Error())
+	}
+
 	return &Service{
-		oauthRepo:     oauthRepo,
-		userService:   userService,
-		clientService: clientService,
-		tokenService:  tokenService,
-		scopeService:  scopeService,
-		authService:   authService,
+		oauthRepo:          oauthRepo,
+		flowRepo:           flowRepo,
+		userService:        userService,
+		clientService:      clientService,
+		tokenService:       tokenService,
+		scopeService:       scopeService,
+		authService:        authService,
+		sessionService:     sessionService,
+		auditService:       auditService,
+		deviceCodeExpiry:   deviceCodeExpiry,
+		devicePollInterval: devicePollInterval,
+		parRequestExpiry:   parRequestExpiry,
+		consentLifetime:    consentLifetime,
+	}
+}
+
+func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, userID uint, authTime time.Time) (string, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.Authorize")
+	defer span.End()
+
+	requestedScope, err := s.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	// Check if consent is needed, unless the user has just given it.
+	// A stored consent that covers the request is reused silently
+	if !req.consented {
+		policy, err := s.evaluateConsent(ctx, userID, req.ClientID, requestedScope, req.Prompt)
+		if err != nil {
+			return "", err
+		}
+
+		if policy == ConsentPolicyTrustedClient {
+			// Trusted clients are not asked, but the grant is stored like any other,
+			// so the user sees it among their consents and can revoke it
+			if err := s.SaveConsent(ctx, userID, req.ClientID, requestedScope); err != nil {
+				return "", err
+			}
+			s.recordConsentDecision(ctx, userID, req.ClientID, policy, ConsentOutcomeGranted, requestedScope)
+		}
+
+		if policy.RequiresConsent() {
+			s.recordConsentDecision(ctx, userID, req.ClientID, policy, ConsentOutcomeRequired, requestedScope)
+
+			// Return indicator that consent is needed (to be handled by the handler),
+			// with the policy for the authorization session
+			return "", errors.New(http.StatusFound, errors.ErrMsgConsentRequired).WithDetails(policy)
+		}
+	}
+
+	// Generate authorization code
+	code, err := s.generateAuthorizationCode()
+	if err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToGenerateAuthCode)
+	}
+
+	// Save authorization code
+	authCode := &AuthorizationCode{
+		Code:                code,
+		ClientID:            req.ClientID,
+		UserID:              userID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               requestedScope,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		AuthTime:            authTime,
+		ExpiresAt:           time.Now().Add(10 * time.Minute),
+		CreatedAt:           time.Now(),
+		IsUsed:              false,
+	}
+
+	if err := s.oauthRepo.SaveAuthorizationCode(ctx, authCode); err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToSaveAuthCode)
+	}
+
+	// A pushed request is used up once a code has been issued for it
+	if req.RequestURI != "" {
+		if err := s.flowRepo.DeletePushedAuthorizationRequest(ctx, req.RequestURI); err != nil {
+			return "", err
+		}
+	}
+
+	return code, nil
+}
+
+// CreateAuthorizationSession stores a validated authorization request for the given user
+// while they are asked for consent. The policy is the one Authorize returned with its
+// consent_required error. The consent page refers to the returned session by its ID,
+// and the consent decision must echo its CSRF token.
+func (s *Service) CreateAuthorizationSession(ctx context.Context, req AuthorizeRequest, userID uint, authTime time.Time, policy ConsentPolicy) (*AuthorizationSession, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.CreateAuthorizationSession")
+	defer span.End()
+
+	scope, err := s.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.generateAuthorizationCode()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToCreateAuthorizationSession)
+	}
+	csrfToken, err := s.generateAuthorizationCode()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToCreateAuthorizationSession)
+	}
+
+	now := time.Now()
+	session := &AuthorizationSession{
+		ID:         id,
+		UserID:     userID,
+		Request:    req,
+		RequestURI: req.RequestURI,
+		Scope:      scope,
+		Policy:     policy,
+		CSRFToken:  csrfToken,
+		AuthTime:   authTime,
+		ExpiresAt:  now.Add(authorizationSessionExpiry),
+		CreatedAt:  now,
+	}
+	if err := s.flowRepo.SaveAuthorizationSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetConsentSessionData returns the consent page data for an authorization session
+// of the given user, including the CSRF token the decision must echo.
+func (s *Service) GetConsentSessionData(ctx context.Context, sessionID string, userID uint) (*ConsentPageData, error) {
+	session, err := s.findAuthorizationSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.GetConsentPageData(ctx, session.Request.ClientID, session.Scope)
+	if err != nil {
+		return nil, err
+	}
+	data.State = session.Request.State
+	data.SessionID = session.ID
+	data.CSRFToken = session.CSRFToken
+
+	return data, nil
+}
+
+// CompleteAuthorizationSession checks a consent decision against its authorization session
+// and removes the session, so each session can be decided once. The session must belong to the
+// user and the CSRF token must match. The returned session carries the original request to
+// replay, with the request URI of a pushed request restored; a denied pushed request is removed.
+// When the request is approved, the scope of the session is narrowed to the scopes the user
+// selected; it is empty if the user approved none of them. The decision is recorded in the
+// audit log, and an approved request is marked so that replaying it does not ask for consent again.
+func (s *Service) CompleteAuthorizationSession(ctx context.Context, decision ConsentDecisionRequest, userID uint) (*AuthorizationSession, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.CompleteAuthorizationSession")
+	defer span.End()
+
+	session, err := s.findAuthorizationSession(ctx, decision.SessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.CSRFToken), []byte(decision.CSRFToken)) != 1 {
+		return nil, errors.Forbidden(errors.ErrMsgInvalidCSRFToken)
+	}
+
+	// Check the selection before the session is used up, so the user can correct it
+	if decision.Consent {
+		approved, err := s.approvedScope(ctx, session, decision.Scope)
+		if err != nil {
+			return nil, err
+		}
+		session.Scope = approved
+	}
+
+	deleted, err := s.flowRepo.DeleteAuthorizationSession(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidAuthorizationSession)
+	}
+
+	outcome := ConsentOutcomeDenied
+	if decision.Consent && session.Scope != "" {
+		outcome = ConsentOutcomeApproved
+		session.Request.consented = true
+	}
+	s.recordConsentDecision(ctx, userID, session.Request.ClientID, session.Policy, outcome, session.Scope)
+
+	// A denied pushed request is used up as well; the client has to push a new one
+	if outcome == ConsentOutcomeDenied && session.RequestURI != "" {
+		if err := s.flowRepo.DeletePushedAuthorizationRequest(ctx, session.RequestURI); err != nil {
+			return nil, err
+		}
+	}
+
+	session.Request.RequestURI = session.RequestURI
+	return session, nil
+}
+
+// PushAuthorizationRequest validates and stores the authorization request of an authenticated
+// client (RFC 9126). The returned request URI replaces the request parameters at the
+// authorization endpoint until it expires or a code has been issued for it.
+func (s *Service) PushAuthorizationRequest(ctx context.Context, req AuthorizeRequest, client *client.Client) (*PushedAuthorizationResponse, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.PushAuthorizationRequest")
+	defer span.End()
+
+	// A pushed request cannot itself refer to another one
+	if req.RequestURI != "" {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+	if req.ClientID != client.ClientID {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+
+	if _, err := s.ValidateAuthorizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	id, err := s.generateAuthorizationCode()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToGenerateRequestURI)
+	}
+
+	now := time.Now()
+	pushed := &PushedAuthorizationRequest{
+		RequestURI: requestURIPrefix + id,
+		Request:    req,
+		ExpiresAt:  now.Add(s.parRequestExpiry),
+		CreatedAt:  now,
+	}
+	if err := s.flowRepo.SavePushedAuthorizationRequest(ctx, pushed); err != nil {
+		return nil, err
+	}
+
+	return &PushedAuthorizationResponse{
+		RequestURI: pushed.RequestURI,
+		ExpiresIn:  int(s.parRequestExpiry.Seconds()),
+	}, nil
+}
+
+// ResolveAuthorizeRequest returns the effective parameters of an authorization request.
+// A request carrying a request URI is replaced by the pushed request it refers to, which must
+// have been pushed by the same client. Requests with inline parameters are rejected for clients
+// that are required to push their authorization requests.
+func (s *Service) ResolveAuthorizeRequest(ctx context.Context, req AuthorizeRequest) (AuthorizeRequest, error) {
+	if req.RequestURI == "" {
+		client, err := s.clientService.GetByClientID(ctx, req.ClientID)
+		if err != nil {
+			return req, err
+		}
+		if client != nil && client.RequirePushedAuthorizationRequests {
+			return req, errors.BadRequest(errors.ErrMsgPushedAuthorizationRequired)
+		}
+		return req, nil
+	}
+
+	pushed, err := s.flowRepo.FindPushedAuthorizationRequest(ctx, req.RequestURI)
+	if err != nil {
+		return req, err
+	}
+	if pushed == nil || time.Now().After(pushed.ExpiresAt) || pushed.Request.ClientID != req.ClientID {
+		return req, errors.BadRequest(errors.ErrMsgInvalidRequestURI)
+	}
+
+	resolved := pushed.Request
+	resolved.RequestURI = pushed.RequestURI
+	resolved.pushedAt = pushed.CreatedAt
+	return resolved, nil
+}
+
+// RequiresLogin reports whether the user must log in before an authorization request
+// can proceed: without a login session, with prompt=login, or when the session is older
+// than max_age. A login after the request was stored server-side satisfies prompt=login
+// and max_age, so the user is asked only once.
+func (s *Service) RequiresLogin(req AuthorizeRequest, loginSession *session.Session) bool {
+	if loginSession == nil {
+		return true
+	}
+	if !req.pushedAt.IsZero() && loginSession.AuthTime.After(req.pushedAt) {
+		return false
+	}
+	if hasPrompt(req.Prompt, PromptLogin) {
+		return true
+	}
+	return req.MaxAge != nil && time.Since(loginSession.AuthTime) > time.Duration(*req.MaxAge)*time.Second
+}
+
+// SaveAuthorizeRequestForLogin keeps a validated authorization request on the server while
+// the user logs in, the same way as a pushed authorization request. The login page returns
+// to the authorization endpoint with the returned request URI, so the original parameters
+// come back intact. Requests that were pushed already keep their request URI.
+func (s *Service) SaveAuthorizeRequestForLogin(ctx context.Context, req AuthorizeRequest) (string, error) {
+	if req.RequestURI != "" {
+		return req.RequestURI, nil
+	}
+
+	id, err := s.generateAuthorizationCode()
+	if err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToGenerateRequestURI)
+	}
+
+	now := time.Now()
+	pushed := &PushedAuthorizationRequest{
+		RequestURI: requestURIPrefix + id,
+		Request:    req,
+		ExpiresAt:  now.Add(authorizationSessionExpiry),
+		CreatedAt:  now,
+	}
+	if err := s.flowRepo.SavePushedAuthorizationRequest(ctx, pushed); err != nil {
+		return "", err
+	}
+
+	return pushed.RequestURI, nil
+}
+
+// Login authenticates a user on the hosted login page and starts a browser login session.
+func (s *Service) Login(ctx context.Context, email, password, userAgent, ipAddress string) (*session.Session, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.Login")
+	defer span.End()
+
+	user, err := s.userService.Authenticate(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionService.Create(ctx, user.ID, userAgent, ipAddress)
+}
+
+// Logout ends a browser login session.
+func (s *Service) Logout(ctx context.Context, sessionID string) error {
+	return s.sessionService.Delete(ctx, sessionID)
+}
+
+func (s *Service) Token(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.Token",
+		attribute.String("oauth.grant_type", req.GrantType),
+		attribute.String("oauth.client_id", req.ClientID),
+	)
+	defer span.End()
+
+	var handle func(context.Context, TokenRequest) (*TokenResponse, error)
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		handle = s.handleAuthorizationCodeGrant
+	case GrantTypeRefreshToken:
+		handle = s.handleRefreshTokenGrant
+	case GrantTypeClientCredentials:
+		handle = s.handleClientCredentialsGrant
+	case GrantTypeDeviceCode:
+		handle = s.handleDeviceCodeGrant
+	case GrantTypeTokenExchange:
+		handle = s.handleTokenExchangeGrant
+	default:
+		return nil, errors.BadRequest(errors.ErrMsgUnsupportedGrantType)
+	}
+
+	// The client may only use the grant types it was registered for
+	client, err := s.clientService.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrantType(req.GrantType) {
+		return nil, errors.BadRequest(errors.ErrMsgUnauthorizedClient)
+	}
+
+	return handle(ctx, req)
+}
+
+// DeviceAuthorization starts a device authorization request (RFC 8628) for an authenticated client.
+// The returned device code is polled at the token endpoint while the user enters the user code
+// on the verification page, which is derived from the base path of the OAuth routes unless
+// a separate verification page is configured.
+func (s *Service) DeviceAuthorization(ctx context.Context, req DeviceAuthorizationRequest, client *client.Client, basePath string) (*DeviceAuthorizationResponse, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.DeviceAuthorization")
+	defer span.End()
+
+	if !client.AllowsGrantType(GrantTypeDeviceCode) {
+		return nil, errors.BadRequest(errors.ErrMsgUnauthorizedClient)
+	}
+
+	// Validate and normalize scope
+	requestedScope := req.Scope
+	if requestedScope == "" {
+		requestedScope = "profile" // Default scope
+	}
+
+	validScope, err := s.scopeService.ValidateScope(ctx, requestedScope, client.Scope)
+	if err != nil || !validScope {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidScope)
+	}
+
+	deviceCode, err := s.generateAuthorizationCode()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToGenerateDeviceCode)
+	}
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToGenerateDeviceCode)
+	}
+
+	now := time.Now()
+	authorization := &DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   client.ClientID,
+		Scope:      requestedScope,
+		Status:     DeviceStatusPending,
+		Interval:   int(s.devicePollInterval.Seconds()),
+		ExpiresAt:  now.Add(s.deviceCodeExpiry),
+		CreatedAt:  now,
+	}
+
+	if err := s.flowRepo.SaveDeviceAuthorization(ctx, authorization); err != nil {
+		return nil, err
+	}
+
+	verificationURI := config.AppConfig.DeviceVerificationURI
+	if verificationURI == "" {
+		verificationURI = config.AppConfig.OIDCIssuer + basePath + pathDevice
+	}
+	displayCode := formatUserCode(userCode)
+
+	return &DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                displayCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(displayCode),
+		ExpiresIn:               int(s.deviceCodeExpiry.Seconds()),
+		Interval:                authorization.Interval,
+	}, nil
+}
+
+// GetDeviceVerificationData returns the consent information for a pending device authorization,
+// identified by the user code the user entered, including the CSRF token the decision must echo.
+func (s *Service) GetDeviceVerificationData(ctx context.Context, userCode string, userID uint) (*DeviceVerificationData, error) {
+	authorization, err := s.findPendingDeviceAuthorization(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.GetConsentPageData(ctx, authorization.ClientID, authorization.Scope)
+	if err != nil {
+		return nil, err
+	}
+	data.CSRFToken, err = s.issueDeviceVerificationCSRFToken(ctx, authorization, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeviceVerificationData{
+		ConsentPageData: *data,
+		UserCode:        formatUserCode(authorization.UserCode),
+	}, nil
+}
+
+// HandleDeviceVerification records the user's decision for a pending device authorization.
+// Approving saves the user's consent like the authorization code flow does, after which the
+// device receives tokens on its next poll; denying makes the next poll fail with access_denied.
+// The CSRF token must be the one issued to the user with the verification page. The consent
+// is only saved once the decision has been recorded, so a request that was already decided on
+// or has expired leaves no consent behind.
+func (s *Service) HandleDeviceVerification(ctx context.Context, req DeviceVerificationRequest, userID uint, authTime time.Time) error {
+	ctx, span := tracing.Start(ctx, "oauth.Service.HandleDeviceVerification")
+	defer span.End()
+
+	authorization, err := s.findPendingDeviceAuthorization(ctx, req.UserCode)
+	if err != nil {
+		return err
+	}
+	if !validDeviceVerificationCSRFToken(authorization, userID, req.CSRFToken) {
+		return errors.Forbidden(errors.ErrMsgInvalidCSRFToken)
+	}
+
+	status := DeviceStatusDenied
+	outcome := ConsentOutcomeDenied
+	if req.Consent {
+		status = DeviceStatusApproved
+		outcome = ConsentOutcomeApproved
+	}
+
+	// Only a pending authorization may be decided on; it may have been decided on
+	// in another request or expired since it was looked up
+	csrfValid := true
+	updated, err := s.flowRepo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *DeviceAuthorization) bool {
+		if current.Status != DeviceStatusPending || time.Now().After(current.ExpiresAt) {
+			return false
+		}
+		// The page may have been shown to another user since the token was checked
+		if !validDeviceVerificationCSRFToken(current, userID, req.CSRFToken) {
+			csrfValid = false
+			return false
+		}
+		current.UserID = userID
+		current.Status = status
+		if status == DeviceStatusApproved {
+			current.AuthTime = authTime
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if !csrfValid {
+		return errors.Forbidden(errors.ErrMsgInvalidCSRFToken)
+	}
+	if !updated {
+		return errors.BadRequest(errors.ErrMsgDeviceAuthorizationAlreadyHandled)
+	}
+
+	if status == DeviceStatusApproved {
+		if err := s.SaveConsent(ctx, userID, authorization.ClientID, authorization.Scope); err != nil {
+			return err
+		}
+	}
+
+	s.recordConsentDecision(ctx, userID, authorization.ClientID, ConsentPolicyDeviceVerification, outcome, authorization.Scope)
+	return nil
+}
+
+func (s *Service) Revoke(ctx context.Context, req RevokeRequest, clientID string) error {
+	ctx, span := tracing.Start(ctx, "oauth.Service.Revoke")
+	defer span.End()
+
+	if req.TokenTypeHint == "access_token" || req.TokenTypeHint == "" {
+		err := s.tokenService.RevokeAccessToken(ctx, req.Token, clientID)
+		if err == nil {
+			return nil
+		}
+	}
+
+	if req.TokenTypeHint == "refresh_token" || req.TokenTypeHint == "" {
+		err := s.tokenService.RevokeRefreshToken(ctx, req.Token, clientID)
+		if err == nil {
+			return nil
+		}
+	}
+
+	// RFC 7009: Return success even if token was not found
+	return nil
+}
+
+func (s *Service) GetUserInfo(ctx context.Context, userID uint) (*UserInfoResponse, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.GetUserInfo")
+	defer span.End()
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserInfoResponse{
+		Sub:               strconv.FormatUint(uint64(user.ID), 10),
+		Name:              user.Username,
+		Email:             user.Email,
+		EmailVerified:     user.IsVerified,
+		PreferredUsername: user.Username,
+	}, nil
+}
+
+func (s *Service) SaveConsent(ctx context.Context, userID uint, clientID, scope string) error {
+	ctx, span := tracing.Start(ctx, "oauth.Service.SaveConsent")
+	defer span.End()
+
+	consent, _ := s.oauthRepo.FindUserConsent(ctx, userID, clientID)
+
+	if consent != nil {
+		consent.Scope = scope
+		consent.UpdatedAt = time.Now()
+		return s.oauthRepo.UpdateUserConsent(ctx, consent)
+	}
+
+	consent = &UserConsent{
+		UserID:    userID,
+		ClientID:  clientID,
+		Scope:     scope,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	return s.oauthRepo.SaveUserConsent(ctx, consent)
+}
+
+// ListUserConsents returns the applications a user has authorized, with the granted scopes
+// and the client details shown on the consent page.
+func (s *Service) ListUserConsents(ctx context.Context, userID uint) (*ConsentListResponse, error) {
+	consents, err := s.oauthRepo.FindUserConsentsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ConsentListResponse{Consents: make([]ConsentInfo, 0, len(consents))}
+	for _, consent := range consents {
+		info := ConsentInfo{
+			ClientID:       consent.ClientID,
+			Scope:          consent.Scope,
+			FirstGrantedAt: consent.CreatedAt,
+			LastGrantedAt:  consent.UpdatedAt,
+		}
+
+		// Consents of deleted clients are still listed, so the user can remove them
+		client, err := s.clientService.GetByClientID(ctx, consent.ClientID)
+		if err != nil {
+			if customErr, ok := err.(errors.CustomError); !ok || customErr.Status != http.StatusNotFound {
+				return nil, err
+			}
+		} else {
+			info.ClientName = client.ClientName
+			info.ClientURI = client.ClientURI
+			info.LogoURI = client.LogoURI
+		}
+		if lifetime := s.consentLifetimeFor(client); lifetime > 0 {
+			expiresAt := consent.UpdatedAt.Add(lifetime)
+			info.ExpiresAt = &expiresAt
+		}
+
+		response.Consents = append(response.Consents, info)
+	}
+
+	return response, nil
+}
+
+// RevokeUserConsent disconnects an application from the user's account: every access and
+// refresh token the client holds for the user is revoked and the consent is removed, so the
+// user is asked again the next time the client requests authorization.
+// Tokens are revoked first, so a failure leaves the consent in place for another attempt.
+func (s *Service) RevokeUserConsent(ctx context.Context, userID uint, clientID string) error {
+	ctx, span := tracing.Start(ctx, "oauth.Service.RevokeUserConsent")
+	defer span.End()
+
+	consent, err := s.oauthRepo.FindUserConsent(ctx, userID, clientID)
+	if err != nil {
+		return err
+	}
+	if consent == nil {
+		return errors.NotFound(errors.ErrMsgConsentNotFound)
+	}
+
+	if err := s.tokenService.RevokeUserClientTokens(ctx, userID, clientID); err != nil {
+		return err
+	}
+
+	return s.oauthRepo.DeleteUserConsent(ctx, userID, clientID)
+}
+
+// PurgeExpiredCodes deletes expired authorization codes, including used ones.
+// Used codes are kept until they expire so that their replay can be detected.
+// Returns the number of codes deleted.
+func (s *Service) PurgeExpiredCodes(ctx context.Context) (int64, error) {
+	return s.oauthRepo.DeleteExpiredCodes(ctx, time.Now())
+}
+
+func (s *Service) GetConsentPageData(ctx context.Context, clientID, scope string) (*ConsentPageData, error) {
+	client, err := s.clientService.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+
+	scopes := strings.Split(scope, " ")
+
+	descriptions := make([]ScopeDescription, 0, len(scopes))
+	for _, name := range scopes {
+		description := ScopeDescription{Name: name, Required: client.RequiresScope(name)}
+		registered, err := s.scopeService.FindScopeByName(ctx, name)
+		if err != nil {
+			// Scopes without a registration are shown by name
+			if customErr, ok := err.(errors.CustomError); !ok || customErr.Status != http.StatusNotFound {
+				return nil, err
+			}
+		} else {
+			description.Description = registered.Description
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	return &ConsentPageData{
+		ClientName:     client.ClientName,
+		ClientID:       clientID,
+		ClientURI:      client.ClientURI,
+		LogoURI:        client.LogoURI,
+		TOSURI:         client.TOSUri,
+		PolicyURI:      client.PolicyURI,
+		RequestedScope: scope,
+		ScopeList:      scopes,
+		Scopes:         descriptions,
+	}, nil
+}
+
+// GetDiscoveryDocument builds the authorization server metadata document.
+// Endpoint URLs are derived from the issuer and the base path the OAuth routes
+// are mounted on, so they always match the routes registered by the handler.
+// The supported scopes are read from the scopes registered in the database.
+func (s *Service) GetDiscoveryDocument(ctx context.Context, basePath string) (*DiscoveryResponse, error) {
+	scopes, err := s.scopeService.GetAllScopes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scopeNames := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		scopeNames = append(scopeNames, sc.Name)
+	}
+
+	// client_secret_jwt is not advertised, as client secrets are only stored as hashes
+	clientAuthMethods := []string{client.AuthMethodClientSecretBasic, client.AuthMethodClientSecretPost, client.AuthMethodPrivateKeyJWT, client.AuthMethodNone}
+
+	endpoint := func(path string) string {
+		return config.AppConfig.OIDCIssuer + basePath + path
+	}
+
+	return &DiscoveryResponse{
+		Issuer:                                     config.AppConfig.OIDCIssuer,
+		AuthorizationEndpoint:                      endpoint(pathAuthorize),
+		TokenEndpoint:                              endpoint(pathToken),
+		UserInfoEndpoint:                           endpoint(pathUserInfo),
+		JwksURI:                                    endpoint(pathJWKS),
+		RevocationEndpoint:                         endpoint(pathRevoke),
+		DeviceAuthorizationEndpoint:                endpoint(pathDeviceAuthorization),
+		IntrospectionEndpoint:                      endpoint(pathIntrospect),
+		PushedAuthorizationRequestEndpoint:         endpoint(pathPushedAuthorization),
+		RequirePushedAuthorizationRequests:         false,
+		ScopesSupported:                            scopeNames,
+		ResponseTypesSupported:                     []string{ResponseTypeCode},
+		ResponseModesSupported:                     []string{"query"},
+		GrantTypesSupported:                        []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials, GrantTypeDeviceCode, GrantTypeTokenExchange},
+		SubjectTypesSupported:                      []string{"public"},
+		IDTokenSigningAlgValuesSupported:           jwtutil.SigningAlgorithms(),
+		TokenEndpointAuthMethodsSupported:          clientAuthMethods,
+		TokenEndpointAuthSigningAlgValuesSupported: []string{jwtutil.AlgorithmRS256, jwtutil.AlgorithmES256, jwtutil.AlgorithmEdDSA},
+		RevocationEndpointAuthMethodsSupported:     clientAuthMethods,
+		IntrospectionEndpointAuthMethodsSupported:  clientAuthMethods,
+		CodeChallengeMethodsSupported:              []string{"plain", "S256"},
+		ClaimsSupported: []string{
+			jwtutil.ClaimKeyISS, jwtutil.ClaimKeySub, jwtutil.ClaimKeyAud, jwtutil.ClaimKeyEXP,
+			jwtutil.ClaimKeyIAT, jwtutil.ClaimKeyAuthTime, jwtutil.ClaimKeyNonce, jwtutil.ClaimKeyAtHash,
+			jwtutil.ClaimKeyName, jwtutil.ClaimKeyPreferredUsername, jwtutil.ClaimKeyPicture,
+			jwtutil.ClaimKeyEmail, jwtutil.ClaimKeyEmailVerified,
+		},
+	}, nil
+}
+
+// GetJWKS returns the public JSON Web Key Set used to verify tokens issued by this server.
+func (s *Service) GetJWKS() jwtutil.JWKS {
+	return jwtutil.PublicJWKS()
+}
+
+// Private helper methods
+
+func (s *Service) handleAuthorizationCodeGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	// Validate required parameters
+	if req.Code == "" || req.RedirectURI == "" {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+
+	// Get and validate authorization code
+	authCode, err := s.oauthRepo.FindAuthorizationCode(ctx, req.Code)
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToGetAuthCode)
+	}
+	if authCode == nil {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	// Validate code hasn't been used
+	if authCode.IsUsed {
+		// Security: revoke all tokens associated with this code
+		s.tokenService.RevokeTokensByAuthCode(ctx, req.Code)
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	// Validate code hasn't expired
+	if time.Now().After(authCode.ExpiresAt) {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	// Validate client
+	if authCode.ClientID != req.ClientID {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	// Validate redirect URI
+	if authCode.RedirectURI != req.RedirectURI {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	// Validate PKCE if used
+	if authCode.CodeChallenge != "" {
+		if req.CodeVerifier == "" {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+		}
+
+		if !pkce.VerifyCodeChallenge(req.CodeVerifier, authCode.CodeChallenge, authCode.CodeChallengeMethod) {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+		}
+	}
+
+	// Mark code as used
+	if err := s.oauthRepo.MarkCodeAsUsed(ctx, req.Code); err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToMarkCodeAsUsed)
+	}
+
+	// Generate tokens
+	tokenResp, err := s.tokenService.CreateTokens(ctx, authCode.UserID, authCode.ClientID, authCode.Scope, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue an ID token when the openid scope was granted
+	var idToken string
+	if hasScope(authCode.Scope, scope.ScopeOpenID) {
+		idToken, err = s.createIDToken(ctx, idTokenParams{
+			UserID:   authCode.UserID,
+			ClientID: authCode.ClientID,
+			Scope:    authCode.Scope,
+			Nonce:    authCode.Nonce,
+			AuthTime: authCode.AuthTime,
+		}, tokenResp.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Convert token.TokenCreateResponse to TokenResponse
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		TokenType:    tokenResp.TokenType,
+		ExpiresIn:    tokenResp.ExpiresIn,
+		RefreshToken: tokenResp.RefreshToken,
+		Scope:        tokenResp.Scope,
+		IDToken:      idToken,
+	}, nil
+}
+
+// idTokenParams describes the user authentication an ID token is issued for.
+type idTokenParams struct {
+	UserID   uint      // Authenticated user
+	ClientID string    // Client the ID token is issued to
+	Scope    string    // Granted scopes, which select the profile and email claims
+	Nonce    string    // Nonce from the authorization request, if any
+	AuthTime time.Time // When the user authenticated; zero if unknown
+}
+
+// createIDToken builds and signs an OpenID Connect ID token for an authorization granted by a user.
+// Profile and email claims are included only when the corresponding scopes were granted.
+func (s *Service) createIDToken(ctx context.Context, params idTokenParams, accessToken string) (string, error) {
+	user, err := s.userService.GetByID(ctx, params.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.clientService.GetByClientID(ctx, params.ClientID)
+	if err != nil {
+		return "", err
+	}
+
+	// Clients that registered no preference receive ID tokens signed with the default algorithm
+	algorithm := client.IDTokenSignedResponseAlg
+	if algorithm == "" {
+		algorithm = jwtutil.DefaultAlgorithm()
+	}
+
+	claims := jwt.MapClaims{
+		jwtutil.ClaimKeyAtHash: jwtutil.AccessTokenHash(accessToken, algorithm),
+	}
+	// auth_time is left out rather than guessed when the login time is not known
+	if !params.AuthTime.IsZero() {
+		claims[jwtutil.ClaimKeyAuthTime] = params.AuthTime.Unix()
+	}
+	if params.Nonce != "" {
+		claims[jwtutil.ClaimKeyNonce] = params.Nonce
+	}
+
+	if hasScope(params.Scope, scope.ScopeProfile) {
+		claims[jwtutil.ClaimKeyPreferredUsername] = user.Username
+		claims[jwtutil.ClaimKeyName] = user.Username
+		if user.FullName != nil && *user.FullName != "" {
+			claims[jwtutil.ClaimKeyName] = *user.FullName
+		}
+		if user.ProfilePictureURL != nil && *user.ProfilePictureURL != "" {
+			claims[jwtutil.ClaimKeyPicture] = *user.ProfilePictureURL
+		}
+	}
+
+	if hasScope(params.Scope, scope.ScopeEmail) {
+		claims[jwtutil.ClaimKeyEmail] = user.Email
+		claims[jwtutil.ClaimKeyEmailVerified] = user.IsVerified
+	}
+
+	subject := strconv.FormatUint(uint64(user.ID), 10)
+	return s.tokenService.CreateIDToken(subject, params.ClientID, algorithm, claims)
+}
+
+func (s *Service) handleRefreshTokenGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+
+	tokenResp, err := s.tokenService.RefreshTokens(ctx, req.RefreshToken, req.ClientID, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		TokenType:    tokenResp.TokenType,
+		ExpiresIn:    tokenResp.ExpiresIn,
+		RefreshToken: tokenResp.RefreshToken,
+		Scope:        tokenResp.Scope,
+	}, nil
+}
+
+// evaluateConsent decides whether the user must be asked to consent to a request and
+// returns the policy that applied. prompt=consent always asks, even for trusted clients,
+// since the client asked for it explicitly. Otherwise a stored consent is reused while it has
+// not expired and covers every requested scope, and trusted first-party clients never ask.
+func (s *Service) evaluateConsent(ctx context.Context, userID uint, clientID, scope, prompt string) (ConsentPolicy, error) {
+	if hasPrompt(prompt, PromptConsent) {
+		return ConsentPolicyPromptConsent, nil
+	}
+
+	client, err := s.clientService.GetByClientID(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	consent, err := s.oauthRepo.FindUserConsent(ctx, userID, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	policy := ConsentPolicyExistingConsent
+	if consent == nil {
+		policy = ConsentPolicyNoConsent
+	} else if lifetime := s.consentLifetimeFor(client); lifetime > 0 && time.Since(consent.UpdatedAt) > lifetime {
+		policy = ConsentPolicyConsentExpired
+	} else {
+		// Check if requested scope is within already consented scope
+		for _, requested := range strings.Fields(scope) {
+			if !hasScope(consent.Scope, requested) {
+				policy = ConsentPolicyScopeNotGranted
+				break
+			}
+		}
+	}
+
+	if policy != ConsentPolicyExistingConsent && client.IsTrusted {
+		return ConsentPolicyTrustedClient, nil
+	}
+	return policy, nil
+}
+
+// consentLifetimeFor returns how long a consent to the client stays valid: the client's own
+// lifetime if it sets one, the server default otherwise. Zero means consents do not expire.
+// A nil client, for consents of deleted clients, gets the server default.
+func (s *Service) consentLifetimeFor(client *client.Client) time.Duration {
+	if client != nil && client.ConsentLifetime != nil {
+		return time.Duration(*client.ConsentLifetime) * time.Second
+	}
+	return s.consentLifetime
+}
+
+// recordConsentDecision writes a consent decision and the policy behind it to the audit log.
+// Authorizations that reuse a stored consent are not decisions and are not recorded.
+func (s *Service) recordConsentDecision(ctx context.Context, userID uint, clientID string, policy ConsentPolicy, outcome, scope string) {
+	actorID := userID
+	entry := &audit.Log{
+		ActorID:      &actorID,
+		ActorType:    audit.ActorTypeUser,
+		Action:       auditActionConsentDecision,
+		ResourceType: "client",
+		ResourceID:   clientID,
+		Status:       outcome,
+		AdditionalData: map[string]interface{}{
+			"policy": string(policy),
+			"scope":  scope,
+		},
+	}
+	if err := s.auditService.Record(ctx, entry); err != nil {
+		// Not critical, continue
+	}
+	metrics.ConsentDecision(outcome)
+}
+
+// hasScope reports whether a space-separated scope string contains the given scope.
+func hasScope(scopeString, name string) bool {
+	for _, sc := range strings.Fields(scopeString) {
+		if sc == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validPrompt reports whether a prompt parameter consists of known values only,
+// with none standing alone.
+func validPrompt(prompt string) bool {
+	values := strings.Fields(prompt)
+	for _, value := range values {
+		switch value {
+		case PromptNone:
+			if len(values) > 1 {
+				return false
+			}
+		case PromptLogin, PromptConsent, PromptSelectAccount:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasPrompt reports whether a space-separated prompt parameter contains the given value.
+func hasPrompt(prompt, value string) bool {
+	for _, p := range strings.Fields(prompt) {
+		if p == value {
+			return true
+		}
+	}
+	return false
+}
+
+// approvedScope returns the scopes of an authorization session that the user selected,
+// in the order they were requested. A nil selection approves all of them; empty entries,
+// which the HTML consent form sends so that an empty selection is not taken for none,
+// are ignored. The selection may not add scopes or leave out scopes the client requires.
+func (s *Service) approvedScope(ctx context.Context, session *AuthorizationSession, selection []string) (string, error) {
+	if selection == nil {
+		return session.Scope, nil
+	}
+
+	client, err := s.clientService.GetByClientID(ctx, session.Request.ClientID)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		return "", errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range selection {
+		if name == "" {
+			continue
+		}
+		if !hasScope(session.Scope, name) {
+			return "", errors.BadRequest(errors.ErrMsgInvalidScope)
+		}
+		selected[name] = true
+	}
+
+	var approved []string
+	for _, name := range strings.Fields(session.Scope) {
+		if selected[name] {
+			approved = append(approved, name)
+		} else if client.RequiresScope(name) {
+			return "", errors.BadRequest(fmt.Sprintf(errors.ErrMsgRequiredScopeDeclined, name))
+		}
+	}
+
+	return strings.Join(approved, " "), nil
+}
+
+// findAuthorizationSession looks up an unexpired authorization session of the given user.
+// Sessions of other users are reported as not found.
+func (s *Service) findAuthorizationSession(ctx context.Context, sessionID string, userID uint) (*AuthorizationSession, error) {
+	session, err := s.flowRepo.FindAuthorizationSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.UserID != userID || time.Now().After(session.ExpiresAt) {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidAuthorizationSession)
 	}
+	return session, nil
 }
 
-func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, userID uint) (string, error) {
+// ValidateAuthorizeRequest checks an authorization request against the registration of its
+// client: response type, redirect URI, PKCE parameters, prompt, max_age and scope.
+// Returns the requested scope, defaulting to "profile" when none was given.
+func (s *Service) ValidateAuthorizeRequest(ctx context.Context, req AuthorizeRequest) (string, error) {
 	// Validate response type
-	if req.ResponseType != "code" {
+	if req.ResponseType != ResponseTypeCode {
 		return "", errors.BadRequest(errors.ErrMsgUnsupportedResponseType)
 	}
 
@@ -58,6 +1225,9 @@ func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, userID ui
 	if client == nil || !client.IsActive {
 		return "", errors.BadRequest(errors.ErrMsgInvalidClient)
 	}
+	if !client.AllowsResponseType(req.ResponseType) {
+		return "", errors.BadRequest(errors.ErrMsgUnauthorizedClient)
+	}
 
 	// Validate PKCE requirements
 	if client.PKCERequired && req.CodeChallenge == "" {
@@ -81,6 +1251,11 @@ func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, userID ui
 		return "", errors.BadRequest(errors.ErrMsgInvalidCodeChallengeMethod)
 	}
 
+	// Validate re-authentication parameters; none cannot be combined with other prompts
+	if !validPrompt(req.Prompt) || (req.MaxAge != nil && *req.MaxAge < 0) {
+		return "", errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+
 	// Validate and normalize scope
 	requestedScope := req.Scope
 	if requestedScope == "" {
@@ -92,255 +1267,410 @@ func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, userID ui
 		return "", errors.BadRequest(errors.ErrMsgInvalidScope)
 	}
 
-	// Check if consent is needed
-	if s.needsConsent(ctx, userID, req.ClientID, requestedScope) {
-		// Return indicator that consent is needed (to be handled by the handler)
-		return "", errors.New(302, "consent_required")
+	return requestedScope, nil
+}
+
+func (s *Service) generateAuthorizationCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
 
-	// Generate authorization code
-	code, err := s.generateAuthorizationCode()
-	if err != nil {
-		return "", errors.Internal(errors.ErrMsgFailedToGenerateAuthCode)
+// Additional methods for client validation
+func (s *Service) ValidateClient(ctx context.Context, clientID, clientSecret string) (*client.Client, error) {
+	return s.clientService.ValidateClient(ctx, clientID, clientSecret)
+}
+
+// AuthenticateClient authenticates a client by its credentials.
+// The authentication method used by the request must be the token_endpoint_auth_method
+// the client was registered with. Confidential clients must present a matching secret
+// or a valid client assertion; public clients authenticate with their client ID only.
+// The base path of the OAuth routes is used to derive the token endpoint URL that
+// client assertions may be addressed to.
+func (s *Service) AuthenticateClient(ctx context.Context, credentials ClientCredentials, basePath string) (*client.Client, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.AuthenticateClient")
+	defer span.End()
+
+	usesAssertion := credentials.AuthMethod == client.AuthMethodPrivateKeyJWT
+
+	clientID := credentials.ClientID
+	if clientID == "" && usesAssertion {
+		clientID = assertionSubject(credentials.ClientAssertion)
 	}
 
-	// Save authorization code
-	authCode := &AuthorizationCode{
-		Code:                code,
-		ClientID:            req.ClientID,
-		UserID:              userID,
-		RedirectURI:         req.RedirectURI,
-		Scope:               requestedScope,
-		CodeChallenge:       req.CodeChallenge,
-		CodeChallengeMethod: req.CodeChallengeMethod,
-		ExpiresAt:           time.Now().Add(10 * time.Minute),
-		CreatedAt:           time.Now(),
-		IsUsed:              false,
+	client, err := s.clientService.GetByClientID(ctx, clientID)
+	if err != nil || client == nil || !client.IsActive {
+		return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+	if client.TokenEndpointAuthMethod != credentials.AuthMethod {
+		return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
 	}
 
-	if err := s.oauthRepo.SaveAuthorizationCode(ctx, authCode); err != nil {
-		return "", errors.Internal(errors.ErrMsgFailedToSaveAuthCode)
+	switch {
+	case usesAssertion:
+		if err := s.verifyClientAssertion(ctx, client, credentials.ClientAssertion, basePath); err != nil {
+			return nil, err
+		}
+	case client.IsConfidential:
+		if _, err := s.ValidateClient(ctx, clientID, credentials.ClientSecret); err != nil {
+			return nil, errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+		}
 	}
 
-	return code, nil
+	return client, nil
 }
 
-func (s *Service) Token(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
-	switch req.GrantType {
-	case "authorization_code":
-		return s.handleAuthorizationCodeGrant(ctx, req)
-	case "refresh_token":
-		return s.handleRefreshTokenGrant(ctx, req)
-	default:
-		return nil, errors.BadRequest(errors.ErrMsgUnsupportedGrantType)
+// verifyClientAssertion authenticates a client by a JWT assertion signed with one of its
+// registered keys (private_key_jwt, RFC 7523, section 3). The issuer and subject must be the
+// client ID and the audience the issuer or token endpoint of this server. Each assertion can be
+// used once: its token ID is remembered until it expires, which must be within
+// maxClientAssertionLifetime.
+func (s *Service) verifyClientAssertion(ctx context.Context, client *client.Client, assertion, basePath string) error {
+	claims, err := s.clientService.VerifyAssertion(ctx, client, assertion)
+	if err != nil {
+		return err
 	}
-}
 
-func (s *Service) Revoke(ctx context.Context, req RevokeRequest, clientID string) error {
-	if req.TokenTypeHint == "access_token" || req.TokenTypeHint == "" {
-		err := s.tokenService.RevokeAccessToken(ctx, req.Token, clientID)
-		if err == nil {
-			return nil
-		}
+	issuer, _ := claims[jwtutil.ClaimKeyISS].(string)
+	subject, _ := claims[jwtutil.ClaimKeySub].(string)
+	if issuer != client.ClientID || subject != client.ClientID {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
 	}
 
-	if req.TokenTypeHint == "refresh_token" || req.TokenTypeHint == "" {
-		err := s.tokenService.RevokeRefreshToken(ctx, req.Token, clientID)
-		if err == nil {
-			return nil
-		}
+	tokenEndpoint := config.AppConfig.OIDCIssuer + basePath + pathToken
+	if !claims.VerifyAudience(config.AppConfig.OIDCIssuer, true) && !claims.VerifyAudience(tokenEndpoint, true) {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
 	}
 
-	// RFC 7009: Return success even if token was not found
-	return nil
-}
+	exp, ok := claims[jwtutil.ClaimKeyEXP].(float64)
+	if !ok {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+	expiresAt := time.Unix(int64(exp), 0)
+	if time.Until(expiresAt) > maxClientAssertionLifetime {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
 
-func (s *Service) GetUserInfo(ctx context.Context, userID uint) (*UserInfoResponse, error) {
-	user, err := s.userService.GetByID(ctx, userID)
+	jti, _ := claims[jwtutil.ClaimKeyJTI].(string)
+	if jti == "" {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
+	}
+	firstUse, err := s.flowRepo.RecordClientAssertion(ctx, client.ClientID, jti, expiresAt)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if !firstUse {
+		return errors.Unauthorized(errors.ErrMsgInvalidClientCredentials)
 	}
-	return &UserInfoResponse{
-		Sub:               strconv.FormatUint(uint64(user.ID), 10),
-		Name:              user.Username,
-		Email:             user.Email,
-		EmailVerified:     user.IsVerified,
-		PreferredUsername: user.Username,
-	}, nil
-}
 
-func (s *Service) SaveConsent(ctx context.Context, userID uint, clientID, scope string) error {
-	consent, _ := s.oauthRepo.FindUserConsent(ctx, userID, clientID)
+	return nil
+}
 
-	if consent != nil {
-		consent.Scope = scope
-		consent.UpdatedAt = time.Now()
-		return s.oauthRepo.UpdateUserConsent(ctx, consent)
+// assertionSubject returns the unverified subject of a client assertion, which identifies
+// the client when the request carries no client_id. The assertion is verified afterwards
+// against the keys of that client.
+func assertionSubject(assertion string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(assertion, claims); err != nil {
+		return ""
 	}
+	subject, _ := claims[jwtutil.ClaimKeySub].(string)
+	return subject
+}
 
-	consent = &UserConsent{
-		UserID:    userID,
-		ClientID:  clientID,
-		Scope:     scope,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+// Introspect returns the state of the token in the request on behalf of an authenticated client.
+func (s *Service) Introspect(ctx context.Context, req IntrospectRequest, client *client.Client) (*token.IntrospectionResponse, error) {
+	ctx, span := tracing.Start(ctx, "oauth.Service.Introspect")
+	defer span.End()
 
-	return s.oauthRepo.SaveUserConsent(ctx, consent)
+	return s.tokenService.Introspect(ctx, req.Token, req.TokenTypeHint, client.ClientID, client.IsConfidential)
 }
 
-func (s *Service) GetConsentPageData(ctx context.Context, clientID, scope string) (*ConsentPageData, error) {
+func (s *Service) IsPublicClient(ctx context.Context, clientID string) (bool, error) {
 	client, err := s.clientService.GetByClientID(ctx, clientID)
+	if err != nil {
+		return false, err
+	}
+	if client == nil {
+		return false, errors.NotFound(errors.ErrMsgClientNotFound)
+	}
+	return !client.IsConfidential, nil
+}
+
+// handleClientCredentialsGrant issues an access token to a confidential client acting on
+// its own behalf. The requested scope (all of the client's scopes when omitted) must be
+// allowed for the client.
+func (s *Service) handleClientCredentialsGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	client, err := s.clientService.GetByClientID(ctx, req.ClientID)
 	if err != nil {
 		return nil, err
 	}
+	if client == nil || !client.IsActive {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+	if !client.IsConfidential {
+		return nil, errors.BadRequest(errors.ErrMsgUnauthorizedClient)
+	}
 
-	scopes := strings.Split(scope, " ")
+	requestedScope := req.Scope
+	if requestedScope == "" {
+		requestedScope = client.Scope
+	}
 
-	return &ConsentPageData{
-		ClientName:     client.ClientName,
-		ClientID:       clientID,
-		RequestedScope: scope,
-		ScopeList:      scopes,
+	validScope, err := s.scopeService.ValidateScope(ctx, requestedScope, client.Scope)
+	if err != nil || !validScope {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidScope)
+	}
+
+	tokenResp, err := s.tokenService.CreateClientToken(ctx, client.ClientID, requestedScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: tokenResp.AccessToken,
+		TokenType:   tokenResp.TokenType,
+		ExpiresIn:   tokenResp.ExpiresIn,
+		Scope:       tokenResp.Scope,
 	}, nil
 }
 
-// Private helper methods
-
-func (s *Service) handleAuthorizationCodeGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
-	// Validate required parameters
-	if req.Code == "" || req.RedirectURI == "" {
+// handleTokenExchangeGrant exchanges a user's access token for a new access token (RFC 8693).
+// Clients without an exchange policy cannot exchange tokens. The requested audience must be
+// an active client listed in the exchange policy of the requesting client; the policy also
+// decides whether the exchange is a delegation, recorded in the act claim, or an impersonation.
+// Only access tokens can be exchanged or issued.
+func (s *Service) handleTokenExchangeGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	if req.SubjectToken == "" || req.SubjectTokenType != token.TokenTypeURIAccessToken {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+	if (req.ActorToken == "") != (req.ActorTokenType == "") {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+	if req.ActorToken != "" && req.ActorTokenType != token.TokenTypeURIAccessToken {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != token.TokenTypeURIAccessToken {
 		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
 	}
 
-	// Get and validate authorization code
-	authCode, err := s.oauthRepo.FindAuthorizationCode(ctx, req.Code)
+	client, err := s.clientService.GetByClientID(ctx, req.ClientID)
 	if err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToGetAuthCode)
+		return nil, err
 	}
-	if authCode == nil {
-		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	if client == nil || !client.IsActive {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
 	}
 
-	// Validate code hasn't been used
-	if authCode.IsUsed {
-		// Security: revoke all tokens associated with this code
-		s.tokenService.RevokeTokensByAuthCode(ctx, req.Code)
-		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	policy := client.TokenExchangePolicy
+	if !policy.AllowsExchange() {
+		return nil, errors.BadRequest(errors.ErrMsgUnauthorizedClient)
+	}
+	if policy.AllowImpersonation && req.ActorToken != "" {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
 	}
 
-	// Validate code hasn't expired
-	if time.Now().After(authCode.ExpiresAt) {
-		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	audience := req.Audience
+	if audience == "" {
+		audience = client.ClientID
+	} else if audience != client.ClientID {
+		if !policy.AllowsAudience(audience) {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidTarget)
+		}
+		target, err := s.clientService.GetByClientID(ctx, audience)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil || !target.IsActive {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidTarget)
+		}
 	}
 
-	// Validate client
-	if authCode.ClientID != req.ClientID {
-		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	tokenResp, err := s.tokenService.ExchangeToken(ctx, token.ExchangeRequest{
+		SubjectToken:  req.SubjectToken,
+		ActorToken:    req.ActorToken,
+		ClientID:      client.ClientID,
+		Audience:      audience,
+		Scope:         req.Scope,
+		Impersonation: policy.AllowImpersonation,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate redirect URI
-	if authCode.RedirectURI != req.RedirectURI {
+	return &TokenResponse{
+		AccessToken:     tokenResp.AccessToken,
+		TokenType:       tokenResp.TokenType,
+		ExpiresIn:       tokenResp.ExpiresIn,
+		Scope:           tokenResp.Scope,
+		IssuedTokenType: tokenResp.IssuedTokenType,
+	}, nil
+}
+
+// handleDeviceCodeGrant exchanges an approved device code for tokens (RFC 8628, section 3.4).
+// Until the user has decided, polls fail with authorization_pending; polling faster than the
+// interval fails with slow_down, and polling after expiry fails with expired_token.
+func (s *Service) handleDeviceCodeGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
+	if req.DeviceCode == "" {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
+	}
+
+	authorization, err := s.flowRepo.FindDeviceAuthorization(ctx, req.DeviceCode)
+	if err != nil {
+		return nil, err
+	}
+	if authorization == nil || authorization.ClientID != req.ClientID {
 		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
 	}
+	if time.Now().After(authorization.ExpiresAt) {
+		return nil, errors.BadRequest(errors.ErrMsgExpiredToken)
+	}
 
-	// Validate PKCE if used
-	if authCode.CodeChallenge != "" {
-		if req.CodeVerifier == "" {
-			return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	interval := time.Duration(authorization.Interval) * time.Second
+	allowed, err := s.flowRepo.RecordDevicePoll(ctx, authorization.DeviceCode, interval)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		// Every slow_down lengthens the interval for the rest of the flow (RFC 8628, section 3.5)
+		if _, err := s.flowRepo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *DeviceAuthorization) bool {
+			current.Interval += deviceSlowDownIncrement
+			return true
+		}); err != nil {
+			return nil, err
 		}
+		return nil, errors.BadRequest(errors.ErrMsgSlowDown)
+	}
 
-		if !pkce.VerifyCodeChallenge(req.CodeVerifier, authCode.CodeChallenge, authCode.CodeChallengeMethod) {
-			return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
-		}
+	switch authorization.Status {
+	case DeviceStatusPending:
+		return nil, errors.BadRequest(errors.ErrMsgAuthorizationPending)
+	case DeviceStatusDenied:
+		s.flowRepo.DeleteDeviceAuthorization(ctx, authorization)
+		return nil, errors.BadRequest(errors.ErrMsgAccessDenied)
 	}
 
-	// Mark code as used
-	if err := s.oauthRepo.MarkCodeAsUsed(ctx, req.Code); err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToMarkCodeAsUsed)
+	// Consume the device code; only one poll can exchange it
+	consumed, err := s.flowRepo.DeleteDeviceAuthorization(ctx, authorization)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
 	}
 
-	// Generate tokens
-	tokenResp, err := s.tokenService.CreateTokens(ctx, authCode.UserID, authCode.ClientID, authCode.Scope, req.Code)
+	tokenResp, err := s.tokenService.CreateTokens(ctx, authorization.UserID, authorization.ClientID, authorization.Scope, "")
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert token.TokenCreateResponse to TokenResponse
+	// Issue an ID token when the openid scope was granted
+	var idToken string
+	if hasScope(authorization.Scope, scope.ScopeOpenID) {
+		idToken, err = s.createIDToken(ctx, idTokenParams{
+			UserID:   authorization.UserID,
+			ClientID: authorization.ClientID,
+			Scope:    authorization.Scope,
+			AuthTime: authorization.AuthTime,
+		}, tokenResp.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &TokenResponse{
 		AccessToken:  tokenResp.AccessToken,
 		TokenType:    tokenResp.TokenType,
 		ExpiresIn:    tokenResp.ExpiresIn,
 		RefreshToken: tokenResp.RefreshToken,
 		Scope:        tokenResp.Scope,
+		IDToken:      idToken,
 	}, nil
 }
 
-func (s *Service) handleRefreshTokenGrant(ctx context.Context, req TokenRequest) (*TokenResponse, error) {
-	if req.RefreshToken == "" {
-		return nil, errors.BadRequest(errors.ErrMsgInvalidRequest)
-	}
-
-	tokenResp, err := s.tokenService.RefreshTokens(ctx, req.RefreshToken, req.ClientID, req.Scope)
+// findPendingDeviceAuthorization looks up a device authorization by the user code entered
+// on the verification page. Codes are matched case-insensitively, ignoring separators.
+func (s *Service) findPendingDeviceAuthorization(ctx context.Context, userCode string) (*DeviceAuthorization, error) {
+	authorization, err := s.flowRepo.FindDeviceAuthorizationByUserCode(ctx, normalizeUserCode(userCode))
 	if err != nil {
 		return nil, err
 	}
-
-	return &TokenResponse{
-		AccessToken:  tokenResp.AccessToken,
-		TokenType:    tokenResp.TokenType,
-		ExpiresIn:    tokenResp.ExpiresIn,
-		RefreshToken: tokenResp.RefreshToken,
-		Scope:        tokenResp.Scope,
-	}, nil
+	if authorization == nil || time.Now().After(authorization.ExpiresAt) {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidUserCode)
+	}
+	if authorization.Status != DeviceStatusPending {
+		return nil, errors.BadRequest(errors.ErrMsgDeviceAuthorizationAlreadyHandled)
+	}
+	return authorization, nil
 }
 
-func (s *Service) needsConsent(ctx context.Context, userID uint, clientID, scope string) bool {
-	consent, err := s.oauthRepo.FindUserConsent(ctx, userID, clientID)
-	if err != nil || consent == nil {
-		return true
+// issueDeviceVerificationCSRFToken returns the CSRF token for the verification page of a
+// pending device authorization shown to the user. A token already issued to the same user is
+// reused, so the page can be opened more than once; showing the page to another user replaces it.
+func (s *Service) issueDeviceVerificationCSRFToken(ctx context.Context, authorization *DeviceAuthorization, userID uint) (string, error) {
+	if authorization.CSRFToken != "" && authorization.CSRFUserID == userID {
+		return authorization.CSRFToken, nil
 	}
 
-	// Check if requested scope is within already consented scope
-	requestedScopes := strings.Split(scope, " ")
-	consentedScopes := strings.Split(consent.Scope, " ")
+	csrfToken, err := s.generateAuthorizationCode()
+	if err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToSaveDeviceAuthorization)
+	}
 
-	for _, requested := range requestedScopes {
-		found := false
-		for _, consented := range consentedScopes {
-			if requested == consented {
-				found = true
-				break
-			}
+	updated, err := s.flowRepo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *DeviceAuthorization) bool {
+		if current.Status != DeviceStatusPending {
+			return false
 		}
-		if !found {
+		if current.CSRFToken != "" && current.CSRFUserID == userID {
+			// Issued in a concurrent request for the same user
+			csrfToken = current.CSRFToken
 			return true
 		}
+		current.CSRFToken = csrfToken
+		current.CSRFUserID = userID
+		return true
+	})
+	if err != nil {
+		return "", err
 	}
+	if !updated {
+		return "", errors.BadRequest(errors.ErrMsgDeviceAuthorizationAlreadyHandled)
+	}
+	return csrfToken, nil
+}
 
-	return false
+// validDeviceVerificationCSRFToken reports whether a CSRF token is the one issued to the user
+// with the verification page of a device authorization. Tokens are compared in constant time.
+func validDeviceVerificationCSRFToken(authorization *DeviceAuthorization, userID uint, csrfToken string) bool {
+	if authorization.CSRFToken == "" || authorization.CSRFUserID != userID {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(authorization.CSRFToken), []byte(csrfToken)) == 1
 }
 
-func (s *Service) generateAuthorizationCode() (string, error) {
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
+// generateUserCode creates a random user code of userCodeLength characters from userCodeCharset.
+func generateUserCode() (string, error) {
+	charsetSize := big.NewInt(int64(len(userCodeCharset)))
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, charsetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeCharset[n.Int64()]
 	}
-	return base64.URLEncoding.EncodeToString(b), nil
+	return string(code), nil
 }
 
-// Additional methods for client validation
-func (s *Service) ValidateClient(ctx context.Context, clientID, clientSecret string) (*client.Client, error) {
-	return s.clientService.ValidateClient(ctx, clientID, clientSecret)
+// normalizeUserCode converts a user code as typed by the user to its stored form.
+func normalizeUserCode(userCode string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(userCode))
 }
 
-func (s *Service) IsPublicClient(ctx context.Context, clientID string) (bool, error) {
-	client, err := s.clientService.GetByClientID(ctx, clientID)
-	if err != nil {
-		return false, err
-	}
-	if client == nil {
-		return false, errors.NotFound(errors.ErrMsgClientNotFound)
-	}
-	return !client.IsConfidential, nil
+// formatUserCode splits a user code in two halves for display, e.g. "BDFG-HJKL".
+func formatUserCode(userCode string) string {
+	half := len(userCode) / 2
+	return userCode[:half] + "-" + userCode[half:]
 }
@@ -0,0 +1,250 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/audit"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/token"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// errorMessage returns the message of a CustomError, or an empty string for any other error.
+func errorMessage(err error) string {
+	if customErr, ok := err.(errors.CustomError); ok {
+		return customErr.Message
+	}
+	return ""
+}
+
+func TestDeviceCodeGrantSlowDownLengthensInterval(t *testing.T) {
+	flowRepo := newFakeFlowRepository()
+	service := &Service{flowRepo: flowRepo}
+	ctx := context.Background()
+
+	flowRepo.SaveDeviceAuthorization(ctx, &DeviceAuthorization{
+		DeviceCode: "device-code",
+		UserCode:   "ABCD-EFGH",
+		ClientID:   "client-a",
+		Status:     DeviceStatusPending,
+		Interval:   5,
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	})
+	req := TokenRequest{GrantType: GrantTypeDeviceCode, DeviceCode: "device-code", ClientID: "client-a"}
+
+	if _, err := service.handleDeviceCodeGrant(ctx, req); errorMessage(err) != errors.ErrMsgAuthorizationPending {
+		t.Fatalf("first poll: got %v, want authorization_pending", err)
+	}
+
+	// Every poll within the interval is answered with slow_down and adds 5 seconds to it
+	for _, want := range []int{10, 15} {
+		if _, err := service.handleDeviceCodeGrant(ctx, req); errorMessage(err) != errors.ErrMsgSlowDown {
+			t.Fatalf("early poll: got %v, want slow_down", err)
+		}
+		authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code")
+		if authorization.Interval != want {
+			t.Errorf("interval = %d, want %d", authorization.Interval, want)
+		}
+	}
+}
+
+func TestDeviceVerificationDecidesOnce(t *testing.T) {
+	flowRepo := newFakeFlowRepository()
+	oauthRepo := newFakeOAuthRepository()
+	service := &Service{flowRepo: flowRepo, oauthRepo: oauthRepo}
+	ctx := context.Background()
+
+	flowRepo.SaveDeviceAuthorization(ctx, &DeviceAuthorization{
+		DeviceCode: "device-code",
+		UserCode:   "ABCDEFGH",
+		ClientID:   "client-a",
+		Status:     DeviceStatusPending,
+		Interval:   5,
+		CSRFToken:  "csrf-token",
+		CSRFUserID: 2,
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	})
+
+	// Another request decides on the authorization after this one looked it up
+	flowRepo.afterFindByUserCode = func() {
+		flowRepo.UpdateDeviceAuthorization(ctx, "device-code", func(current *DeviceAuthorization) bool {
+			current.Status = DeviceStatusApproved
+			current.UserID = 1
+			return true
+		})
+	}
+
+	err := service.HandleDeviceVerification(ctx, DeviceVerificationRequest{UserCode: "ABCD-EFGH", Consent: true, CSRFToken: "csrf-token"}, 2, time.Now())
+	if errorMessage(err) != errors.ErrMsgDeviceAuthorizationAlreadyHandled {
+		t.Fatalf("got %v, want the authorization to be already handled", err)
+	}
+
+	authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code")
+	if authorization.Status != DeviceStatusApproved || authorization.UserID != 1 {
+		t.Errorf("decision was overwritten: %+v", authorization)
+	}
+	if consent, _ := oauthRepo.FindUserConsent(ctx, 2, "client-a"); consent != nil {
+		t.Error("consent stored for an approval that lost the race")
+	}
+}
+
+func TestDeviceVerificationOfExpiredRequestStoresNoConsent(t *testing.T) {
+	flowRepo := newFakeFlowRepository()
+	oauthRepo := newFakeOAuthRepository()
+	service := &Service{flowRepo: flowRepo, oauthRepo: oauthRepo}
+	ctx := context.Background()
+
+	flowRepo.SaveDeviceAuthorization(ctx, &DeviceAuthorization{
+		DeviceCode: "device-code",
+		UserCode:   "ABCDEFGH",
+		ClientID:   "client-a",
+		Scope:      "openid",
+		Status:     DeviceStatusPending,
+		Interval:   5,
+		CSRFToken:  "csrf-token",
+		CSRFUserID: 1,
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	})
+
+	// The authorization expires after it was looked up
+	flowRepo.afterFindByUserCode = func() {
+		flowRepo.UpdateDeviceAuthorization(ctx, "device-code", func(current *DeviceAuthorization) bool {
+			current.ExpiresAt = time.Now().Add(-time.Second)
+			return true
+		})
+	}
+
+	err := service.HandleDeviceVerification(ctx, DeviceVerificationRequest{UserCode: "ABCD-EFGH", Consent: true, CSRFToken: "csrf-token"}, 1, time.Now())
+	if errorMessage(err) != errors.ErrMsgDeviceAuthorizationAlreadyHandled {
+		t.Fatalf("got %v, want the authorization to be already handled", err)
+	}
+
+	authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code")
+	if authorization.Status != DeviceStatusPending {
+		t.Errorf("expired authorization was decided on: %+v", authorization)
+	}
+	if consent, _ := oauthRepo.FindUserConsent(ctx, 1, "client-a"); consent != nil {
+		t.Error("consent stored for an expired request")
+	}
+}
+
+func TestDeviceVerificationRequiresCSRFToken(t *testing.T) {
+	flowRepo := newFakeFlowRepository()
+	oauthRepo := newFakeOAuthRepository()
+	service := &Service{flowRepo: flowRepo, oauthRepo: oauthRepo, auditService: audit.NewService(&fakeAuditRepository{})}
+	ctx := context.Background()
+
+	flowRepo.SaveDeviceAuthorization(ctx, &DeviceAuthorization{
+		DeviceCode: "device-code",
+		UserCode:   "ABCDEFGH",
+		ClientID:   "client-a",
+		Scope:      "openid",
+		Status:     DeviceStatusPending,
+		Interval:   5,
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	})
+	issue := func(userID uint) string {
+		t.Helper()
+		authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code")
+		csrfToken, err := service.issueDeviceVerificationCSRFToken(ctx, authorization, userID)
+		if err != nil {
+			t.Fatalf("issueDeviceVerificationCSRFToken: %v", err)
+		}
+		return csrfToken
+	}
+	decide := func(csrfToken string, userID uint) error {
+		req := DeviceVerificationRequest{UserCode: "ABCD-EFGH", Consent: true, CSRFToken: csrfToken}
+		return service.HandleDeviceVerification(ctx, req, userID, time.Now())
+	}
+
+	// Opening the page again keeps the token of the first visit
+	csrfToken := issue(1)
+	if again := issue(1); again != csrfToken {
+		t.Errorf("token reissued for the same user: %q, want %q", again, csrfToken)
+	}
+
+	if err := decide("", 1); errorMessage(err) != errors.ErrMsgInvalidCSRFToken {
+		t.Errorf("missing token: got %v, want invalid CSRF token", err)
+	}
+	if err := decide("forged", 1); errorMessage(err) != errors.ErrMsgInvalidCSRFToken {
+		t.Errorf("wrong token: got %v, want invalid CSRF token", err)
+	}
+	if err := decide(csrfToken, 2); errorMessage(err) != errors.ErrMsgInvalidCSRFToken {
+		t.Errorf("token of another user: got %v, want invalid CSRF token", err)
+	}
+
+	// Showing the page to another user replaces the token
+	otherToken := issue(2)
+	if err := decide(csrfToken, 1); errorMessage(err) != errors.ErrMsgInvalidCSRFToken {
+		t.Errorf("replaced token: got %v, want invalid CSRF token", err)
+	}
+	if authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code"); authorization.Status != DeviceStatusPending {
+		t.Fatalf("rejected decisions changed the authorization: %+v", authorization)
+	}
+
+	if err := decide(otherToken, 2); err != nil {
+		t.Fatalf("decision with the issued token: %v", err)
+	}
+	authorization, _ := flowRepo.FindDeviceAuthorization(ctx, "device-code")
+	if authorization.Status != DeviceStatusApproved || authorization.UserID != 2 {
+		t.Errorf("decision not recorded: %+v", authorization)
+	}
+}
+
+func TestTokenExchangeRequiresPolicy(t *testing.T) {
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+	exchanger := &client.Client{
+		ClientID:   "client-a",
+		IsActive:   true,
+		Scope:      "openid",
+		GrantTypes: []string{client.GrantTypeTokenExchange},
+	}
+	service := &Service{clientService: client.NewService(newFakeClientRepository(exchanger), nil)}
+
+	_, err := service.handleTokenExchangeGrant(context.Background(), TokenRequest{
+		GrantType:        GrantTypeTokenExchange,
+		ClientID:         "client-a",
+		SubjectToken:     "subject-token",
+		SubjectTokenType: token.TokenTypeURIAccessToken,
+	})
+	if errorMessage(err) != errors.ErrMsgUnauthorizedClient {
+		t.Fatalf("got %v, want unauthorized_client for a client without an exchange policy", err)
+	}
+}
+
+func TestDeniedConsentRemovesPushedRequest(t *testing.T) {
+	flowRepo := newFakeFlowRepository()
+	service := &Service{flowRepo: flowRepo, auditService: audit.NewService(&fakeAuditRepository{})}
+	ctx := context.Background()
+
+	requestURI := requestURIPrefix + "pushed"
+	flowRepo.SavePushedAuthorizationRequest(ctx, &PushedAuthorizationRequest{
+		RequestURI: requestURI,
+		Request:    AuthorizeRequest{ClientID: "client-a"},
+		ExpiresAt:  time.Now().Add(time.Minute),
+	})
+	flowRepo.SaveAuthorizationSession(ctx, &AuthorizationSession{
+		ID:         "session",
+		UserID:     1,
+		Request:    AuthorizeRequest{ClientID: "client-a"},
+		RequestURI: requestURI,
+		Scope:      "openid",
+		Policy:     ConsentPolicyNoConsent,
+		CSRFToken:  "csrf",
+		ExpiresAt:  time.Now().Add(time.Minute),
+	})
+
+	session, err := service.CompleteAuthorizationSession(ctx, ConsentDecisionRequest{SessionID: "session", CSRFToken: "csrf"}, 1)
+	if err != nil {
+		t.Fatalf("CompleteAuthorizationSession: %v", err)
+	}
+	if session.Request.consented {
+		t.Error("denied request marked as consented")
+	}
+	if pushed, _ := flowRepo.FindPushedAuthorizationRequest(ctx, requestURI); pushed != nil {
+		t.Error("pushed request still usable after consent was denied")
+	}
+}
@@ -0,0 +1,72 @@
+// Package oauth provides functionality for implementing OAuth 2.0 authorization flows,
+// including authorization code, implicit, password, and client credentials.
+package oauth
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+)
+
+// templateFS holds the default page templates and static assets of the hosted pages.
+// The layout of a template directory configured with TEMPLATE_DIR mirrors this one:
+// page templates at the top level and assets, such as style.css, in static/.
+//
+//go:embed templates
+var templateFS embed.FS
+
+// Page templates rendered by the handler. Each page is parsed together with layoutTemplate.
+const (
+	layoutTemplate  = "layout.html"
+	loginTemplate   = "login.html"
+	consentTemplate = "consent.html"
+)
+
+// pageFS serves files from an operator-provided directory, falling back to
+// the embedded defaults for files the directory does not contain.
+type pageFS struct {
+	override fs.FS // Template directory; nil when none is configured
+	defaults fs.FS // Embedded templates and assets
+}
+
+// Open opens the named file from the template directory if it exists there,
+// and from the embedded defaults otherwise.
+func (p pageFS) Open(name string) (fs.File, error) {
+	if p.override != nil {
+		if f, err := p.override.Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return p.defaults.Open(name)
+}
+
+// newPageFS returns the file system the hosted pages are rendered from.
+// Files in dir take precedence over the embedded ones; an empty dir uses the embedded files only.
+func newPageFS(dir string) fs.FS {
+	defaults, err := fs.Sub(templateFS, "templates")
+	if err != nil {
+		panic("embedded templates are missing: " + err.Error())
+	}
+
+	files := pageFS{defaults: defaults}
+	if dir != "" {
+		files.override = os.DirFS(dir)
+	}
+	return files
+}
+
+// parsePages parses every page template with the shared layout and template functions.
+// Returns the templates by page name, or an error if any of them is invalid.
+func parsePages(files fs.FS, funcs template.FuncMap) (map[string]*template.Template, error) {
+	pages := make(map[string]*template.Template)
+	for _, name := range []string{loginTemplate, consentTemplate} {
+		page, err := template.New(name).Funcs(funcs).ParseFS(files, layoutTemplate, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		pages[name] = page
+	}
+	return pages, nil
+}
@@ -6,6 +6,14 @@ import (
 	"time"
 )
 
+// Built-in scope names seeded by the scopes migration.
+const (
+	ScopeOpenID        = "openid"         // Requests an OpenID Connect ID token
+	ScopeProfile       = "profile"        // Access to basic profile claims
+	ScopeEmail         = "email"          // Access to email claims
+	ScopeOfflineAccess = "offline_access" // Access to refresh tokens
+)
+
 // Scope represents an OAuth permission scope stored in the database.
 type Scope struct {
 	ID          uint      `json:"id"`          // Primary key
@@ -0,0 +1,28 @@
+// Package session provides browser login sessions, which authenticate users
+// at the authorization endpoint and the pages of the hosted login flow.
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Session represents a user's login in a browser.
+// The browser holds its ID in a secure, HttpOnly cookie; the session itself is stored in Redis.
+type Session struct {
+	ID        string    `json:"id"`                   // Opaque identifier stored in the session cookie
+	UserID    uint      `json:"user_id"`              // User who logged in
+	AuthTime  time.Time `json:"auth_time"`            // When the user entered their credentials
+	ExpiresAt time.Time `json:"expires_at"`           // Expiration timestamp
+	CreatedAt time.Time `json:"created_at"`           // Creation timestamp
+	UserAgent string    `json:"user_agent,omitempty"` // Browser user agent for audit
+	IPAddress string    `json:"ip_address,omitempty"` // Client IP address for audit
+}
+
+// PublicID returns an identifier of the session that can be shown to the user and sent back
+// to end the session. Unlike the session ID, it cannot be used as a session cookie.
+func (s *Session) PublicID() string {
+	sum := sha256.Sum256([]byte(s.ID))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,23 @@
+// Package session provides browser login sessions, which authenticate users
+// at the authorization endpoint and the pages of the hosted login flow.
+package session
+
+import (
+	"context"
+)
+
+// Repository defines the interface for login session storage.
+type Repository interface {
+	// Save stores a session until it expires.
+	Save(ctx context.Context, session *Session) error
+
+	// FindByID looks up a session by its ID.
+	// Returns nil if the session doesn't exist.
+	FindByID(ctx context.Context, id string) (*Session, error)
+
+	// FindByUserID retrieves the stored sessions of a user, which may include expired ones.
+	FindByUserID(ctx context.Context, userID uint) ([]Session, error)
+
+	// Delete removes a session. Deleting a session that doesn't exist is not an error.
+	Delete(ctx context.Context, id string) error
+}
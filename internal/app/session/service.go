@@ -0,0 +1,130 @@
+// Package session provides browser login sessions, which authenticate users
+// at the authorization endpoint and the pages of the hosted login flow.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// Service manages browser login sessions.
+type Service struct {
+	repo     Repository
+	lifetime time.Duration
+}
+
+// NewService creates a new session service with the lifetime configured in SESSION_LIFETIME.
+func NewService(repo Repository) *Service {
+	lifetime, err := time.ParseDuration(config.AppConfig.SessionLifetime)
+	if err != nil {
+		panic("invalid session lifetime: " + err.Error())
+	}
+
+	return &Service{
+		repo:     repo,
+		lifetime: lifetime,
+	}
+}
+
+// Create starts a new login session for a user who has just entered their credentials.
+func (s *Service) Create(ctx context.Context, userID uint, userAgent, ipAddress string) (*Session, error) {
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, errors.Internal(errors.ErrMsgFailedToCreateSession)
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:        id,
+		UserID:    userID,
+		AuthTime:  now,
+		ExpiresAt: now.Add(s.lifetime),
+		CreatedAt: now,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+	}
+	if err := s.repo.Save(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Get returns the session with the given ID.
+// Returns nil if the session doesn't exist or has expired.
+func (s *Service) Get(ctx context.Context, id string) (*Session, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	session, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+// ListByUser returns the unexpired login sessions of a user, most recent login first.
+func (s *Service) ListByUser(ctx context.Context, userID uint) ([]Session, error) {
+	stored, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := make([]Session, 0, len(stored))
+	for _, session := range stored {
+		if session.UserID == userID && now.Before(session.ExpiresAt) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].AuthTime.After(sessions[j].AuthTime)
+	})
+
+	return sessions, nil
+}
+
+// Delete ends a login session.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// DeleteByUser ends all login sessions of a user.
+func (s *Service) DeleteByUser(ctx context.Context, userID uint) error {
+	sessions, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.repo.Delete(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lifetime returns how long a new session stays valid.
+func (s *Service) Lifetime() time.Duration {
+	return s.lifetime
+}
+
+// generateSessionID returns a random, URL-safe session identifier with 256 bits of entropy.
+func generateSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -30,4 +30,31 @@ type TokenCreateResponse struct {
 	ExpiresIn    int    `json:"expires_in"`              // Time in seconds until the token expires
 	RefreshToken string `json:"refresh_token,omitempty"` // Refresh token for obtaining new access tokens
 	Scope        string `json:"scope,omitempty"`         // Space-separated list of granted scopes
+
+	IssuedTokenType string `json:"issued_token_type,omitempty"` // Type of the issued token (token exchange only)
+}
+
+// ExchangeRequest describes a token exchange (RFC 8693) requested by a client.
+type ExchangeRequest struct {
+	SubjectToken  string // Access token of the user on whose behalf the new token is issued
+	ActorToken    string // Optional access token of the party acting for the user
+	ClientID      string // Client performing the exchange
+	Audience      string // Client ID the new token is intended for; defaults to ClientID
+	Scope         string // Requested scope; defaults to the scope of the subject token
+	Impersonation bool   // Whether to omit the act claim and issue the token as if to the user
+}
+
+// IntrospectionResponse represents the state of a token as returned by the
+// introspection endpoint (RFC 7662). Inactive tokens carry only the active flag.
+type IntrospectionResponse struct {
+	Active    bool                   `json:"active"`               // Whether the token is currently active
+	Scope     string                 `json:"scope,omitempty"`      // Space-separated list of granted scopes
+	ClientID  string                 `json:"client_id,omitempty"`  // Client the token was issued to
+	Sub       string                 `json:"sub,omitempty"`        // Subject: user ID, or client ID for client-only tokens
+	Aud       string                 `json:"aud,omitempty"`        // Audience of the token
+	Act       map[string]interface{} `json:"act,omitempty"`        // Acting party of a delegated token
+	Exp       int64                  `json:"exp,omitempty"`        // Expiration time (Unix seconds)
+	Iat       int64                  `json:"iat,omitempty"`        // Issue time (Unix seconds)
+	JTI       string                 `json:"jti,omitempty"`        // Token identifier
+	TokenType string                 `json:"token_type,omitempty"` // "Bearer" for access tokens, "refresh_token" for refresh tokens
 }
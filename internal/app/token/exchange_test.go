@@ -0,0 +1,106 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// errorMessage returns the message of a CustomError, or an empty string for any other error.
+func errorMessage(err error) string {
+	if customErr, ok := err.(errors.CustomError); ok {
+		return customErr.Message
+	}
+	return ""
+}
+
+func TestExchangeTokenOfOwnClient(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid profile", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+
+	response, err := service.ExchangeToken(ctx, ExchangeRequest{SubjectToken: tokens.AccessToken, ClientID: "client-a", Scope: "openid"})
+	if err != nil {
+		t.Fatalf("ExchangeToken: %v", err)
+	}
+	if response.Scope != "openid" {
+		t.Errorf("scope = %q, want openid", response.Scope)
+	}
+}
+
+func TestExchangeTokenOfOtherClient(t *testing.T) {
+	exchanger := testClient("client-b")
+	exchanger.TokenExchangePolicy = client.TokenExchangePolicy{AllowedAudiences: []string{"client-c"}}
+	service, _ := newTestService(t, testClient("client-a"), exchanger)
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	req := ExchangeRequest{SubjectToken: tokens.AccessToken, ClientID: "client-b"}
+
+	// Listing audiences does not allow exchanging the tokens of every client
+	if _, err := service.ExchangeToken(ctx, req); errorMessage(err) != errors.ErrMsgInvalidGrant {
+		t.Fatalf("got %v, want invalid_grant for a subject client not in the policy", err)
+	}
+
+	exchanger.TokenExchangePolicy.AllowedSubjectClients = []string{"client-a"}
+	if _, err := service.ExchangeToken(ctx, req); err != nil {
+		t.Fatalf("ExchangeToken with the subject client in the policy: %v", err)
+	}
+}
+
+func TestExchangeTokenScopeLimitedToClient(t *testing.T) {
+	exchanger := testClient("client-b")
+	exchanger.Scope = "openid"
+	exchanger.TokenExchangePolicy = client.TokenExchangePolicy{AllowedSubjectClients: []string{"client-a"}}
+	service, _ := newTestService(t, testClient("client-a"), exchanger)
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid email", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+
+	// The subject token carries a scope the exchanging client is not registered for
+	_, err = service.ExchangeToken(ctx, ExchangeRequest{SubjectToken: tokens.AccessToken, ClientID: "client-b"})
+	if errorMessage(err) != errors.ErrMsgInvalidScope {
+		t.Fatalf("got %v, want invalid_scope for the full subject scope", err)
+	}
+
+	response, err := service.ExchangeToken(ctx, ExchangeRequest{SubjectToken: tokens.AccessToken, ClientID: "client-b", Scope: "openid"})
+	if err != nil {
+		t.Fatalf("ExchangeToken with a registered scope: %v", err)
+	}
+	if response.Scope != "openid" {
+		t.Errorf("scope = %q, want openid", response.Scope)
+	}
+}
+
+func TestExchangeTokenOfExpiringSubjectToken(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	// Issued at the start of a second, the subject token expires at the end of the next one,
+	// so it has less than a second left when the exchange below runs
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	service.accessExpiry = 1500 * time.Millisecond
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = service.ExchangeToken(ctx, ExchangeRequest{SubjectToken: tokens.AccessToken, ClientID: "client-a"})
+	if errorMessage(err) != errors.ErrMsgInvalidGrant {
+		t.Fatalf("got %v, want invalid_grant for a subject token with less than a second left", err)
+	}
+}
@@ -0,0 +1,346 @@
+package token
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/auth"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
+)
+
+// newTestService creates a token service backed by in-memory repositories,
+// with a fresh signing key, the given clients and the standard OpenID Connect scopes registered.
+func newTestService(t *testing.T, clients ...*client.Client) (*Service, *fakeTokenRepository) {
+	t.Helper()
+
+	config.AppConfig.JWTAccessExpiry = "15m"
+	config.AppConfig.JWTRefreshExpiry = "168h"
+	config.AppConfig.JWTIDTokenExpiry = "1h"
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+	config.AppConfig.TokenHashPepper = "test-pepper"
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwtutil.NewSigningKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jwtutil.SetKeys([]*jwtutil.SigningKey{key}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	clientRepo := &fakeClientRepository{clients: make(map[string]*client.Client)}
+	for _, c := range clients {
+		clientRepo.clients[c.ClientID] = c
+	}
+
+	authService := auth.NewService(nil, nil)
+	tokenRepo := newFakeTokenRepository()
+	scopeRepo := &fakeScopeRepository{names: []string{"openid", "profile", "email"}}
+	service := NewService(tokenRepo, newFakeCache(), authService, client.NewService(clientRepo, authService), scope.NewService(scopeRepo))
+	return service, tokenRepo
+}
+
+// fakeClientRepository is an in-memory client.Repository keyed by client ID.
+type fakeClientRepository struct {
+	clients map[string]*client.Client
+}
+
+func (r *fakeClientRepository) Save(ctx context.Context, c *client.Client) error {
+	r.clients[c.ClientID] = c
+	return nil
+}
+
+func (r *fakeClientRepository) Update(ctx context.Context, c *client.Client) error {
+	r.clients[c.ClientID] = c
+	return nil
+}
+
+func (r *fakeClientRepository) FindByID(ctx context.Context, id uint) (*client.Client, error) {
+	for _, c := range r.clients {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeClientRepository) FindByClientID(ctx context.Context, clientID string) (*client.Client, error) {
+	return r.clients[clientID], nil
+}
+
+func (r *fakeClientRepository) FindByOwnerID(ctx context.Context, ownerID uint, page, limit int) ([]client.Client, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeClientRepository) Delete(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (r *fakeClientRepository) UpdateStatus(ctx context.Context, id uint, isActive bool) error {
+	return nil
+}
+
+// fakeScopeRepository is an in-memory scope.Repository of the given scope names.
+type fakeScopeRepository struct {
+	names []string
+}
+
+func (r *fakeScopeRepository) Save(ctx context.Context, s *scope.Scope) error {
+	r.names = append(r.names, s.Name)
+	return nil
+}
+
+func (r *fakeScopeRepository) FindByName(ctx context.Context, name string) (*scope.Scope, error) {
+	for _, n := range r.names {
+		if n == name {
+			return &scope.Scope{Name: n}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeScopeRepository) FindByNames(ctx context.Context, names []string) ([]scope.Scope, error) {
+	var scopes []scope.Scope
+	for _, name := range names {
+		if found, _ := r.FindByName(ctx, name); found != nil {
+			scopes = append(scopes, *found)
+		}
+	}
+	return scopes, nil
+}
+
+func (r *fakeScopeRepository) FindAll(ctx context.Context) ([]scope.Scope, error) {
+	return r.FindByNames(ctx, r.names)
+}
+
+func (r *fakeScopeRepository) FindDefaults(ctx context.Context) ([]scope.Scope, error) {
+	return nil, nil
+}
+
+// fakeCache is an in-memory CacheRepository that ignores expiration.
+type fakeCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{values: make(map[string]string)}
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := value.(string); ok {
+		c.values[key] = s
+	} else {
+		c.values[key] = "1"
+	}
+	return nil
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key], nil
+}
+
+func (c *fakeCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	return nil
+}
+
+// fakeTokenRepository is an in-memory Repository.
+type fakeTokenRepository struct {
+	mu            sync.Mutex
+	accessTokens  map[string]*AccessToken
+	refreshTokens map[string]*RefreshToken
+}
+
+func newFakeTokenRepository() *fakeTokenRepository {
+	return &fakeTokenRepository{
+		accessTokens:  make(map[string]*AccessToken),
+		refreshTokens: make(map[string]*RefreshToken),
+	}
+}
+
+func (r *fakeTokenRepository) SaveAccessToken(ctx context.Context, token *AccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *token
+	r.accessTokens[token.TokenID] = &saved
+	return nil
+}
+
+func (r *fakeTokenRepository) FindAccessToken(ctx context.Context, tokenID string) (*AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.accessTokens[tokenID]; ok {
+		found := *token
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepository) FindAccessTokensByUserID(ctx context.Context, userID uint, page, limit int) ([]AccessToken, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeTokenRepository) FindAccessTokensByClientID(ctx context.Context, clientID string, page, limit int) ([]AccessToken, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeTokenRepository) RevokeAccessToken(ctx context.Context, tokenID string) error {
+	return r.revokeAccessTokens(func(t *AccessToken) bool { return t.TokenID == tokenID })
+}
+
+func (r *fakeTokenRepository) RevokeAccessTokensByUserID(ctx context.Context, userID uint) error {
+	return r.revokeAccessTokens(func(t *AccessToken) bool { return t.UserID != nil && *t.UserID == userID })
+}
+
+func (r *fakeTokenRepository) RevokeAccessTokensByClientID(ctx context.Context, clientID string) error {
+	return r.revokeAccessTokens(func(t *AccessToken) bool { return t.ClientID == clientID })
+}
+
+func (r *fakeTokenRepository) RevokeAccessTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error {
+	return r.revokeAccessTokens(func(t *AccessToken) bool {
+		return t.UserID != nil && *t.UserID == userID && t.ClientID == clientID
+	})
+}
+
+func (r *fakeTokenRepository) RevokeAccessTokensByRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	ids := make(map[string]bool)
+	for _, token := range r.refreshTokens {
+		if token.FamilyID == familyID {
+			ids[token.AccessTokenID] = true
+		}
+	}
+	r.mu.Unlock()
+	return r.revokeAccessTokens(func(t *AccessToken) bool { return ids[t.TokenID] })
+}
+
+func (r *fakeTokenRepository) RevokeAccessTokensByAuthCode(ctx context.Context, authCode string) error {
+	return nil
+}
+
+func (r *fakeTokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.accessTokens[tokenID]
+	return !ok || token.IsRevoked, nil
+}
+
+func (r *fakeTokenRepository) DeleteAccessTokens(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeTokenRepository) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *token
+	r.refreshTokens[token.TokenID] = &saved
+	return nil
+}
+
+func (r *fakeTokenRepository) FindRefreshToken(ctx context.Context, tokenID string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.refreshTokens[tokenID]; ok {
+		found := *token
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.refreshTokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepository) FindRefreshTokensByUserID(ctx context.Context, userID uint, page, limit int) ([]RefreshToken, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeTokenRepository) FindRefreshTokensByClientID(ctx context.Context, clientID string, page, limit int) ([]RefreshToken, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeTokenRepository) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.TokenID == tokenID })
+}
+
+func (r *fakeTokenRepository) RotateRefreshToken(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.refreshTokens[tokenID]
+	if !ok || token.IsRevoked {
+		return false, nil
+	}
+	token.IsRevoked = true
+	return true, nil
+}
+
+func (r *fakeTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.FamilyID == familyID })
+}
+
+func (r *fakeTokenRepository) RevokeRefreshTokensByUserID(ctx context.Context, userID uint) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.UserID == userID })
+}
+
+func (r *fakeTokenRepository) RevokeRefreshTokensByClientID(ctx context.Context, clientID string) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.ClientID == clientID })
+}
+
+func (r *fakeTokenRepository) RevokeRefreshTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.UserID == userID && t.ClientID == clientID })
+}
+
+func (r *fakeTokenRepository) RevokeRefreshTokensByAccessTokenID(ctx context.Context, accessTokenID string) error {
+	return r.revokeRefreshTokens(func(t *RefreshToken) bool { return t.AccessTokenID == accessTokenID })
+}
+
+func (r *fakeTokenRepository) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeTokenRepository) revokeAccessTokens(match func(*AccessToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.accessTokens {
+		if match(token) {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepository) revokeRefreshTokens(match func(*RefreshToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.refreshTokens {
+		if match(token) {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
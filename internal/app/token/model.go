@@ -9,9 +9,9 @@ import (
 type AccessToken struct {
 	ID        uint      `json:"id"`         // Primary key
 	TokenID   string    `json:"token_id"`   // Unique identifier (UUID) for the token
-	TokenHash string    `json:"-"`          // Hashed token value, not exposed in JSON
+	TokenHash string    `json:"-"`          // Keyed digest of the token value, not exposed in JSON
 	ClientID  string    `json:"client_id"`  // OAuth client identifier
-	UserID    uint      `json:"user_id"`    // User the token was issued to
+	UserID    *uint     `json:"user_id"`    // User the token was issued to; nil for client-only tokens
 	Scope     string    `json:"scope"`      // Space-separated list of OAuth scopes
 	ExpiresAt time.Time `json:"expires_at"` // Expiration timestamp
 	CreatedAt time.Time `json:"created_at"` // Creation timestamp
@@ -22,7 +22,8 @@ type AccessToken struct {
 type RefreshToken struct {
 	ID            uint      `json:"id"`              // Primary key
 	TokenID       string    `json:"token_id"`        // Unique identifier (UUID) for the token
-	TokenHash     string    `json:"-"`               // Hashed token value, not exposed in JSON
+	TokenHash     string    `json:"-"`               // Keyed digest of the token value, not exposed in JSON
+	FamilyID      string    `json:"family_id"`       // ID of the first refresh token of the grant, shared by its rotations
 	AccessTokenID string    `json:"access_token_id"` // Related access token ID
 	ClientID      string    `json:"client_id"`       // OAuth client identifier
 	UserID        uint      `json:"user_id"`         // User the token was issued to
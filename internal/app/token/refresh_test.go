@@ -0,0 +1,133 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// familyTokensRevoked reports whether every refresh token of the family and every access token
+// issued with them has been revoked.
+func familyTokensRevoked(repo *fakeTokenRepository, familyID string) bool {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, refreshToken := range repo.refreshTokens {
+		if refreshToken.FamilyID != familyID {
+			continue
+		}
+		if !refreshToken.IsRevoked {
+			return false
+		}
+		if accessToken, ok := repo.accessTokens[refreshToken.AccessTokenID]; ok && !accessToken.IsRevoked {
+			return false
+		}
+	}
+	return true
+}
+
+// familyOf returns the family ID of a refresh token.
+func familyOf(t *testing.T, service *Service, refreshToken string) string {
+	t.Helper()
+
+	token, err := service.findRefreshToken(context.Background(), refreshToken)
+	if err != nil || token == nil {
+		t.Fatalf("findRefreshToken: %v, %v", token, err)
+	}
+	return token.FamilyID
+}
+
+func TestRefreshTokenRotationKeepsFamily(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	rotated, err := service.RefreshTokens(ctx, tokens.RefreshToken, "client-a", "")
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+
+	if familyOf(t, service, rotated.RefreshToken) != familyOf(t, service, tokens.RefreshToken) {
+		t.Error("rotated refresh token started a new family")
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	service, repo := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	unrelated, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	rotated, err := service.RefreshTokens(ctx, tokens.RefreshToken, "client-a", "")
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+
+	// The token that was rotated out is presented again
+	if _, err := service.RefreshTokens(ctx, tokens.RefreshToken, "client-a", ""); errorMessage(err) != errors.ErrMsgTokenRevoked {
+		t.Fatalf("reuse: got %v, want token revoked", err)
+	}
+
+	if !familyTokensRevoked(repo, familyOf(t, service, tokens.RefreshToken)) {
+		t.Error("family not revoked after reuse")
+	}
+	if _, err := service.RefreshTokens(ctx, rotated.RefreshToken, "client-a", ""); errorMessage(err) != errors.ErrMsgTokenRevoked {
+		t.Errorf("latest token of the family: got %v, want token revoked", err)
+	}
+
+	// Tokens of other grants are not affected
+	if _, err := service.RefreshTokens(ctx, unrelated.RefreshToken, "client-a", ""); err != nil {
+		t.Errorf("refresh token of another family: %v", err)
+	}
+}
+
+func TestConcurrentRefreshCountsAsReuse(t *testing.T) {
+	service, repo := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.RefreshTokens(ctx, tokens.RefreshToken, "client-a", "")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+		} else if errorMessage(err) != errors.ErrMsgTokenRevoked {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d refreshes succeeded, want 1", succeeded)
+	}
+
+	// The losers presented a rotated token, which revokes the whole family
+	if !familyTokensRevoked(repo, familyOf(t, service, tokens.RefreshToken)) {
+		t.Error("family not revoked after concurrent refreshes")
+	}
+}
@@ -3,6 +3,7 @@ package token
 
 import (
 	"context"
+	"time"
 )
 
 // Repository defines the interface for token data storage and retrieval operations.
@@ -30,12 +31,23 @@ type Repository interface {
 	// RevokeAccessTokensByClientID revokes all access tokens for a specific client
 	RevokeAccessTokensByClientID(ctx context.Context, clientID string) error
 
+	// RevokeAccessTokensByUserAndClient revokes all access tokens a client holds for a specific user
+	RevokeAccessTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error
+
+	// RevokeAccessTokensByRefreshTokenFamily revokes all access tokens issued alongside
+	// the refresh tokens of a grant
+	RevokeAccessTokensByRefreshTokenFamily(ctx context.Context, familyID string) error
+
 	// RevokeAccessTokensByAuthCode revokes all access tokens associated with an authorization code
 	RevokeAccessTokensByAuthCode(ctx context.Context, authCode string) error
 
 	// IsAccessTokenRevoked checks if an access token has been revoked
 	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
 
+	// DeleteAccessTokens removes access tokens that expired, or were issued and revoked,
+	// before the given time, and returns the number of tokens removed
+	DeleteAccessTokens(ctx context.Context, before time.Time) (int64, error)
+
 	// Refresh token methods
 
 	// SaveRefreshToken stores a new refresh token in the database
@@ -44,7 +56,7 @@ type Repository interface {
 	// FindRefreshToken retrieves a refresh token by its ID
 	FindRefreshToken(ctx context.Context, tokenID string) (*RefreshToken, error)
 
-	// FindRefreshTokenByHash retrieves a refresh token by its hash value
+	// FindRefreshTokenByHash retrieves a refresh token by the digest of its value
 	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
 
 	// FindRefreshTokensByUserID retrieves a paginated list of refresh tokens for a specific user
@@ -56,12 +68,26 @@ type Repository interface {
 	// RevokeRefreshToken marks a refresh token as revoked
 	RevokeRefreshToken(ctx context.Context, tokenID string) error
 
+	// RotateRefreshToken revokes an active refresh token that is being exchanged for a new one.
+	// Returns false if the token had already been revoked, for example by a concurrent refresh
+	RotateRefreshToken(ctx context.Context, tokenID string) (bool, error)
+
+	// RevokeRefreshTokenFamily revokes all refresh tokens descending from the same grant
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+
 	// RevokeRefreshTokensByUserID revokes all refresh tokens for a specific user
 	RevokeRefreshTokensByUserID(ctx context.Context, userID uint) error
 
 	// RevokeRefreshTokensByClientID revokes all refresh tokens for a specific client
 	RevokeRefreshTokensByClientID(ctx context.Context, clientID string) error
 
+	// RevokeRefreshTokensByUserAndClient revokes all refresh tokens a client holds for a specific user
+	RevokeRefreshTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error
+
 	// RevokeRefreshTokensByAccessTokenID revokes all refresh tokens for a specific access token
 	RevokeRefreshTokensByAccessTokenID(ctx context.Context, accessTokenID string) error
+
+	// DeleteExpiredRefreshTokens removes refresh tokens that expired before the given time,
+	// revoked or not, and returns the number of tokens removed
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error)
 }
@@ -5,8 +5,9 @@ package token
 import (
 	"context"
 	"crypto/rand"
-	"crypto/rsa"
 	"encoding/base64"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,7 +15,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/verigate/verigate-server/internal/app/auth"
 	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
 	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 	"github.com/verigate/verigate-server/internal/pkg/utils/hash"
 	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
@@ -24,6 +28,13 @@ import (
 const (
 	TokenTypeBearer = "Bearer" // Bearer token type for Authorization header
 
+	// Token type hints (RFC 7009 and RFC 7662)
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+
+	// Token type identifiers (RFC 8693)
+	TokenTypeURIAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
 	// Cache key prefixes
 	CacheKeyAccessToken = "access_token:" // Prefix for access token cache keys
 )
@@ -43,29 +54,19 @@ type CacheRepository interface {
 // Service handles token-related operations including creation, validation,
 // and revocation of access and refresh tokens.
 type Service struct {
-	tokenRepo     Repository
-	cacheRepo     CacheRepository
-	authService   *auth.Service
-	clientService *client.Service
-	privateKey    *rsa.PrivateKey
-	publicKey     *rsa.PublicKey
-	accessExpiry  time.Duration
-	refreshExpiry time.Duration
+	tokenRepo       Repository
+	cacheRepo       CacheRepository
+	authService     *auth.Service
+	clientService   *client.Service
+	scopeService    *scope.Service
+	accessExpiry    time.Duration
+	refreshExpiry   time.Duration
+	idTokenExpiry   time.Duration
+	tokenHashPepper string // Key of the stored token digests
 }
 
 // NewService creates a new token service instance with the necessary dependencies.
-func NewService(tokenRepo Repository, cacheRepo CacheRepository, authService *auth.Service, clientService *client.Service) *Service {
-	// Parse JWT keys
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.AppConfig.JWTPrivateKey))
-	if err != nil {
-		panic("failed to parse private key: " + err.Error())
-	}
-
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(config.AppConfig.JWTPublicKey))
-	if err != nil {
-		panic("failed to parse public key: " + err.Error())
-	}
-
+func NewService(tokenRepo Repository, cacheRepo CacheRepository, authService *auth.Service, clientService *client.Service, scopeService *scope.Service) *Service {
 	// Parse expiry durations
 	accessExpiry, err := time.ParseDuration(config.AppConfig.JWTAccessExpiry)
 	if err != nil {
@@ -77,21 +78,37 @@ func NewService(tokenRepo Repository, cacheRepo CacheRepository, authService *au
 		panic("invalid refresh token expiry: " + err.Error())
 	}
 
+	idTokenExpiry, err := time.ParseDuration(config.AppConfig.JWTIDTokenExpiry)
+	if err != nil {
+		panic("invalid ID token expiry: " + err.Error())
+	}
+
 	return &Service{
-		tokenRepo:     tokenRepo,
-		cacheRepo:     cacheRepo,
-		authService:   authService,
-		clientService: clientService,
-		privateKey:    privateKey,
-		publicKey:     publicKey,
-		accessExpiry:  accessExpiry,
-		refreshExpiry: refreshExpiry,
+		tokenRepo:       tokenRepo,
+		cacheRepo:       cacheRepo,
+		authService:     authService,
+		clientService:   clientService,
+		scopeService:    scopeService,
+		accessExpiry:    accessExpiry,
+		refreshExpiry:   refreshExpiry,
+		idTokenExpiry:   idTokenExpiry,
+		tokenHashPepper: config.AppConfig.TokenHashPepper,
 	}
 }
 
 // CreateTokens generates new access and refresh tokens for a user.
 // It stores the tokens in the database and returns them to the client.
+// The refresh token starts a new token family.
 func (s *Service) CreateTokens(ctx context.Context, userID uint, clientID, scope, authCode string) (*TokenCreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "token.Service.CreateTokens")
+	defer span.End()
+
+	return s.createTokens(ctx, userID, clientID, scope, "")
+}
+
+// createTokens issues an access and refresh token pair. The refresh token joins the given
+// family, or starts a new one identified by its own token ID if familyID is empty.
+func (s *Service) createTokens(ctx context.Context, userID uint, clientID, scope, familyID string) (*TokenCreateResponse, error) {
 	// Get client configuration for token lifetimes
 	client, err := s.clientService.GetByClientID(ctx, clientID)
 	if err != nil {
@@ -112,8 +129,13 @@ func (s *Service) CreateTokens(ctx context.Context, userID uint, clientID, scope
 		refreshExpiry = time.Duration(client.RefreshTokenLifetime) * time.Second
 	}
 
-	// Generate access token
-	accessToken, accessTokenID, err := s.createAccessTokenWithExpiry(userID, clientID, scope, accessExpiry)
+	// Generate and save access token
+	accessToken, accessTokenID, err := s.issueAccessToken(ctx, accessTokenParams{
+		UserID:   &userID,
+		ClientID: clientID,
+		Scope:    scope,
+		Expiry:   accessExpiry,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -124,36 +146,14 @@ func (s *Service) CreateTokens(ctx context.Context, userID uint, clientID, scope
 		return nil, err
 	}
 
-	// Hash tokens for storage
-	accessTokenHash, err := hash.HashPassword(accessToken)
-	if err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToHashAccessToken)
-	}
-
-	refreshTokenHash, err := hash.HashPassword(refreshToken)
-	if err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToHashRefreshToken)
-	}
-
-	// Save tokens
-	accessTokenModel := &AccessToken{
-		TokenID:   accessTokenID,
-		TokenHash: accessTokenHash,
-		ClientID:  clientID,
-		UserID:    userID,
-		Scope:     scope,
-		ExpiresAt: time.Now().Add(accessExpiry),
-		CreatedAt: time.Now(),
-		IsRevoked: false,
-	}
-
-	if err := s.tokenRepo.SaveAccessToken(ctx, accessTokenModel); err != nil {
-		return nil, err
+	if familyID == "" {
+		familyID = refreshTokenID
 	}
 
 	refreshTokenModel := &RefreshToken{
 		TokenID:       refreshTokenID,
-		TokenHash:     refreshTokenHash,
+		TokenHash:     hash.Token(refreshToken, s.tokenHashPepper),
+		FamilyID:      familyID,
 		AccessTokenID: accessTokenID,
 		ClientID:      clientID,
 		UserID:        userID,
@@ -167,11 +167,6 @@ func (s *Service) CreateTokens(ctx context.Context, userID uint, clientID, scope
 		return nil, err
 	}
 
-	// Cache the access token for quick validation
-	if err := s.cacheRepo.Set(ctx, CacheKeyAccessToken+accessTokenID, accessTokenModel, accessExpiry); err != nil {
-		// Not critical, continue
-	}
-
 	return &TokenCreateResponse{
 		AccessToken:  accessToken,
 		TokenType:    TokenTypeBearer,
@@ -181,18 +176,186 @@ func (s *Service) CreateTokens(ctx context.Context, userID uint, clientID, scope
 	}, nil
 }
 
+// CreateClientToken generates an access token for a client acting on its own behalf
+// (client_credentials grant). The token has no user, so its subject is the client ID,
+// and no refresh token is issued since the client can always request a new token.
+func (s *Service) CreateClientToken(ctx context.Context, clientID, scope string) (*TokenCreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "token.Service.CreateClientToken")
+	defer span.End()
+
+	client, err := s.clientService.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+
+	accessExpiry := s.accessExpiry
+	if client.AccessTokenLifetime > 0 {
+		accessExpiry = time.Duration(client.AccessTokenLifetime) * time.Second
+	}
+
+	accessToken, _, err := s.issueAccessToken(ctx, accessTokenParams{
+		ClientID: clientID,
+		Scope:    scope,
+		Expiry:   accessExpiry,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenCreateResponse{
+		AccessToken: accessToken,
+		TokenType:   TokenTypeBearer,
+		ExpiresIn:   int(accessExpiry.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// ExchangeToken issues an access token for the user of a subject token to the requesting
+// client, as described in RFC 8693. The new token may be narrowed in scope and intended for
+// a different audience, and never outlives the subject token.
+// The subject token must have been issued to the requesting client or to a client listed in
+// its exchange policy, and the new token is limited to the scopes registered for the client.
+// Unless impersonating, the token records the acting party in its act claim: the subject of
+// the actor token when one is given, the requesting client otherwise. An act claim already
+// present on the subject token is nested to keep the delegation chain.
+func (s *Service) ExchangeToken(ctx context.Context, req ExchangeRequest) (*TokenCreateResponse, error) {
+	ctx, span := tracing.Start(ctx, "token.Service.ExchangeToken")
+	defer span.End()
+
+	subject, err := s.validateExchangeToken(ctx, req.SubjectToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only tokens issued to a user can be exchanged
+	userID, ok := subject[jwtutil.ClaimKeyUserID].(float64)
+	if !ok || userID <= 0 {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	client, err := s.clientService.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidClient)
+	}
+
+	// Tokens of other clients can only be exchanged if the policy names their client
+	subjectClientID, _ := subject[jwtutil.ClaimKeyClientID].(string)
+	if subjectClientID != client.ClientID && !client.TokenExchangePolicy.AllowsSubjectClient(subjectClientID) {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	subjectScope, _ := subject[jwtutil.ClaimKeyScope].(string)
+	grantedScope := subjectScope
+	if req.Scope != "" {
+		if !s.isScopeSubset(req.Scope, subjectScope) {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidScope)
+		}
+		grantedScope = req.Scope
+	}
+	if grantedScope != "" {
+		valid, err := s.scopeService.ValidateScope(ctx, grantedScope, client.Scope)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			return nil, errors.BadRequest(errors.ErrMsgInvalidScope)
+		}
+	}
+
+	var actor jwt.MapClaims
+	if !req.Impersonation {
+		actor = jwt.MapClaims{jwtutil.ClaimKeySub: req.ClientID}
+		if req.ActorToken != "" {
+			actorClaims, err := s.validateExchangeToken(ctx, req.ActorToken)
+			if err != nil {
+				return nil, err
+			}
+			actor[jwtutil.ClaimKeySub] = claimString(actorClaims[jwtutil.ClaimKeySub])
+		}
+		if previous, ok := subject[jwtutil.ClaimKeyAct].(map[string]interface{}); ok {
+			actor[jwtutil.ClaimKeyAct] = previous
+		}
+	}
+
+	accessExpiry := s.accessExpiry
+	if client.AccessTokenLifetime > 0 {
+		accessExpiry = time.Duration(client.AccessTokenLifetime) * time.Second
+	}
+	if exp, ok := subject[jwtutil.ClaimKeyEXP].(float64); ok {
+		if remaining := time.Until(time.Unix(int64(exp), 0)); remaining < accessExpiry {
+			accessExpiry = remaining.Truncate(time.Second)
+		}
+	}
+	// A subject token about to expire cannot back a token of its own
+	if accessExpiry < time.Second {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	uid := uint(userID)
+	accessToken, _, err := s.issueAccessToken(ctx, accessTokenParams{
+		UserID:   &uid,
+		ClientID: req.ClientID,
+		Audience: req.Audience,
+		Scope:    grantedScope,
+		Expiry:   accessExpiry,
+		Actor:    actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenCreateResponse{
+		AccessToken:     accessToken,
+		TokenType:       TokenTypeBearer,
+		ExpiresIn:       int(accessExpiry.Seconds()),
+		Scope:           grantedScope,
+		IssuedTokenType: TokenTypeURIAccessToken,
+	}, nil
+}
+
+// CreateIDToken signs an OpenID Connect ID token for the given subject and client
+// using the active key of the requested algorithm.
+// The caller supplies the authentication and scope-dependent claims (auth_time, nonce,
+// at_hash, profile claims); the issuer, subject, audience, issue time and expiry are set here.
+func (s *Service) CreateIDToken(subject, clientID, algorithm string, claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+
+	idClaims := jwt.MapClaims{}
+	for key, value := range claims {
+		idClaims[key] = value
+	}
+	idClaims[jwtutil.ClaimKeyISS] = config.AppConfig.OIDCIssuer
+	idClaims[jwtutil.ClaimKeySub] = subject
+	idClaims[jwtutil.ClaimKeyAud] = clientID
+	idClaims[jwtutil.ClaimKeyIAT] = now.Unix()
+	idClaims[jwtutil.ClaimKeyEXP] = now.Add(s.idTokenExpiry).Unix()
+
+	signedToken, err := jwtutil.SignWith(algorithm, idClaims)
+	if err != nil {
+		return "", errors.Internal(errors.ErrMsgFailedToGenerateIDToken)
+	}
+
+	return signedToken, nil
+}
+
 // RefreshTokens exchanges a valid refresh token for a new access token and refresh token pair.
 // It validates the refresh token, checks scope restrictions, and revokes the old tokens
-// before generating new ones.
+// before generating new ones in the same token family.
+// A refresh token can only be used once. If a revoked token is presented again, it may have
+// been stolen, so the whole family is revoked and both the attacker and the legitimate
+// client have to obtain a new grant.
 func (s *Service) RefreshTokens(ctx context.Context, refreshToken, clientID, requestedScope string) (*TokenCreateResponse, error) {
-	// Hash the refresh token
-	tokenHash, err := hash.HashPassword(refreshToken)
-	if err != nil {
-		return nil, errors.Internal(errors.ErrMsgFailedToHashRefreshToken)
-	}
+	ctx, span := tracing.Start(ctx, "token.Service.RefreshTokens")
+	defer span.End()
 
 	// Find the refresh token
-	token, err := s.tokenRepo.FindRefreshTokenByHash(ctx, tokenHash)
+	token, err := s.findRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -201,15 +364,16 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken, clientID, req
 	}
 
 	// Validate token
+	if token.ClientID != clientID {
+		return nil, errors.Unauthorized(errors.ErrMsgRefreshTokenNotIssuedToClient)
+	}
 	if token.IsRevoked {
+		s.revokeTokenFamily(ctx, token.FamilyID)
 		return nil, errors.Unauthorized(errors.ErrMsgTokenRevoked)
 	}
 	if time.Now().After(token.ExpiresAt) {
 		return nil, errors.Unauthorized(errors.ErrMsgTokenExpired)
 	}
-	if token.ClientID != clientID {
-		return nil, errors.Unauthorized(errors.ErrMsgRefreshTokenNotIssuedToClient)
-	}
 
 	// Validate requested scope
 	scope := token.Scope
@@ -220,23 +384,33 @@ func (s *Service) RefreshTokens(ctx context.Context, refreshToken, clientID, req
 		scope = requestedScope
 	}
 
-	// Revoke old tokens
-	if err := s.tokenRepo.RevokeRefreshToken(ctx, token.TokenID); err != nil {
+	// Revoke old tokens. Losing the race against another refresh with the same token
+	// counts as reuse.
+	rotated, err := s.tokenRepo.RotateRefreshToken(ctx, token.TokenID)
+	if err != nil {
 		return nil, err
 	}
+	if !rotated {
+		s.revokeTokenFamily(ctx, token.FamilyID)
+		return nil, errors.Unauthorized(errors.ErrMsgTokenRevoked)
+	}
 	if token.AccessTokenID != "" {
 		if err := s.tokenRepo.RevokeAccessToken(ctx, token.AccessTokenID); err != nil {
 			// Not critical, continue
 		}
+		s.cacheRepo.Delete(ctx, CacheKeyAccessToken+token.AccessTokenID)
 	}
 
 	// Create new tokens
-	return s.CreateTokens(ctx, token.UserID, token.ClientID, scope, "")
+	return s.createTokens(ctx, token.UserID, token.ClientID, scope, token.FamilyID)
 }
 
 // RevokeAccessToken invalidates an access token if it belongs to the specified client.
 // It removes the token from the cache and marks it as revoked in the database.
 func (s *Service) RevokeAccessToken(ctx context.Context, tokenValue, clientID string) error {
+	ctx, span := tracing.Start(ctx, "token.Service.RevokeAccessToken")
+	defer span.End()
+
 	// Parse JWT to get token ID
 	tokenID, err := s.getTokenIDFromJWT(tokenValue)
 	if err != nil {
@@ -260,6 +434,7 @@ func (s *Service) RevokeAccessToken(ctx context.Context, tokenValue, clientID st
 
 	// Remove from cache
 	s.cacheRepo.Delete(ctx, CacheKeyAccessToken+tokenID)
+	metrics.TokenRevoked(metrics.RevokedByClient)
 
 	return nil
 }
@@ -267,14 +442,11 @@ func (s *Service) RevokeAccessToken(ctx context.Context, tokenValue, clientID st
 // RevokeRefreshToken invalidates a refresh token and its associated access token
 // if they belong to the specified client.
 func (s *Service) RevokeRefreshToken(ctx context.Context, tokenValue, clientID string) error {
-	// Hash the refresh token
-	tokenHash, err := hash.HashPassword(tokenValue)
-	if err != nil {
-		return errors.Internal(errors.ErrMsgFailedToHashRefreshToken)
-	}
+	ctx, span := tracing.Start(ctx, "token.Service.RevokeRefreshToken")
+	defer span.End()
 
 	// Find the refresh token
-	token, err := s.tokenRepo.FindRefreshTokenByHash(ctx, tokenHash)
+	token, err := s.findRefreshToken(ctx, tokenValue)
 	if err != nil || token == nil {
 		return errors.NotFound(errors.ErrMsgTokenNotFound)
 	}
@@ -292,6 +464,7 @@ func (s *Service) RevokeRefreshToken(ctx context.Context, tokenValue, clientID s
 		s.tokenRepo.RevokeAccessToken(ctx, token.AccessTokenID)
 		s.cacheRepo.Delete(ctx, CacheKeyAccessToken+token.AccessTokenID)
 	}
+	metrics.TokenRevoked(metrics.RevokedByClient)
 
 	return nil
 }
@@ -299,6 +472,9 @@ func (s *Service) RevokeRefreshToken(ctx context.Context, tokenValue, clientID s
 // ValidateAccessToken verifies the signature and validity of an access token.
 // It checks if the token has been revoked and returns the claims if the token is valid.
 func (s *Service) ValidateAccessToken(ctx context.Context, tokenValue string) (*jwt.MapClaims, error) {
+	ctx, span := tracing.Start(ctx, "token.Service.ValidateAccessToken")
+	defer span.End()
+
 	// Use the jwtutil.ValidateTokenForRevocation function to validate the token format
 	// and extract the token ID
 	tokenID, err := jwtutil.ValidateTokenForRevocation(tokenValue)
@@ -307,9 +483,7 @@ func (s *Service) ValidateAccessToken(ctx context.Context, tokenValue string) (*
 	}
 
 	// Parse the token to get claims for additional checks and return value
-	token, err := jwt.Parse(tokenValue, func(token *jwt.Token) (interface{}, error) {
-		return s.publicKey, nil
-	})
+	token, err := jwt.Parse(tokenValue, jwtutil.KeyFunc)
 
 	if err != nil {
 		return nil, errors.Unauthorized(errors.ErrMsgInvalidToken)
@@ -338,6 +512,41 @@ func (s *Service) ValidateAccessToken(ctx context.Context, tokenValue string) (*
 	return &claims, nil
 }
 
+// Introspect reports the state of an access or refresh token as described in RFC 7662.
+// Access tokens are checked with ValidateAccessToken, so signature, expiry and revocation
+// status all apply; refresh tokens are looked up in the repository.
+// The token type hint only decides which kind is tried first.
+// Confidential clients (resource servers) may introspect any token, while public clients
+// only learn about tokens issued to themselves. Any other token is reported as inactive.
+func (s *Service) Introspect(ctx context.Context, tokenValue, tokenTypeHint, clientID string, isConfidential bool) (*IntrospectionResponse, error) {
+	ctx, span := tracing.Start(ctx, "token.Service.Introspect")
+	defer span.End()
+
+	lookups := []func(context.Context, string) (*IntrospectionResponse, error){
+		s.introspectAccessToken,
+		s.introspectRefreshToken,
+	}
+	if tokenTypeHint == TokenTypeHintRefreshToken {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+
+	for _, lookup := range lookups {
+		response, err := lookup(ctx, tokenValue)
+		if err != nil {
+			return nil, err
+		}
+		if response == nil {
+			continue
+		}
+		if !isConfidential && response.ClientID != clientID {
+			break
+		}
+		return response, nil
+	}
+
+	return &IntrospectionResponse{Active: false}, nil
+}
+
 // ListTokens retrieves a paginated list of access tokens for a specific user.
 func (s *Service) ListTokens(ctx context.Context, userID uint, page, limit int) (*TokenListResponse, error) {
 	accessTokens, totalAccess, err := s.tokenRepo.FindAccessTokensByUserID(ctx, userID, page, limit)
@@ -350,7 +559,7 @@ func (s *Service) ListTokens(ctx context.Context, userID uint, page, limit int)
 		tokens = append(tokens, TokenInfo{
 			ID:        token.TokenID,
 			ClientID:  token.ClientID,
-			UserID:    token.UserID,
+			UserID:    userID,
 			Scope:     token.Scope,
 			ExpiresAt: token.ExpiresAt,
 			CreatedAt: token.CreatedAt,
@@ -368,6 +577,9 @@ func (s *Service) ListTokens(ctx context.Context, userID uint, page, limit int)
 
 // RevokeToken invalidates an access token if it belongs to the specified user.
 func (s *Service) RevokeToken(ctx context.Context, tokenID string, userID uint) error {
+	ctx, span := tracing.Start(ctx, "token.Service.RevokeToken")
+	defer span.End()
+
 	token, err := s.tokenRepo.FindAccessToken(ctx, tokenID)
 	if err != nil {
 		return err
@@ -377,41 +589,241 @@ func (s *Service) RevokeToken(ctx context.Context, tokenID string, userID uint)
 	}
 
 	// Check ownership
-	if token.UserID != userID {
+	if token.UserID == nil || *token.UserID != userID {
 		return errors.Forbidden(errors.ErrMsgNotAuthorizedToRevokeToken)
 	}
 
-	return s.tokenRepo.RevokeAccessToken(ctx, tokenID)
+	if err := s.tokenRepo.RevokeAccessToken(ctx, tokenID); err != nil {
+		return err
+	}
+	metrics.TokenRevoked(metrics.RevokedByUser)
+
+	return nil
+}
+
+// RevokeUserClientTokens invalidates every access and refresh token a client holds for a user,
+// for example when the user withdraws their consent for the client.
+func (s *Service) RevokeUserClientTokens(ctx context.Context, userID uint, clientID string) error {
+	ctx, span := tracing.Start(ctx, "token.Service.RevokeUserClientTokens")
+	defer span.End()
+
+	if err := s.tokenRepo.RevokeRefreshTokensByUserAndClient(ctx, userID, clientID); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeAccessTokensByUserAndClient(ctx, userID, clientID); err != nil {
+		return err
+	}
+	metrics.TokenRevoked(metrics.RevokedConsentWithdrawn)
+
+	return nil
+}
+
+// PurgeTokens deletes access and refresh tokens that have been expired for longer than
+// the retention window, and access tokens revoked more than that long after they were issued.
+// Revoked refresh tokens are kept until they expire, as they are needed to detect reuse.
+// Returns the number of tokens deleted.
+func (s *Service) PurgeTokens(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+
+	accessTokens, err := s.tokenRepo.DeleteAccessTokens(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshTokens, err := s.tokenRepo.DeleteExpiredRefreshTokens(ctx, before)
+	if err != nil {
+		return accessTokens, err
+	}
+
+	return accessTokens + refreshTokens, nil
 }
 
 // RevokeTokensByAuthCode invalidates all access tokens associated with a specific authorization code.
 func (s *Service) RevokeTokensByAuthCode(ctx context.Context, authCode string) error {
-	return s.tokenRepo.RevokeAccessTokensByAuthCode(ctx, authCode)
+	if err := s.tokenRepo.RevokeAccessTokensByAuthCode(ctx, authCode); err != nil {
+		return err
+	}
+	metrics.TokenRevoked(metrics.RevokedAuthCodeReuse)
+
+	return nil
 }
 
-// createAccessToken generates a new JWT access token with the specified claims.
-func (s *Service) createAccessToken(userID uint, clientID, scope string) (string, string, error) {
-	return s.createAccessTokenWithExpiry(userID, clientID, scope, s.accessExpiry)
+// introspectAccessToken returns the introspection response for an active JWT access token.
+// Returns nil without an error if the value is not an active access token.
+func (s *Service) introspectAccessToken(ctx context.Context, tokenValue string) (*IntrospectionResponse, error) {
+	claimsPtr, err := s.ValidateAccessToken(ctx, tokenValue)
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok && customErr.Status == http.StatusInternalServerError {
+			return nil, err
+		}
+		return nil, nil
+	}
+	claims := *claimsPtr
+
+	// Tokens issued for the web application are not OAuth access tokens
+	if issuer, _ := claims[jwtutil.ClaimKeyISS].(string); issuer != jwtutil.TokenIssuer {
+		return nil, nil
+	}
+
+	response := &IntrospectionResponse{
+		Active:    true,
+		TokenType: TokenTypeBearer,
+		Sub:       claimString(claims[jwtutil.ClaimKeySub]),
+	}
+	response.Scope, _ = claims[jwtutil.ClaimKeyScope].(string)
+	// Tokens issued before the client_id claim was added are identified by their audience
+	response.ClientID, _ = claims[jwtutil.ClaimKeyClientID].(string)
+	if response.ClientID == "" {
+		response.ClientID, _ = claims[jwtutil.ClaimKeyAud].(string)
+	}
+	response.Aud, _ = claims[jwtutil.ClaimKeyAud].(string)
+	response.Act, _ = claims[jwtutil.ClaimKeyAct].(map[string]interface{})
+	response.JTI, _ = claims[jwtutil.ClaimKeyJTI].(string)
+	if exp, ok := claims[jwtutil.ClaimKeyEXP].(float64); ok {
+		response.Exp = int64(exp)
+	}
+	if iat, ok := claims[jwtutil.ClaimKeyIAT].(float64); ok {
+		response.Iat = int64(iat)
+	}
+
+	return response, nil
+}
+
+// introspectRefreshToken returns the introspection response for an active refresh token.
+// Returns nil without an error if the value is not an active refresh token.
+func (s *Service) introspectRefreshToken(ctx context.Context, tokenValue string) (*IntrospectionResponse, error) {
+	token, err := s.findRefreshToken(ctx, tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.IsRevoked || time.Now().After(token.ExpiresAt) {
+		return nil, nil
+	}
+
+	return &IntrospectionResponse{
+		Active:    true,
+		Scope:     token.Scope,
+		ClientID:  token.ClientID,
+		Sub:       strconv.FormatUint(uint64(token.UserID), 10),
+		Exp:       token.ExpiresAt.Unix(),
+		Iat:       token.CreatedAt.Unix(),
+		JTI:       token.TokenID,
+		TokenType: TokenTypeHintRefreshToken,
+	}, nil
+}
+
+// validateExchangeToken validates an access token presented in a token exchange.
+// Anything other than an active OAuth access token is rejected with invalid_grant.
+func (s *Service) validateExchangeToken(ctx context.Context, tokenValue string) (jwt.MapClaims, error) {
+	claimsPtr, err := s.ValidateAccessToken(ctx, tokenValue)
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok && customErr.Status == http.StatusInternalServerError {
+			return nil, err
+		}
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+	claims := *claimsPtr
+
+	if issuer, _ := claims[jwtutil.ClaimKeyISS].(string); issuer != jwtutil.TokenIssuer {
+		return nil, errors.BadRequest(errors.ErrMsgInvalidGrant)
+	}
+
+	return claims, nil
+}
+
+// findRefreshToken looks up a refresh token by the digest of its presented value.
+// Returns nil without an error if no matching token exists.
+func (s *Service) findRefreshToken(ctx context.Context, tokenValue string) (*RefreshToken, error) {
+	return s.tokenRepo.FindRefreshTokenByHash(ctx, hash.Token(tokenValue, s.tokenHashPepper))
+}
+
+// revokeTokenFamily revokes every refresh token of a family and the access tokens issued with them.
+// Failures are ignored, as the caller rejects the request either way.
+func (s *Service) revokeTokenFamily(ctx context.Context, familyID string) {
+	if err := s.tokenRepo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		// Not critical, continue
+	}
+	if err := s.tokenRepo.RevokeAccessTokensByRefreshTokenFamily(ctx, familyID); err != nil {
+		// Not critical, continue
+	}
+	metrics.TokenRevoked(metrics.RevokedRefreshReuse)
+}
+
+// accessTokenParams describes an access token to be issued.
+type accessTokenParams struct {
+	UserID   *uint         // User the token is issued for; nil for client-only tokens
+	ClientID string        // Client the token is issued to
+	Audience string        // Audience of the token; defaults to the client ID
+	Scope    string        // Space-separated list of granted scopes
+	Expiry   time.Duration // Lifetime of the token
+	Actor    jwt.MapClaims // Acting party recorded in the act claim, if any (RFC 8693)
+}
+
+// issueAccessToken creates a JWT access token, stores it and caches it for quick validation.
+// Returns the signed token and its token ID.
+func (s *Service) issueAccessToken(ctx context.Context, params accessTokenParams) (string, string, error) {
+	userID, clientID, scope, expiry := params.UserID, params.ClientID, params.Scope, params.Expiry
+
+	accessToken, accessTokenID, err := s.createAccessTokenWithExpiry(params)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessTokenModel := &AccessToken{
+		TokenID:   accessTokenID,
+		TokenHash: hash.Token(accessToken, s.tokenHashPepper),
+		ClientID:  clientID,
+		UserID:    userID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: time.Now(),
+		IsRevoked: false,
+	}
+
+	if err := s.tokenRepo.SaveAccessToken(ctx, accessTokenModel); err != nil {
+		return "", "", err
+	}
+
+	// Cache the access token for quick validation
+	if err := s.cacheRepo.Set(ctx, CacheKeyAccessToken+accessTokenID, accessTokenModel, expiry); err != nil {
+		// Not critical, continue
+	}
+
+	return accessToken, accessTokenID, nil
 }
 
 // createAccessTokenWithExpiry generates a new JWT access token with the specified claims and expiry.
-func (s *Service) createAccessTokenWithExpiry(userID uint, clientID, scope string, expiry time.Duration) (string, string, error) {
+// The subject is the user ID for user tokens and the client ID for client-only tokens;
+// user tokens also carry the numeric user_id claim.
+func (s *Service) createAccessTokenWithExpiry(params accessTokenParams) (string, string, error) {
 	tokenID := uuid.New().String()
 	now := time.Now()
 
+	audience := params.Audience
+	if audience == "" {
+		audience = params.ClientID
+	}
+
 	claims := jwt.MapClaims{
-		jwtutil.ClaimKeyJTI:   tokenID,
-		jwtutil.ClaimKeySub:   userID,
-		jwtutil.ClaimKeyAud:   clientID,
-		jwtutil.ClaimKeyScope: scope,
-		jwtutil.ClaimKeyIAT:   now.Unix(),
-		jwtutil.ClaimKeyEXP:   now.Add(expiry).Unix(),
-		jwtutil.ClaimKeyISS:   jwtutil.TokenIssuer,
-		jwtutil.ClaimKeyType:  jwtutil.TokenTypeAccess,
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signedToken, err := token.SignedString(s.privateKey)
+		jwtutil.ClaimKeyJTI:      tokenID,
+		jwtutil.ClaimKeySub:      params.ClientID,
+		jwtutil.ClaimKeyAud:      audience,
+		jwtutil.ClaimKeyClientID: params.ClientID,
+		jwtutil.ClaimKeyScope:    params.Scope,
+		jwtutil.ClaimKeyIAT:      now.Unix(),
+		jwtutil.ClaimKeyEXP:      now.Add(params.Expiry).Unix(),
+		jwtutil.ClaimKeyISS:      jwtutil.TokenIssuer,
+		jwtutil.ClaimKeyType:     jwtutil.TokenTypeAccess,
+	}
+	if params.UserID != nil {
+		claims[jwtutil.ClaimKeySub] = strconv.FormatUint(uint64(*params.UserID), 10)
+		claims[jwtutil.ClaimKeyUserID] = *params.UserID
+	}
+	if params.Actor != nil {
+		claims[jwtutil.ClaimKeyAct] = params.Actor
+	}
+
+	signedToken, err := jwtutil.Sign(claims)
 	if err != nil {
 		return "", "", err
 	}
@@ -452,6 +864,18 @@ func (s *Service) getTokenIDFromJWT(tokenValue string) (string, error) {
 	return tokenID, nil
 }
 
+// claimString converts a string or numeric claim value to its string form.
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
 // isScopeSubset checks if the requested scope is a subset of the existing scope.
 func (s *Service) isScopeSubset(requested, existing string) bool {
 	requestedScopes := strings.Split(requested, " ")
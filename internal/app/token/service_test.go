@@ -0,0 +1,89 @@
+package token
+
+import (
+	"context"
+	"testing"
+
+	"github.com/verigate/verigate-server/internal/app/client"
+)
+
+// testClient returns an active confidential client with the given ID.
+func testClient(clientID string) *client.Client {
+	return &client.Client{
+		ClientID:       clientID,
+		IsActive:       true,
+		IsConfidential: true,
+		Scope:          "openid profile email",
+		GrantTypes:     []string{client.GrantTypeAuthorizationCode, client.GrantTypeRefreshToken},
+	}
+}
+
+func TestIntrospectRefreshToken(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid profile", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+
+	response, err := service.Introspect(ctx, tokens.RefreshToken, TokenTypeHintRefreshToken, "client-a", true)
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if !response.Active {
+		t.Fatal("issued refresh token introspected as inactive")
+	}
+	if response.TokenType != TokenTypeHintRefreshToken || response.ClientID != "client-a" || response.Sub != "42" || response.Scope != "openid profile" {
+		t.Errorf("unexpected introspection response: %+v", response)
+	}
+
+	// Without a hint the access token is tried first, and the refresh token is still found
+	response, err = service.Introspect(ctx, tokens.RefreshToken, "", "client-a", true)
+	if err != nil {
+		t.Fatalf("Introspect without hint: %v", err)
+	}
+	if !response.Active || response.TokenType != TokenTypeHintRefreshToken {
+		t.Errorf("refresh token without hint: %+v", response)
+	}
+}
+
+func TestIntrospectRevokedRefreshToken(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+	if err := service.RevokeRefreshToken(ctx, tokens.RefreshToken, "client-a"); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+
+	response, err := service.Introspect(ctx, tokens.RefreshToken, TokenTypeHintRefreshToken, "client-a", true)
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if response.Active {
+		t.Error("revoked refresh token introspected as active")
+	}
+}
+
+func TestIntrospectRefreshTokenOfOtherClient(t *testing.T) {
+	service, _ := newTestService(t, testClient("client-a"))
+	ctx := context.Background()
+
+	tokens, err := service.CreateTokens(ctx, 42, "client-a", "openid", "")
+	if err != nil {
+		t.Fatalf("CreateTokens: %v", err)
+	}
+
+	// Public clients only learn about their own tokens
+	response, err := service.Introspect(ctx, tokens.RefreshToken, TokenTypeHintRefreshToken, "client-b", false)
+	if err != nil {
+		t.Fatalf("Introspect: %v", err)
+	}
+	if response.Active {
+		t.Error("public client introspected another client's refresh token")
+	}
+}
@@ -66,3 +66,23 @@ type RefreshTokenResponse struct {
 	RefreshToken string    `json:"refresh_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
 }
+
+// SessionResponse describes one of the user's active logins.
+type SessionResponse struct {
+	ID         string    `json:"id"`           // Session identifier, used to revoke the session
+	Kind       string    `json:"kind"`         // "token" for a web app login, "browser" for a hosted login page session
+	Browser    string    `json:"browser"`      // Browser parsed from the user agent, e.g. "Firefox 125"
+	OS         string    `json:"os"`           // Operating system parsed from the user agent
+	Device     string    `json:"device"`       // Device type: desktop, mobile, tablet, bot or unknown
+	UserAgent  string    `json:"user_agent"`   // Raw user agent of the most recent use
+	IPAddress  string    `json:"ip_address"`   // IP address of the most recent use
+	CreatedAt  time.Time `json:"created_at"`   // When the user logged in
+	LastUsedAt time.Time `json:"last_used_at"` // When the session last logged in or refreshed its tokens
+	ExpiresAt  time.Time `json:"expires_at"`   // When the session ends unless it is used again
+	Current    bool      `json:"current"`      // Whether the request was made with this session
+}
+
+// SessionListResponse lists the user's active logins, most recently used first.
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
@@ -42,6 +42,9 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
 		protected.PUT("/me/password", h.ChangePassword)
 		protected.DELETE("/me", h.DeleteMe)
 		protected.POST("/logout", h.Logout) // Added
+		protected.GET("/me/sessions", h.ListSessions)
+		protected.DELETE("/me/sessions", h.RevokeOtherSessions)
+		protected.DELETE("/me/sessions/:session_id", h.RevokeSession)
 	}
 }
 
@@ -177,13 +180,61 @@ func (h *Handler) DeleteMe(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// Logout handles user logout requests by revoking all active refresh tokens.
-// This effectively terminates all active sessions for the user.
+// Logout handles user logout requests by revoking the session the request was made with.
+// The user's sessions on other devices stay active.
 // This endpoint is protected and only accessible to authenticated users.
 func (h *Handler) Logout(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	sessionID := c.GetString(middleware.ContextKeyWebSessionID)
 
-	if err := h.service.Logout(c.Request.Context(), userID); err != nil {
+	if err := h.service.Logout(c.Request.Context(), userID, sessionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions returns the authenticated user's active logins, with the device, browser,
+// IP address and times of each, and marks the one the request was made with.
+//
+// Route: GET /users/me/sessions
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	sessionID := c.GetString(middleware.ContextKeyWebSessionID)
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession logs the authenticated user out of one session, for example a lost phone.
+// Its refresh token stops working and its access tokens are rejected immediately.
+//
+// Route: DELETE /users/me/sessions/:session_id
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.service.RevokeSession(c.Request.Context(), userID, c.Param("session_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions logs the authenticated user out of every session except the current one.
+//
+// Route: DELETE /users/me/sessions
+func (h *Handler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	sessionID := c.GetString(middleware.ContextKeyWebSessionID)
+
+	if err := h.service.RevokeOtherSessions(c.Request.Context(), userID, sessionID); err != nil {
 		c.Error(err)
 		return
 	}
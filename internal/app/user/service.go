@@ -4,12 +4,15 @@ package user
 
 import (
 	"context"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/verigate/verigate-server/internal/app/auth"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 	"github.com/verigate/verigate-server/internal/pkg/utils/hash"
+	"github.com/verigate/verigate-server/internal/pkg/utils/useragent"
 )
 
 // Service handles user-related business logic including registration,
@@ -83,41 +86,57 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) (*UserRespo
 }
 
 func (s *Service) Login(ctx context.Context, req LoginRequest, userAgent, ipAddress string) (*LoginResponse, error) {
-	user, err := s.repo.FindByEmail(ctx, req.Email)
+	user, err := s.Authenticate(ctx, req.Email, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate tokens
+	tokenPair, err := s.authService.CreateTokenPair(ctx, user.ID, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		User:         *s.toResponse(user),
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresAt:    tokenPair.AccessTokenExpiresAt,
+	}, nil
+}
+
+// Authenticate verifies a user's email and password and records the login.
+// It is shared by the API login and the hosted login page of the authorization server.
+// Returns the user if the credentials are valid and the account is active.
+func (s *Service) Authenticate(ctx context.Context, email, password string) (*User, error) {
+	user, err := s.repo.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
+		metrics.Login(metrics.LoginFailure)
 		return nil, errors.Unauthorized(errors.ErrMsgInvalidCredentials)
 	}
 
 	// Verify password
-	if err := hash.CompareHashAndPassword(user.PasswordHash, req.Password); err != nil {
+	if err := hash.CompareHashAndPasswordContext(ctx, user.PasswordHash, password); err != nil {
+		metrics.Login(metrics.LoginFailure)
 		return nil, errors.Unauthorized(errors.ErrMsgInvalidCredentials)
 	}
 
 	// Check if user is active
 	if !user.IsActive {
+		metrics.Login(metrics.LoginFailure)
 		return nil, errors.Unauthorized(errors.ErrMsgAccountNotActive)
 	}
+	metrics.Login(metrics.LoginSuccess)
 
 	// Update last login
 	if err := s.repo.UpdateLastLogin(ctx, user.ID); err != nil {
 		// Not critical, continue
 	}
 
-	// Generate tokens
-	tokenPair, err := s.authService.CreateTokenPair(ctx, user.ID, userAgent, ipAddress)
-	if err != nil {
-		return nil, err
-	}
-
-	return &LoginResponse{
-		User:         *s.toResponse(user),
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresAt:    tokenPair.AccessTokenExpiresAt,
-	}, nil
+	return user, nil
 }
 
 func (s *Service) GetByID(ctx context.Context, id uint) (*UserResponse, error) {
@@ -166,7 +185,7 @@ func (s *Service) ChangePassword(ctx context.Context, id uint, req ChangePasswor
 	}
 
 	// Verify old password
-	if err := hash.CompareHashAndPassword(user.PasswordHash, req.OldPassword); err != nil {
+	if err := hash.CompareHashAndPasswordContext(ctx, user.PasswordHash, req.OldPassword); err != nil {
 		return errors.Unauthorized(errors.ErrMsgIncorrectPassword)
 	}
 
@@ -205,9 +224,77 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken, userAgent, ipA
 	}, nil
 }
 
-// Logout revokes all the user's refresh tokens
-func (s *Service) Logout(ctx context.Context, userID uint) error {
-	return s.authService.RevokeAllUserRefreshTokens(ctx, userID)
+// Logout ends the login session the request was made with, leaving the user's other
+// devices logged in. Tokens that carry no session ID predate session tracking;
+// for them all the user's refresh tokens are revoked, as before.
+func (s *Service) Logout(ctx context.Context, userID uint, sessionID string) error {
+	if sessionID == "" {
+		return s.authService.RevokeAllUserRefreshTokens(ctx, userID)
+	}
+
+	err := s.authService.RevokeSession(ctx, userID, sessionID)
+	if customErr, ok := err.(errors.CustomError); ok && customErr.Status == http.StatusNotFound {
+		// The session's refresh tokens are already gone
+		return nil
+	}
+	return err
+}
+
+// ListSessions returns the user's active logins with their parsed user agents.
+// The session the request was made with is marked as current.
+func (s *Service) ListSessions(ctx context.Context, userID uint, currentSessionID string) (*SessionListResponse, error) {
+	sessions, err := s.authService.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SessionListResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		ua := useragent.Parse(session.UserAgent)
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:         session.ID,
+			Kind:       session.Kind,
+			Browser:    ua.Browser,
+			OS:         ua.OS,
+			Device:     ua.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return response, nil
+}
+
+// RevokeSession logs the user out of one of their sessions.
+func (s *Service) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	return s.authService.RevokeSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the session the request was made with.
+// Without a current session, every session is revoked.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) error {
+	if currentSessionID == "" {
+		return s.authService.RevokeAllUserRefreshTokens(ctx, userID)
+	}
+
+	sessions, err := s.authService.ListSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		if err := s.authService.RevokeSession(ctx, userID, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (s *Service) toResponse(user *User) *UserResponse {
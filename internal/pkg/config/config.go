@@ -3,6 +3,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strconv"
 	"strings"
@@ -17,6 +18,44 @@ type Config struct {
 	JWTPublicKey               string
 	JWTAccessExpiry            string
 	JWTRefreshExpiry           string
+	JWTIDTokenExpiry           string
+	OIDCIssuer                 string
+	JWTSigningAlgorithms       []string
+	JWTKeyEncryptionKey        string
+	JWTKeyRotationInterval     string
+	JWTKeyRetention            string
+	JWTKeyRefreshInterval      string
+	AdminAPIKey                string
+	DeviceCodeExpiry           string
+	DevicePollInterval         string
+	DeviceVerificationURI      string
+	ClientJWKSCacheTTL         string
+	PARRequestExpiry           string
+	SessionCookieName          string
+	SessionLifetime            string
+	SessionCookieSecure        bool
+	TemplateDir                string
+	ConsentLifetime            string
+	TokenHashPepper            string
+	LegacyRefreshTokenLookup   bool
+	CodeCleanupInterval        string
+	TokenCleanupInterval       string
+	SessionCleanupInterval     string
+	TokenRetention             string
+	ServerReadTimeout          string
+	ServerReadHeaderTimeout    string
+	ServerWriteTimeout         string
+	ServerIdleTimeout          string
+	ServerMaxHeaderBytes       int
+	ServerShutdownDelay        string
+	ServerShutdownTimeout      string
+	TLSCertFile                string
+	TLSKeyFile                 string
+	TLSReloadInterval          string
+	HealthCheckTimeout         string
+	TracingEndpoint            string
+	TracingServiceName         string
+	TracingSampleRatio         float64
 	PostgresHost               string
 	PostgresPort               string
 	PostgresDB                 string
@@ -46,6 +85,7 @@ func Load() {
 		JWTPublicKey:     mustGetEnv("JWT_PUBLIC_KEY"),
 		JWTAccessExpiry:  getEnv("JWT_ACCESS_EXPIRY", "15m"),
 		JWTRefreshExpiry: getEnv("JWT_REFRESH_EXPIRY", "168h"),
+		JWTIDTokenExpiry: getEnv("JWT_ID_TOKEN_EXPIRY", "1h"),
 		PostgresHost:     getEnv("POSTGRES_HOST", "localhost"),
 		PostgresPort:     getEnv("POSTGRES_PORT", "5432"),
 		PostgresDB:       getEnv("POSTGRES_DB", "oauth_server"),
@@ -55,8 +95,75 @@ func Load() {
 		RedisPort:        getEnv("REDIS_PORT", "6379"),
 		RedisPassword:    getEnv("REDIS_PASSWORD", ""),
 		RedisDB:          getEnv("REDIS_DB", "0"),
+
+		// Signing key rotation; an empty encryption key keeps the static JWT_PRIVATE_KEY
+		JWTKeyEncryptionKey:    getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+		JWTKeyRotationInterval: getEnv("JWT_KEY_ROTATION_INTERVAL", "720h"),
+		JWTKeyRetention:        getEnv("JWT_KEY_RETENTION", "24h"),
+		JWTKeyRefreshInterval:  getEnv("JWT_KEY_REFRESH_INTERVAL", "1m"),
+
+		// Administrative API; admin endpoints are disabled when empty
+		AdminAPIKey: getEnv("ADMIN_API_KEY", ""),
+
+		// Device authorization grant; the verification URI defaults to the server's own endpoint
+		DeviceCodeExpiry:      getEnv("DEVICE_CODE_EXPIRY", "10m"),
+		DevicePollInterval:    getEnv("DEVICE_POLL_INTERVAL", "5s"),
+		DeviceVerificationURI: getEnv("DEVICE_VERIFICATION_URI", ""),
+
+		// How long JWK sets fetched from client jwks_uri endpoints are cached
+		ClientJWKSCacheTTL: getEnv("CLIENT_JWKS_CACHE_TTL", "1h"),
+
+		// Lifetime of pushed authorization requests, which must cover login and consent
+		PARRequestExpiry: getEnv("PAR_REQUEST_EXPIRY", "5m"),
+
+		// Browser login sessions of the hosted login page
+		SessionCookieName: getEnv("SESSION_COOKIE_NAME", "verigate_session"),
+		SessionLifetime:   getEnv("SESSION_LIFETIME", "24h"),
+
+		// Directory with templates and assets overriding the hosted login and consent pages
+		TemplateDir: getEnv("TEMPLATE_DIR", ""),
+
+		// How long a user's consent stays valid unless the client sets its own lifetime; 0 never expires
+		ConsentLifetime: getEnv("CONSENT_LIFETIME", "0"),
+
+		// Secret key for the digests of stored tokens
+		TokenHashPepper: getEnv("TOKEN_HASH_PEPPER", ""),
+
+		// Background maintenance jobs; an interval of 0 disables a job
+		CodeCleanupInterval:    getEnv("CODE_CLEANUP_INTERVAL", "10m"),
+		TokenCleanupInterval:   getEnv("TOKEN_CLEANUP_INTERVAL", "1h"),
+		SessionCleanupInterval: getEnv("SESSION_CLEANUP_INTERVAL", "1h"),
+		TokenRetention:         getEnv("TOKEN_RETENTION", "24h"),
+
+		// HTTP server timeouts; the shutdown delay keeps serving after SIGTERM while
+		// load balancers stop routing to the instance
+		ServerReadTimeout:       getEnv("SERVER_READ_TIMEOUT", "15s"),
+		ServerReadHeaderTimeout: getEnv("SERVER_READ_HEADER_TIMEOUT", "5s"),
+		ServerWriteTimeout:      getEnv("SERVER_WRITE_TIMEOUT", "30s"),
+		ServerIdleTimeout:       getEnv("SERVER_IDLE_TIMEOUT", "120s"),
+		ServerShutdownDelay:     getEnv("SERVER_SHUTDOWN_DELAY", "0s"),
+		ServerShutdownTimeout:   getEnv("SERVER_SHUTDOWN_TIMEOUT", "30s"),
+
+		// Native TLS, enabled when both files are set; the certificate is reloaded when they change
+		TLSCertFile:       getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:        getEnv("TLS_KEY_FILE", ""),
+		TLSReloadInterval: getEnv("TLS_RELOAD_INTERVAL", "1m"),
+
+		// Time the readiness probe gives each dependency check
+		HealthCheckTimeout: getEnv("HEALTH_CHECK_TIMEOUT", "2s"),
+
+		// OpenTelemetry tracing, enabled when an OTLP endpoint is set
+		TracingEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "verigate-server"),
 	}
 
+	// OpenID Connect issuer identifier, defaults to the local server address
+	AppConfig.OIDCIssuer = strings.TrimSuffix(getEnv("OIDC_ISSUER", "http://localhost:"+AppConfig.AppPort), "/")
+
+	// Signing algorithms with managed keys; the first one signs tokens by default.
+	// Left empty when unset, so that the key service can tell whether it was configured
+	AppConfig.JWTSigningAlgorithms = parseList(getEnv("JWT_SIGNING_ALGORITHMS", ""))
+
 	// Parse rate limit
 	rateLimit, err := strconv.Atoi(getEnv("RATE_LIMIT_REQUESTS_PER_MINUTE", "60"))
 	if err != nil {
@@ -64,6 +171,34 @@ func Load() {
 	}
 	AppConfig.RateLimitRequestsPerMinute = rateLimit
 
+	// Parse the maximum size of request headers
+	maxHeaderBytes, err := strconv.Atoi(getEnv("SERVER_MAX_HEADER_BYTES", "1048576"))
+	if err != nil || maxHeaderBytes <= 0 {
+		maxHeaderBytes = 1 << 20
+	}
+	AppConfig.ServerMaxHeaderBytes = maxHeaderBytes
+
+	// Parse the share of new traces that are recorded
+	sampleRatio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil || sampleRatio < 0 || sampleRatio > 1 {
+		sampleRatio = 1
+	}
+	AppConfig.TracingSampleRatio = sampleRatio
+
+	// Session cookies are only sent over HTTPS unless explicitly disabled for local development
+	secureCookies, err := strconv.ParseBool(getEnv("SESSION_COOKIE_SECURE", "true"))
+	if err != nil {
+		secureCookies = true
+	}
+	AppConfig.SessionCookieSecure = secureCookies
+
+	// Refresh tokens issued before the indexed token format are looked up by scanning until disabled
+	legacyLookup, err := strconv.ParseBool(getEnv("LEGACY_REFRESH_TOKEN_LOOKUP", "true"))
+	if err != nil {
+		legacyLookup = true
+	}
+	AppConfig.LegacyRefreshTokenLookup = legacyLookup
+
 	// Parse IP lists
 	AppConfig.IPWhitelist = parseIPList(getEnv("IP_WHITELIST", ""))
 	AppConfig.IPBlacklist = parseIPList(getEnv("IP_BLACKLIST", ""))
@@ -78,6 +213,16 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// ValidateTokenHashPepper reports an error if TOKEN_HASH_PEPPER is not set in production.
+// Without it stored token digests are not keyed, so a leaked database is enough to check guessed tokens.
+// Other environments may run without it.
+func (c *Config) ValidateTokenHashPepper() error {
+	if c.Environment == "production" && c.TokenHashPepper == "" {
+		return errors.New("TOKEN_HASH_PEPPER must be set when ENVIRONMENT is production")
+	}
+	return nil
+}
+
 // mustGetEnv retrieves a required value from environment variables.
 // If the environment variable is not set or is empty, the function panics.
 // This should be used only for configuration values that are essential
@@ -90,6 +235,17 @@ func mustGetEnv(key string) string {
 	return value
 }
 
+// parseList converts a comma-separated string into a slice of trimmed, non-empty values.
+func parseList(values string) []string {
+	var result []string
+	for _, value := range strings.Split(values, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
 // parseIPList converts a comma-separated string of IP addresses into a string slice.
 // This is used for parsing IP whitelist and blacklist environment variables.
 // Returns an empty slice if the input string is empty.
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestValidateTokenHashPepper(t *testing.T) {
+	tests := []struct {
+		environment string
+		pepper      string
+		wantErr     bool
+	}{
+		{"production", "", true},
+		{"production", "secret", false},
+		{"development", "", false},
+		{"staging", "", false},
+	}
+
+	for _, tt := range tests {
+		c := Config{Environment: tt.environment, TokenHashPepper: tt.pepper}
+		if err := c.ValidateTokenHashPepper(); (err != nil) != tt.wantErr {
+			t.Errorf("environment %q, pepper %q: got %v, want error %v", tt.environment, tt.pepper, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package postgres provides PostgreSQL database connection and repository implementations
+// for the Verigate Server application.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+
+	"github.com/verigate/verigate-server/internal/pkg/jobs"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// advisoryLockNamespace is prepended to lock names so that they do not collide with
+// advisory locks taken by other applications sharing the database.
+const advisoryLockNamespace = "verigate:"
+
+// advisoryLocker implements the jobs.Locker interface with PostgreSQL session-level advisory locks.
+// A lock is held on a dedicated connection for the duration of a job, so it is released
+// by the server if the process dies.
+type advisoryLocker struct {
+	db *sql.DB
+}
+
+// NewAdvisoryLocker creates a locker backed by PostgreSQL advisory locks.
+func NewAdvisoryLocker(db *sql.DB) jobs.Locker {
+	return &advisoryLocker{db: db}
+}
+
+// TryLock acquires the advisory lock derived from the name, without waiting for it.
+func (l *advisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	key := advisoryLockKey(name)
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, errors.Internal(errors.ErrMsgFailedToAcquireJobLock)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, errors.Internal(errors.ErrMsgFailedToAcquireJobLock)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// The job's context may be cancelled by now, but the lock must still be released
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			// Discard the connection instead of returning it to the pool, which ends the
+			// session and with it the lock
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+// advisoryLockKey maps a lock name to the 64-bit key of a PostgreSQL advisory lock.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(advisoryLockNamespace + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,168 @@
+// Package postgres provides PostgreSQL database connection and repository implementations
+// for the Verigate Server application.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/verigate/verigate-server/internal/app/audit"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// auditRepository implements the audit.Repository interface using PostgreSQL.
+// Entries are only ever appended; there is no update or delete.
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new PostgreSQL implementation of the audit log repository.
+func NewAuditRepository(db *sql.DB) audit.Repository {
+	return &auditRepository{db: db}
+}
+
+// Save appends an entry to the audit_logs table and sets its generated ID.
+func (r *auditRepository) Save(ctx context.Context, log *audit.Log) error {
+	var additionalData []byte
+	if log.AdditionalData != nil {
+		data, err := json.Marshal(log.AdditionalData)
+		if err != nil {
+			return errors.Internal(errors.ErrMsgFailedToSaveAuditLog)
+		}
+		additionalData = data
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_id, actor_type, action, resource_type, resource_id, description,
+			ip_address, user_agent, created_at, status, additional_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		log.ActorID,
+		nullString(log.ActorType),
+		log.Action,
+		log.ResourceType,
+		nullString(log.ResourceID),
+		nullString(log.Description),
+		nullString(log.IPAddress),
+		nullString(log.UserAgent),
+		log.CreatedAt,
+		log.Status,
+		additionalData,
+	).Scan(&log.ID)
+
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToSaveAuditLog)
+	}
+
+	return nil
+}
+
+// Find retrieves a page of audit log entries matching the filter, most recent first,
+// along with the total number of matching entries.
+func (r *auditRepository) Find(ctx context.Context, filter audit.ListRequest) ([]audit.Log, int64, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(column string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if filter.ActorID != nil {
+		addCondition("actor_id", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		addCondition("action", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		addCondition("resource_type", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		addCondition("resource_id", filter.ResourceID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Get total count
+	var total int64
+	countQuery := "SELECT COUNT(*) FROM audit_logs " + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Internal(errors.ErrMsgFailedToCountAuditLogs)
+	}
+
+	// Get entries with pagination
+	query := fmt.Sprintf(`
+		SELECT id, actor_id, actor_type, action, resource_type, resource_id, description,
+			ip_address, user_agent, created_at, status, additional_data
+		FROM audit_logs
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Internal(errors.ErrMsgFailedToFindAuditLogs)
+	}
+	defer rows.Close()
+
+	logs := []audit.Log{}
+	for rows.Next() {
+		var l audit.Log
+		var actorID sql.NullInt64
+		var actorType, resourceID, description, ipAddress, userAgent sql.NullString
+		var additionalData []byte
+		if err := rows.Scan(
+			&l.ID,
+			&actorID,
+			&actorType,
+			&l.Action,
+			&l.ResourceType,
+			&resourceID,
+			&description,
+			&ipAddress,
+			&userAgent,
+			&l.CreatedAt,
+			&l.Status,
+			&additionalData,
+		); err != nil {
+			return nil, 0, errors.Internal(errors.ErrMsgFailedToScanAuditLog)
+		}
+
+		if actorID.Valid {
+			id := uint(actorID.Int64)
+			l.ActorID = &id
+		}
+		l.ActorType = actorType.String
+		l.ResourceID = resourceID.String
+		l.Description = description.String
+		l.IPAddress = ipAddress.String
+		l.UserAgent = userAgent.String
+		if len(additionalData) > 0 {
+			if err := json.Unmarshal(additionalData, &l.AdditionalData); err != nil {
+				return nil, 0, errors.Internal(errors.ErrMsgFailedToScanAuditLog)
+			}
+		}
+		logs = append(logs, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Internal(errors.ErrMsgErrorIteratingAuditLogs)
+	}
+
+	return logs, total, nil
+}
+
+// nullString maps empty strings to SQL NULL for optional text columns.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -4,6 +4,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/lib/pq"
@@ -11,6 +12,20 @@ import (
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 )
 
+// clientColumns lists the columns selected for a client, in the order scanned by scanClient.
+const clientColumns = `id, client_id, client_secret, client_name, description, client_uri, logo_uri,
+		       redirect_uris, grant_types, response_types, scope, tos_uri, policy_uri,
+		       jwks_uri, jwks, contacts, software_id, software_version,
+		       is_confidential, is_active, created_at, updated_at, owner_id,
+		       id_token_signed_response_alg, pkce_required, token_endpoint_auth_method,
+		       access_token_lifetime, refresh_token_lifetime, token_exchange_policy,
+		       require_pushed_authorization_requests, required_scopes, consent_lifetime, is_trusted`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // clientRepository implements the client.Repository interface using PostgreSQL.
 type clientRepository struct {
 	db *sql.DB
@@ -31,13 +46,22 @@ func (r *clientRepository) Save(ctx context.Context, client *client.Client) erro
 			client_id, client_secret, client_name, description, client_uri, logo_uri,
 			redirect_uris, grant_types, response_types, scope, tos_uri, policy_uri,
 			jwks_uri, jwks, contacts, software_id, software_version,
-			is_confidential, is_active, created_at, updated_at, owner_id
+			is_confidential, is_active, created_at, updated_at, owner_id,
+			id_token_signed_response_alg, pkce_required, token_endpoint_auth_method,
+			access_token_lifetime, refresh_token_lifetime, token_exchange_policy,
+			require_pushed_authorization_requests, required_scopes, consent_lifetime, is_trusted
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+			$23, $24, $25, $26, $27, $28, $29, $30, $31, $32
 		) RETURNING id
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	exchangePolicy, err := json.Marshal(client.TokenExchangePolicy)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToCreateClient + ": " + err.Error())
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
 		client.ClientID,
 		client.ClientSecret,
 		client.ClientName,
@@ -60,6 +84,16 @@ func (r *clientRepository) Save(ctx context.Context, client *client.Client) erro
 		client.CreatedAt,
 		client.UpdatedAt,
 		client.OwnerID,
+		client.IDTokenSignedResponseAlg,
+		client.PKCERequired,
+		client.TokenEndpointAuthMethod,
+		client.AccessTokenLifetime,
+		client.RefreshTokenLifetime,
+		exchangePolicy,
+		client.RequirePushedAuthorizationRequests,
+		pq.Array(client.RequiredScopes),
+		client.ConsentLifetime,
+		client.IsTrusted,
 	).Scan(&client.ID)
 
 	if err != nil {
@@ -83,10 +117,19 @@ func (r *clientRepository) Update(ctx context.Context, client *client.Client) er
 			redirect_uris = $6, grant_types = $7, response_types = $8, scope = $9,
 			tos_uri = $10, policy_uri = $11, jwks_uri = $12, jwks = $13,
 			contacts = $14, software_id = $15, software_version = $16,
-			updated_at = $17
+			updated_at = $17, id_token_signed_response_alg = $18,
+			pkce_required = $19, token_endpoint_auth_method = $20,
+			access_token_lifetime = $21, refresh_token_lifetime = $22,
+			token_exchange_policy = $23, require_pushed_authorization_requests = $24,
+			required_scopes = $25, consent_lifetime = $26, is_trusted = $27
 		WHERE id = $1
 	`
 
+	exchangePolicy, err := json.Marshal(client.TokenExchangePolicy)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToUpdateClient + ": " + err.Error())
+	}
+
 	result, err := r.db.ExecContext(ctx, query,
 		client.ID,
 		client.ClientName,
@@ -105,6 +148,16 @@ func (r *clientRepository) Update(ctx context.Context, client *client.Client) er
 		client.SoftwareID,
 		client.SoftwareVersion,
 		client.UpdatedAt,
+		client.IDTokenSignedResponseAlg,
+		client.PKCERequired,
+		client.TokenEndpointAuthMethod,
+		client.AccessTokenLifetime,
+		client.RefreshTokenLifetime,
+		exchangePolicy,
+		client.RequirePushedAuthorizationRequests,
+		pq.Array(client.RequiredScopes),
+		client.ConsentLifetime,
+		client.IsTrusted,
 	)
 
 	if err != nil {
@@ -126,41 +179,12 @@ func (r *clientRepository) Update(ctx context.Context, client *client.Client) er
 // FindByID retrieves an OAuth client from the PostgreSQL database by its internal ID.
 // Returns the client if found, nil if the client doesn't exist, or an error if the query fails.
 func (r *clientRepository) FindByID(ctx context.Context, id uint) (*client.Client, error) {
-	var c client.Client
 	query := `
-		SELECT id, client_id, client_secret, client_name, description, client_uri, logo_uri,
-		       redirect_uris, grant_types, response_types, scope, tos_uri, policy_uri,
-		       jwks_uri, jwks, contacts, software_id, software_version,
-		       is_confidential, is_active, created_at, updated_at, owner_id
+		SELECT ` + clientColumns + `
 		FROM clients WHERE id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&c.ID,
-		&c.ClientID,
-		&c.ClientSecret,
-		&c.ClientName,
-		&c.Description,
-		&c.ClientURI,
-		&c.LogoURI,
-		pq.Array(&c.RedirectURIs),
-		pq.Array(&c.GrantTypes),
-		pq.Array(&c.ResponseTypes),
-		&c.Scope,
-		&c.TOSUri,
-		&c.PolicyURI,
-		&c.JwksURI,
-		&c.Jwks,
-		pq.Array(&c.Contacts),
-		&c.SoftwareID,
-		&c.SoftwareVersion,
-		&c.IsConfidential,
-		&c.IsActive,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-		&c.OwnerID,
-	)
-
+	c, err := scanClient(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -168,47 +192,18 @@ func (r *clientRepository) FindByID(ctx context.Context, id uint) (*client.Clien
 		return nil, errors.Internal(errors.ErrMsgFailedToGetClientByID + ": " + err.Error())
 	}
 
-	return &c, nil
+	return c, nil
 }
 
 // FindByClientID retrieves an OAuth client from the PostgreSQL database by its client ID (public identifier).
 // Returns the client if found, nil if the client doesn't exist, or an error if the query fails.
 func (r *clientRepository) FindByClientID(ctx context.Context, clientID string) (*client.Client, error) {
-	var c client.Client
 	query := `
-		SELECT id, client_id, client_secret, client_name, description, client_uri, logo_uri,
-		       redirect_uris, grant_types, response_types, scope, tos_uri, policy_uri,
-		       jwks_uri, jwks, contacts, software_id, software_version,
-		       is_confidential, is_active, created_at, updated_at, owner_id
+		SELECT ` + clientColumns + `
 		FROM clients WHERE client_id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, clientID).Scan(
-		&c.ID,
-		&c.ClientID,
-		&c.ClientSecret,
-		&c.ClientName,
-		&c.Description,
-		&c.ClientURI,
-		&c.LogoURI,
-		pq.Array(&c.RedirectURIs),
-		pq.Array(&c.GrantTypes),
-		pq.Array(&c.ResponseTypes),
-		&c.Scope,
-		&c.TOSUri,
-		&c.PolicyURI,
-		&c.JwksURI,
-		&c.Jwks,
-		pq.Array(&c.Contacts),
-		&c.SoftwareID,
-		&c.SoftwareVersion,
-		&c.IsConfidential,
-		&c.IsActive,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-		&c.OwnerID,
-	)
-
+	c, err := scanClient(r.db.QueryRowContext(ctx, query, clientID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -216,7 +211,7 @@ func (r *clientRepository) FindByClientID(ctx context.Context, clientID string)
 		return nil, errors.Internal(errors.ErrMsgFailedToGetClientByClientID + ": " + err.Error())
 	}
 
-	return &c, nil
+	return c, nil
 }
 
 // FindByOwnerID retrieves a paginated list of OAuth clients owned by a specific user.
@@ -234,10 +229,7 @@ func (r *clientRepository) FindByOwnerID(ctx context.Context, ownerID uint, page
 
 	// Get clients with pagination
 	query := `
-		SELECT id, client_id, client_secret, client_name, description, client_uri, logo_uri,
-		       redirect_uris, grant_types, response_types, scope, tos_uri, policy_uri,
-		       jwks_uri, jwks, contacts, software_id, software_version,
-		       is_confidential, is_active, created_at, updated_at, owner_id
+		SELECT ` + clientColumns + `
 		FROM clients
 		WHERE owner_id = $1
 		ORDER BY created_at DESC
@@ -252,35 +244,11 @@ func (r *clientRepository) FindByOwnerID(ctx context.Context, ownerID uint, page
 
 	var clients []client.Client
 	for rows.Next() {
-		var c client.Client
-		if err := rows.Scan(
-			&c.ID,
-			&c.ClientID,
-			&c.ClientSecret,
-			&c.ClientName,
-			&c.Description,
-			&c.ClientURI,
-			&c.LogoURI,
-			pq.Array(&c.RedirectURIs),
-			pq.Array(&c.GrantTypes),
-			pq.Array(&c.ResponseTypes),
-			&c.Scope,
-			&c.TOSUri,
-			&c.PolicyURI,
-			&c.JwksURI,
-			&c.Jwks,
-			pq.Array(&c.Contacts),
-			&c.SoftwareID,
-			&c.SoftwareVersion,
-			&c.IsConfidential,
-			&c.IsActive,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-			&c.OwnerID,
-		); err != nil {
+		c, err := scanClient(rows)
+		if err != nil {
 			return nil, 0, errors.Internal(errors.ErrMsgFailedToScanClientData + ": " + err.Error())
 		}
-		clients = append(clients, c)
+		clients = append(clients, *c)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -338,3 +306,51 @@ func (r *clientRepository) UpdateStatus(ctx context.Context, id uint, isActive b
 
 	return nil
 }
+
+// scanClient reads a client selected with clientColumns from a single row.
+func scanClient(row rowScanner) (*client.Client, error) {
+	var c client.Client
+	var exchangePolicy []byte
+	err := row.Scan(
+		&c.ID,
+		&c.ClientID,
+		&c.ClientSecret,
+		&c.ClientName,
+		&c.Description,
+		&c.ClientURI,
+		&c.LogoURI,
+		pq.Array(&c.RedirectURIs),
+		pq.Array(&c.GrantTypes),
+		pq.Array(&c.ResponseTypes),
+		&c.Scope,
+		&c.TOSUri,
+		&c.PolicyURI,
+		&c.JwksURI,
+		&c.Jwks,
+		pq.Array(&c.Contacts),
+		&c.SoftwareID,
+		&c.SoftwareVersion,
+		&c.IsConfidential,
+		&c.IsActive,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.OwnerID,
+		&c.IDTokenSignedResponseAlg,
+		&c.PKCERequired,
+		&c.TokenEndpointAuthMethod,
+		&c.AccessTokenLifetime,
+		&c.RefreshTokenLifetime,
+		&exchangePolicy,
+		&c.RequirePushedAuthorizationRequests,
+		pq.Array(&c.RequiredScopes),
+		&c.ConsentLifetime,
+		&c.IsTrusted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(exchangePolicy, &c.TokenExchangePolicy); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,46 @@
+// Package postgres provides PostgreSQL implementations of the application's repositories.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/jobs"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// jobRunRepository implements the jobs.RunRecorder interface using PostgreSQL.
+type jobRunRepository struct {
+	db *sql.DB
+}
+
+// NewJobRunRepository creates a PostgreSQL-based recorder of background job runs.
+func NewJobRunRepository(db *sql.DB) jobs.RunRecorder {
+	return &jobRunRepository{db: db}
+}
+
+// StartRun records the start of a run of the named job in the job_runs table, unless the job
+// last started less than interval ago. Times are taken from the database clock, so that
+// replicas with skewed clocks agree on when a run is due.
+func (r *jobRunRepository) StartRun(ctx context.Context, name string, interval time.Duration) (bool, error) {
+	query := `
+		INSERT INTO job_runs (name, last_run_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (name) DO UPDATE SET last_run_at = NOW()
+		WHERE job_runs.last_run_at <= NOW() - make_interval(secs => $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, name, interval.Seconds())
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRecordJobRun, err.Error()))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRecordJobRun, err.Error()))
+	}
+
+	return rows > 0, nil
+}
@@ -0,0 +1,213 @@
+// Package postgres provides PostgreSQL implementations of the application's repositories.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/verigate/verigate-server/internal/app/key"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// signingKeyColumns lists the columns selected for a signing key, in scan order.
+const signingKeyColumns = `id, kid, algorithm, status, encrypted_private_key, public_key,
+	created_at, activated_at, deactivated_at, retired_at`
+
+// keyRepository implements the key.Repository interface using PostgreSQL.
+type keyRepository struct {
+	db *sql.DB
+}
+
+// NewKeyRepository creates a new PostgreSQL-based signing key repository.
+// It takes a database connection and returns a key.Repository interface.
+func NewKeyRepository(db *sql.DB) key.Repository {
+	return &keyRepository{db: db}
+}
+
+// Save inserts a new signing key into the PostgreSQL database.
+// Returns a conflict error if a key with the same kid exists, or if the key
+// would be a second active or next key for its algorithm.
+func (r *keyRepository) Save(ctx context.Context, k *key.SigningKey) error {
+	query := `
+		INSERT INTO signing_keys (kid, algorithm, status, encrypted_private_key, public_key, created_at, activated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		k.KID,
+		k.Algorithm,
+		k.Status,
+		k.EncryptedPrivateKey,
+		k.PublicKey,
+		k.CreatedAt,
+		k.ActivatedAt,
+	).Scan(&k.ID)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.Conflict(fmt.Sprintf("Signing key '%s' conflicts with an existing key", k.KID))
+		}
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveSigningKey, err.Error()))
+	}
+
+	return nil
+}
+
+// FindUsable retrieves all signing keys that have not been retired.
+// Active keys come first, followed by next keys and previous keys, newest first.
+func (r *keyRepository) FindUsable(ctx context.Context) ([]key.SigningKey, error) {
+	query := `
+		SELECT ` + signingKeyColumns + `
+		FROM signing_keys
+		WHERE status <> $1
+		ORDER BY CASE status WHEN 'active' THEN 0 WHEN 'next' THEN 1 ELSE 2 END, created_at DESC
+	`
+
+	return r.query(ctx, query, key.StatusRetired)
+}
+
+// FindAll retrieves all signing keys, newest first.
+func (r *keyRepository) FindAll(ctx context.Context) ([]key.SigningKey, error) {
+	query := `
+		SELECT ` + signingKeyColumns + `
+		FROM signing_keys
+		ORDER BY created_at DESC
+	`
+
+	return r.query(ctx, query)
+}
+
+// Rotate replaces the active key of an algorithm within a single transaction.
+// The active row is locked first so that concurrent rotations from several
+// instances are serialized; the loser sees a different active key and gets a conflict.
+func (r *keyRepository) Rotate(ctx context.Context, algorithm, expectedActiveKID string, newNext *key.SigningKey, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRotateSigningKey, err.Error()))
+	}
+	defer tx.Rollback()
+
+	var activeKID string
+	err = tx.QueryRowContext(ctx,
+		`SELECT kid FROM signing_keys WHERE algorithm = $1 AND status = $2 FOR UPDATE`,
+		algorithm, key.StatusActive,
+	).Scan(&activeKID)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRotateSigningKey, err.Error()))
+	}
+	if activeKID != expectedActiveKID {
+		return errors.Conflict(errors.ErrMsgSigningKeyRotatedElsewhere)
+	}
+
+	// Demote the current active key; it stays verifiable until retired
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE signing_keys SET status = $1, deactivated_at = $2 WHERE algorithm = $3 AND status = $4`,
+		key.StatusPrevious, now, algorithm, key.StatusActive,
+	); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRotateSigningKey, err.Error()))
+	}
+
+	// Promote the next key, if there is one
+	result, err := tx.ExecContext(ctx,
+		`UPDATE signing_keys SET status = $1, activated_at = $2 WHERE algorithm = $3 AND status = $4`,
+		key.StatusActive, now, algorithm, key.StatusNext,
+	)
+	if err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRotateSigningKey, err.Error()))
+	}
+
+	promoted, err := result.RowsAffected()
+	if err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToGetAffectedRows, err.Error()))
+	}
+
+	// Without a published next key, the new key has to be activated directly
+	if promoted == 0 {
+		newNext.Status = key.StatusActive
+		newNext.ActivatedAt = &now
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO signing_keys (kid, algorithm, status, encrypted_private_key, public_key, created_at, activated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		newNext.KID,
+		newNext.Algorithm,
+		newNext.Status,
+		newNext.EncryptedPrivateKey,
+		newNext.PublicKey,
+		newNext.CreatedAt,
+		newNext.ActivatedAt,
+	).Scan(&newNext.ID)
+	if err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveSigningKey, err.Error()))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRotateSigningKey, err.Error()))
+	}
+
+	return nil
+}
+
+// RetirePrevious retires previous keys that were deactivated before the given time.
+// The encrypted private key is cleared, since a retired key is never used again.
+func (r *keyRepository) RetirePrevious(ctx context.Context, deactivatedBefore time.Time) (int64, error) {
+	query := `
+		UPDATE signing_keys
+		SET status = $1, retired_at = $2, encrypted_private_key = NULL
+		WHERE status = $3 AND deactivated_at < $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, key.StatusRetired, time.Now(), key.StatusPrevious, deactivatedBefore)
+	if err != nil {
+		return 0, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRetireSigningKeys, err.Error()))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToGetAffectedRows, err.Error()))
+	}
+
+	return rowsAffected, nil
+}
+
+// query runs a signing key query and scans all resulting rows.
+func (r *keyRepository) query(ctx context.Context, query string, args ...interface{}) ([]key.SigningKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSigningKeys, err.Error()))
+	}
+	defer rows.Close()
+
+	var keys []key.SigningKey
+	for rows.Next() {
+		var k key.SigningKey
+		if err := rows.Scan(
+			&k.ID,
+			&k.KID,
+			&k.Algorithm,
+			&k.Status,
+			&k.EncryptedPrivateKey,
+			&k.PublicKey,
+			&k.CreatedAt,
+			&k.ActivatedAt,
+			&k.DeactivatedAt,
+			&k.RetiredAt,
+		); err != nil {
+			return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToScanSigningKey, err.Error()))
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgErrorIteratingSigningKeys, err.Error()))
+	}
+
+	return keys, nil
+}
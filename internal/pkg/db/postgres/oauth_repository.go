@@ -29,8 +29,9 @@ func (r *oauthRepository) SaveAuthorizationCode(ctx context.Context, code *oauth
 	query := `
 		INSERT INTO authorization_codes (
 			code, client_id, user_id, redirect_uri, scope,
-			code_challenge, code_challenge_method, expires_at, created_at, is_used
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			code_challenge, code_challenge_method, nonce, auth_time,
+			expires_at, created_at, is_used
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
@@ -42,6 +43,8 @@ func (r *oauthRepository) SaveAuthorizationCode(ctx context.Context, code *oauth
 		code.Scope,
 		code.CodeChallenge,
 		code.CodeChallengeMethod,
+		code.Nonce,
+		code.AuthTime,
 		code.ExpiresAt,
 		code.CreatedAt,
 		code.IsUsed,
@@ -62,7 +65,8 @@ func (r *oauthRepository) FindAuthorizationCode(ctx context.Context, code string
 	var ac oauth.AuthorizationCode
 	query := `
 		SELECT id, code, client_id, user_id, redirect_uri, scope,
-		       code_challenge, code_challenge_method, expires_at, created_at, is_used
+		       code_challenge, code_challenge_method, COALESCE(nonce, ''),
+		       COALESCE(auth_time, created_at), expires_at, created_at, is_used
 		FROM authorization_codes
 		WHERE code = $1
 	`
@@ -76,6 +80,8 @@ func (r *oauthRepository) FindAuthorizationCode(ctx context.Context, code string
 		&ac.Scope,
 		&ac.CodeChallenge,
 		&ac.CodeChallengeMethod,
+		&ac.Nonce,
+		&ac.AuthTime,
 		&ac.ExpiresAt,
 		&ac.CreatedAt,
 		&ac.IsUsed,
@@ -119,18 +125,24 @@ func (r *oauthRepository) MarkCodeAsUsed(ctx context.Context, code string) error
 	return nil
 }
 
-func (r *oauthRepository) DeleteExpiredCodes(ctx context.Context) error {
+// DeleteExpiredCodes deletes authorization codes that expired before the given time.
+func (r *oauthRepository) DeleteExpiredCodes(ctx context.Context, before time.Time) (int64, error) {
 	query := `
 		DELETE FROM authorization_codes
 		WHERE expires_at < $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query, time.Now())
+	result, err := r.db.ExecContext(ctx, query, before)
 	if err != nil {
-		return errors.Internal(errors.ErrMsgFailedToDeleteExpiredCodes)
+		return 0, errors.Internal(errors.ErrMsgFailedToDeleteExpiredCodes)
 	}
 
-	return nil
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Internal(errors.ErrMsgFailedToGetAffectedRows)
+	}
+
+	return rows, nil
 }
 
 func (r *oauthRepository) SaveUserConsent(ctx context.Context, consent *oauth.UserConsent) error {
@@ -185,6 +197,44 @@ func (r *oauthRepository) FindUserConsent(ctx context.Context, userID uint, clie
 	return &uc, nil
 }
 
+// FindUserConsentsByUserID retrieves all consent records of a user, most recently updated first.
+// Returns an empty list if the user has not authorized any client.
+func (r *oauthRepository) FindUserConsentsByUserID(ctx context.Context, userID uint) ([]oauth.UserConsent, error) {
+	query := `
+		SELECT id, user_id, client_id, scope, created_at, updated_at
+		FROM user_consents
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToListUserConsents, err.Error()))
+	}
+	defer rows.Close()
+
+	consents := []oauth.UserConsent{}
+	for rows.Next() {
+		var uc oauth.UserConsent
+		if err := rows.Scan(
+			&uc.ID,
+			&uc.UserID,
+			&uc.ClientID,
+			&uc.Scope,
+			&uc.CreatedAt,
+			&uc.UpdatedAt,
+		); err != nil {
+			return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToListUserConsents, err.Error()))
+		}
+		consents = append(consents, uc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToListUserConsents, err.Error()))
+	}
+
+	return consents, nil
+}
+
 // UpdateUserConsent modifies an existing user consent record.
 // This is typically called when a user grants additional permissions to a client.
 // Returns NotFound error if no consent exists, or Internal error if the update fails.
@@ -3,17 +3,26 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/verigate/verigate-server/internal/pkg/config"
 
+	"github.com/XSAM/otelsql"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+// migrationsSource is the location of the migration files applied at startup.
+const migrationsSource = "file://migrations/postgres"
+
 // NewConnection establishes a new PostgreSQL database connection using configuration settings.
 // It connects to the database, validates the connection with a ping, and runs any pending migrations.
 // Returns the database connection pool or an error if the connection or migrations fail.
@@ -26,7 +35,7 @@ func NewConnection() (*sql.DB, error) {
 		config.AppConfig.PostgresDB,
 	)
 
-	db, err := sql.Open("postgres", dsn)
+	db, err := Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +51,18 @@ func NewConnection() (*sql.DB, error) {
 	return db, nil
 }
 
+// Open opens a database handle with the given driver whose queries are traced with their
+// statements; the values of their parameters are not recorded.
+func Open(driverName, dsn string) (*sql.DB, error) {
+	return otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+}
+
 // runMigrations applies any pending database migrations to ensure
 // the database schema is up to date with the expected structure.
 // It uses the golang-migrate library to track and apply migrations.
@@ -52,7 +73,7 @@ func runMigrations(db *sql.DB) error {
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations/postgres",
+		migrationsSource,
 		"postgres",
 		driver,
 	)
@@ -66,3 +87,52 @@ func runMigrations(db *sql.DB) error {
 
 	return nil
 }
+
+// LatestMigrationVersion returns the version of the newest migration file,
+// which is the schema version this build of the application expects.
+func LatestMigrationVersion() (uint, error) {
+	src, err := source.Open(migrationsSource)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		version = next
+	}
+}
+
+// CheckSchemaVersion verifies that the database schema is at least at the expected version
+// and that no migration failed halfway. A newer schema is accepted, since during a rolling
+// update instances of the previous release keep running against the migrated database.
+func CheckSchemaVersion(ctx context.Context, db *sql.DB, expected uint) error {
+	var version uint
+	var dirty bool
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no migrations have been applied")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the schema dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema version %d is older than the expected version %d", version, expected)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/verigate/verigate-server/internal/app/token"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
@@ -238,6 +239,41 @@ func (r *tokenRepository) RevokeAccessTokensByClientID(ctx context.Context, clie
 	return nil
 }
 
+// RevokeAccessTokensByUserAndClient revokes all active access tokens issued to a client for a user.
+func (r *tokenRepository) RevokeAccessTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error {
+	query := `
+		UPDATE access_tokens
+		SET is_revoked = true
+		WHERE user_id = $1 AND client_id = $2 AND is_revoked = false
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, clientID)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToRevokeAccessTokens)
+	}
+
+	return nil
+}
+
+// RevokeAccessTokensByRefreshTokenFamily revokes the active access tokens issued together with
+// any refresh token of a family.
+func (r *tokenRepository) RevokeAccessTokensByRefreshTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE access_tokens
+		SET is_revoked = true
+		WHERE is_revoked = false AND token_id IN (
+			SELECT access_token_id FROM refresh_tokens WHERE family_id = $1
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToRevokeAccessTokens)
+	}
+
+	return nil
+}
+
 func (r *tokenRepository) RevokeAccessTokensByAuthCode(ctx context.Context, authCode string) error {
 	// This would typically involve a join with authorization_codes table
 	// For simplicity, we'll assume we track this relationship differently
@@ -272,16 +308,39 @@ func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID stri
 	return isRevoked, nil
 }
 
+// DeleteAccessTokens deletes access tokens that expired before the given time, and revoked
+// tokens issued before it. Missing tokens are reported as revoked, so deleting revoked
+// tokens early does not make them valid again.
+func (r *tokenRepository) DeleteAccessTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM access_tokens
+		WHERE expires_at < $1 OR (is_revoked = true AND created_at < $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, errors.Internal(errors.ErrMsgFailedToDeleteExpiredTokens)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Internal(errors.ErrMsgFailedToGetAffectedRows)
+	}
+
+	return rows, nil
+}
+
 func (r *tokenRepository) SaveRefreshToken(ctx context.Context, token *token.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (token_id, token_hash, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO refresh_tokens (token_id, token_hash, family_id, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
 	err := r.db.QueryRowContext(ctx, query,
 		token.TokenID,
 		token.TokenHash,
+		token.FamilyID,
 		token.AccessTokenID,
 		token.ClientID,
 		token.UserID,
@@ -301,7 +360,7 @@ func (r *tokenRepository) SaveRefreshToken(ctx context.Context, token *token.Ref
 func (r *tokenRepository) FindRefreshToken(ctx context.Context, tokenID string) (*token.RefreshToken, error) {
 	var t token.RefreshToken
 	query := `
-		SELECT id, token_id, token_hash, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
+		SELECT id, token_id, token_hash, family_id, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
 		FROM refresh_tokens
 		WHERE token_id = $1
 	`
@@ -310,6 +369,7 @@ func (r *tokenRepository) FindRefreshToken(ctx context.Context, tokenID string)
 		&t.ID,
 		&t.TokenID,
 		&t.TokenHash,
+		&t.FamilyID,
 		&t.AccessTokenID,
 		&t.ClientID,
 		&t.UserID,
@@ -332,7 +392,7 @@ func (r *tokenRepository) FindRefreshToken(ctx context.Context, tokenID string)
 func (r *tokenRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*token.RefreshToken, error) {
 	var t token.RefreshToken
 	query := `
-		SELECT id, token_id, token_hash, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
+		SELECT id, token_id, token_hash, family_id, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
 		FROM refresh_tokens
 		WHERE token_hash = $1
 	`
@@ -341,6 +401,7 @@ func (r *tokenRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash
 		&t.ID,
 		&t.TokenID,
 		&t.TokenHash,
+		&t.FamilyID,
 		&t.AccessTokenID,
 		&t.ClientID,
 		&t.UserID,
@@ -372,7 +433,7 @@ func (r *tokenRepository) FindRefreshTokensByUserID(ctx context.Context, userID
 
 	// Get tokens with pagination
 	query := `
-		SELECT id, token_id, token_hash, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
+		SELECT id, token_id, token_hash, family_id, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
 		FROM refresh_tokens
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -392,6 +453,7 @@ func (r *tokenRepository) FindRefreshTokensByUserID(ctx context.Context, userID
 			&t.ID,
 			&t.TokenID,
 			&t.TokenHash,
+			&t.FamilyID,
 			&t.AccessTokenID,
 			&t.ClientID,
 			&t.UserID,
@@ -424,7 +486,7 @@ func (r *tokenRepository) FindRefreshTokensByClientID(ctx context.Context, clien
 
 	// Get tokens with pagination
 	query := `
-		SELECT id, token_id, token_hash, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
+		SELECT id, token_id, token_hash, family_id, access_token_id, client_id, user_id, scope, expires_at, created_at, is_revoked
 		FROM refresh_tokens
 		WHERE client_id = $1
 		ORDER BY created_at DESC
@@ -444,6 +506,7 @@ func (r *tokenRepository) FindRefreshTokensByClientID(ctx context.Context, clien
 			&t.ID,
 			&t.TokenID,
 			&t.TokenHash,
+			&t.FamilyID,
 			&t.AccessTokenID,
 			&t.ClientID,
 			&t.UserID,
@@ -488,6 +551,44 @@ func (r *tokenRepository) RevokeRefreshToken(ctx context.Context, tokenID string
 	return nil
 }
 
+// RotateRefreshToken revokes a refresh token only if it is still active, so that of two
+// concurrent refreshes with the same token exactly one succeeds.
+func (r *tokenRepository) RotateRefreshToken(ctx context.Context, tokenID string) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET is_revoked = true
+		WHERE token_id = $1 AND is_revoked = false
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tokenID)
+	if err != nil {
+		return false, errors.Internal(errors.ErrMsgFailedToRevokeRefreshToken)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Internal(errors.ErrMsgFailedToGetAffectedRows)
+	}
+
+	return rows > 0, nil
+}
+
+// RevokeRefreshTokenFamily revokes every active refresh token of a family.
+func (r *tokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET is_revoked = true
+		WHERE family_id = $1 AND is_revoked = false
+	`
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToRevokeRefreshTokens)
+	}
+
+	return nil
+}
+
 func (r *tokenRepository) RevokeRefreshTokensByUserID(ctx context.Context, userID uint) error {
 	query := `
 		UPDATE refresh_tokens
@@ -518,6 +619,22 @@ func (r *tokenRepository) RevokeRefreshTokensByClientID(ctx context.Context, cli
 	return nil
 }
 
+// RevokeRefreshTokensByUserAndClient revokes all active refresh tokens issued to a client for a user.
+func (r *tokenRepository) RevokeRefreshTokensByUserAndClient(ctx context.Context, userID uint, clientID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET is_revoked = true
+		WHERE user_id = $1 AND client_id = $2 AND is_revoked = false
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, clientID)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToRevokeRefreshTokens)
+	}
+
+	return nil
+}
+
 func (r *tokenRepository) RevokeRefreshTokensByAccessTokenID(ctx context.Context, accessTokenID string) error {
 	query := `
 		UPDATE refresh_tokens
@@ -532,3 +649,25 @@ func (r *tokenRepository) RevokeRefreshTokensByAccessTokenID(ctx context.Context
 
 	return nil
 }
+
+// DeleteExpiredRefreshTokens deletes refresh tokens that expired before the given time.
+// Revoked tokens are kept until they expire, so that the reuse of a rotated-out token
+// can still be detected.
+func (r *tokenRepository) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM refresh_tokens
+		WHERE expires_at < $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, errors.Internal(errors.ErrMsgFailedToDeleteExpiredTokens)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Internal(errors.ErrMsgFailedToGetAffectedRows)
+	}
+
+	return rows, nil
+}
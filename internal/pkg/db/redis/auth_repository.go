@@ -17,8 +17,9 @@ import (
 
 // Constants for Redis key prefixes to avoid collisions and organize data
 const (
-	refreshTokenKeyPrefix = "auth:refresh_token:" // Prefix for individual token storage
-	userTokensKeyPrefix   = "auth:user_tokens:"   // Prefix for user's token collection
+	refreshTokenKeyPrefix   = "auth:refresh_token:"   // Prefix for individual token storage
+	userTokensKeyPrefix     = "auth:user_tokens:"     // Prefix for user's token collection
+	revokedSessionKeyPrefix = "auth:revoked_session:" // Prefix for marks of revoked login sessions
 )
 
 // authRepository implements the auth.Repository interface using Redis for storage.
@@ -84,8 +85,9 @@ func (r *authRepository) FindRefreshToken(ctx context.Context, tokenID string) (
 	return &token, nil
 }
 
-// FindRefreshTokenByToken looks up a refresh token by its plain text token value.
-// This is a more expensive operation as it requires scanning all tokens and comparing hashes.
+// FindRefreshTokenByToken looks up a legacy refresh token by its plain text token value.
+// This is a more expensive operation as it requires scanning all tokens and comparing hashes,
+// which is why current tokens carry their ID instead.
 // Returns nil if the token doesn't exist.
 func (r *authRepository) FindRefreshTokenByToken(ctx context.Context, plainTextToken string) (*auth.RefreshToken, error) {
 	// Scan all token keys
@@ -161,6 +163,71 @@ func (r *authRepository) RevokeRefreshToken(ctx context.Context, tokenID string)
 	return r.client.Set(ctx, tokenKey, updatedData, ttl).Err()
 }
 
+// FindUserRefreshTokens retrieves all unexpired refresh tokens of a user, including revoked ones.
+// IDs of tokens that have expired are removed from the user's token set on the way.
+func (r *authRepository) FindUserRefreshTokens(ctx context.Context, userID uint) ([]auth.RefreshToken, error) {
+	userTokensKey := userTokensKeyPrefix + fmt.Sprintf("%d", userID)
+
+	tokenIDs, err := r.client.SMembers(ctx, userTokensKey).Result()
+	if err != nil && err != redis.Nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToGetRefreshTokens, err.Error()))
+	}
+	if len(tokenIDs) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		keys[i] = refreshTokenKeyPrefix + tokenID
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToGetRefreshTokens, err.Error()))
+	}
+
+	var tokens []auth.RefreshToken
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, tokenIDs[i])
+			continue
+		}
+
+		var token auth.RefreshToken
+		if err := json.Unmarshal([]byte(data), &token); err != nil {
+			return nil, errors.Internal(errors.ErrMsgFailedToUnmarshalRefreshToken)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, userTokensKey, expired...).Err(); err != nil {
+			// Not critical, continue
+		}
+	}
+
+	return tokens, nil
+}
+
+// MarkSessionRevoked stores a mark for a revoked login session that expires after ttl.
+func (r *authRepository) MarkSessionRevoked(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, revokedSessionKeyPrefix+sessionID, 1, ttl).Err(); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRevokeWebSession, err.Error()))
+	}
+	return nil
+}
+
+// IsSessionRevoked checks whether a mark exists for a revoked login session.
+func (r *authRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	count, err := r.client.Exists(ctx, revokedSessionKeyPrefix+sessionID).Result()
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToCheckWebSession, err.Error()))
+	}
+	return count > 0, nil
+}
+
 // RevokeAllUserRefreshTokens revokes all refresh tokens for a user.
 func (r *authRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID uint) error {
 	userTokensKey := userTokensKeyPrefix + fmt.Sprintf("%d", userID)
@@ -182,11 +249,72 @@ func (r *authRepository) RevokeAllUserRefreshTokens(ctx context.Context, userID
 	return nil
 }
 
-// DeleteExpiredTokens removes expired tokens.
-// Redis automatically removes expired keys, so this is a no-op.
-func (r *authRepository) DeleteExpiredTokens(ctx context.Context) error {
-	// Redis handles key expiration automatically
-	return nil
+// DeleteExpiredTokens removes the IDs of expired tokens from the users' token sets.
+// Redis expires the tokens themselves, but a set only expires with the last of its tokens,
+// so the sets of active users keep growing until they are pruned.
+// Returns the number of IDs removed.
+func (r *authRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	var removed int64
+	var cursor uint64
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, userTokensKeyPrefix+"*", 100).Result()
+		if err != nil {
+			return removed, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToPruneUserTokenSets, err.Error()))
+		}
+
+		for _, userTokensKey := range keys {
+			count, err := r.pruneUserTokenSet(ctx, userTokensKey)
+			if err != nil {
+				return removed, err
+			}
+			removed += count
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return removed, nil
+}
+
+// pruneUserTokenSet removes the IDs of tokens that no longer exist from a user's token set.
+func (r *authRepository) pruneUserTokenSet(ctx context.Context, userTokensKey string) (int64, error) {
+	tokenIDs, err := r.client.SMembers(ctx, userTokensKey).Result()
+	if err != nil && err != redis.Nil {
+		return 0, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToPruneUserTokenSets, err.Error()))
+	}
+	if len(tokenIDs) == 0 {
+		return 0, nil
+	}
+
+	pipe := r.client.Pipeline()
+	exists := make([]*redis.IntCmd, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		exists[i] = pipe.Exists(ctx, refreshTokenKeyPrefix+tokenID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToPruneUserTokenSets, err.Error()))
+	}
+
+	var expired []interface{}
+	for i, cmd := range exists {
+		if cmd.Val() == 0 {
+			expired = append(expired, tokenIDs[i])
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	removed, err := r.client.SRem(ctx, userTokensKey, expired...).Result()
+	if err != nil {
+		return 0, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToPruneUserTokenSets, err.Error()))
+	}
+
+	return removed, nil
 }
 
 // IsRefreshTokenRevoked checks if a refresh token has been revoked.
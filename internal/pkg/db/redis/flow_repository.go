@@ -0,0 +1,272 @@
+// Package redis provides Redis-based implementations of the application's repositories.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/app/oauth"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+// Constants for Redis key prefixes used by authorization flows
+const (
+	deviceCodeKeyPrefix = "oauth:device_code:"   // Prefix for device authorizations by device code
+	userCodeKeyPrefix   = "oauth:user_code:"     // Prefix for the user code to device code index
+	devicePollKeyPrefix = "oauth:device_poll:"   // Prefix for the last poll marker of a device code
+	assertionKeyPrefix  = "oauth:assertion:"     // Prefix for used client assertion token IDs
+	parKeyPrefix        = "oauth:par:"           // Prefix for pushed authorization requests by request URI
+	sessionKeyPrefix    = "oauth:authz_session:" // Prefix for authorization sessions awaiting consent
+
+	// deviceAuthorizationGracePeriod keeps expired device authorizations around for a while,
+	// so that polling devices get expired_token rather than invalid_grant
+	deviceAuthorizationGracePeriod = 10 * time.Minute
+
+	// maxDeviceAuthorizationUpdateAttempts bounds the retries of an update that raced with
+	// another change of the same device authorization
+	maxDeviceAuthorizationUpdateAttempts = 5
+)
+
+// flowRepository implements the oauth.FlowRepository interface using Redis for storage.
+type flowRepository struct {
+	client *redis.Client
+}
+
+// NewFlowRepository creates a Redis-based repository for short-lived authorization flow state.
+func NewFlowRepository(client *redis.Client) oauth.FlowRepository {
+	return &flowRepository{client: client}
+}
+
+// SaveDeviceAuthorization stores a device authorization in Redis.
+// It creates two entries that expire together:
+// 1. The authorization itself with the device code as key
+// 2. An index entry mapping the user code to the device code
+func (r *flowRepository) SaveDeviceAuthorization(ctx context.Context, authorization *oauth.DeviceAuthorization) error {
+	data, err := json.Marshal(authorization)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToMarshalDeviceAuthorization)
+	}
+
+	ttl := time.Until(authorization.ExpiresAt) + deviceAuthorizationGracePeriod
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, deviceCodeKeyPrefix+authorization.DeviceCode, data, ttl)
+	pipe.Set(ctx, userCodeKeyPrefix+authorization.UserCode, authorization.DeviceCode, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveDeviceAuthorization, err.Error()))
+	}
+
+	return nil
+}
+
+// FindDeviceAuthorization looks up a device authorization by its device code.
+// Returns nil if the authorization doesn't exist.
+func (r *flowRepository) FindDeviceAuthorization(ctx context.Context, deviceCode string) (*oauth.DeviceAuthorization, error) {
+	data, err := r.client.Get(ctx, deviceCodeKeyPrefix+deviceCode).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindDeviceAuthorization, err.Error()))
+	}
+
+	var authorization oauth.DeviceAuthorization
+	if err := json.Unmarshal([]byte(data), &authorization); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindDeviceAuthorization, err.Error()))
+	}
+
+	return &authorization, nil
+}
+
+// FindDeviceAuthorizationByUserCode looks up a device authorization by its user code.
+// Returns nil if the authorization doesn't exist.
+func (r *flowRepository) FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*oauth.DeviceAuthorization, error) {
+	deviceCode, err := r.client.Get(ctx, userCodeKeyPrefix+userCode).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindDeviceAuthorization, err.Error()))
+	}
+
+	return r.FindDeviceAuthorization(ctx, deviceCode)
+}
+
+// UpdateDeviceAuthorization applies an update to a device authorization in a WATCH/MULTI
+// transaction, so that it cannot overwrite a concurrent change, such as the authorization
+// being consumed by a poll. The update is retried on the new state if the key changed.
+func (r *flowRepository) UpdateDeviceAuthorization(ctx context.Context, deviceCode string, update func(*oauth.DeviceAuthorization) bool) (bool, error) {
+	key := deviceCodeKeyPrefix + deviceCode
+
+	for attempt := 0; attempt < maxDeviceAuthorizationUpdateAttempts; attempt++ {
+		updated := false
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, key).Result()
+			if err == redis.Nil {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			var authorization oauth.DeviceAuthorization
+			if err := json.Unmarshal([]byte(data), &authorization); err != nil {
+				return err
+			}
+			if !update(&authorization) {
+				return nil
+			}
+
+			updatedData, err := json.Marshal(&authorization)
+			if err != nil {
+				return err
+			}
+			ttl := time.Until(authorization.ExpiresAt) + deviceAuthorizationGracePeriod
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, updatedData, ttl)
+				return nil
+			})
+			updated = err == nil
+			return err
+		}, key)
+
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveDeviceAuthorization, err.Error()))
+		}
+		return updated, nil
+	}
+
+	return false, errors.Internal(errors.ErrMsgFailedToSaveDeviceAuthorization)
+}
+
+// DeleteDeviceAuthorization removes a device authorization and its user code index.
+// It reports whether the authorization still existed, which makes deletion the point
+// at which a device code is consumed.
+func (r *flowRepository) DeleteDeviceAuthorization(ctx context.Context, authorization *oauth.DeviceAuthorization) (bool, error) {
+	pipe := r.client.TxPipeline()
+	deleted := pipe.Del(ctx, deviceCodeKeyPrefix+authorization.DeviceCode)
+	pipe.Del(ctx, userCodeKeyPrefix+authorization.UserCode, devicePollKeyPrefix+authorization.DeviceCode)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToDeleteDeviceAuthorization, err.Error()))
+	}
+
+	return deleted.Val() > 0, nil
+}
+
+// RecordDevicePoll sets a marker that expires after the polling interval.
+// If the marker is still present, the device polled too fast and false is returned.
+func (r *flowRepository) RecordDevicePoll(ctx context.Context, deviceCode string, interval time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, devicePollKeyPrefix+deviceCode, time.Now().Unix(), interval).Result()
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRecordDevicePoll, err.Error()))
+	}
+
+	return ok, nil
+}
+
+// SavePushedAuthorizationRequest stores a pushed authorization request in Redis
+// with a TTL matching its expiry.
+func (r *flowRepository) SavePushedAuthorizationRequest(ctx context.Context, request *oauth.PushedAuthorizationRequest) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToMarshalPushedAuthorizationRequest)
+	}
+
+	if err := r.client.Set(ctx, parKeyPrefix+request.RequestURI, data, time.Until(request.ExpiresAt)).Err(); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSavePushedAuthorizationRequest, err.Error()))
+	}
+
+	return nil
+}
+
+// FindPushedAuthorizationRequest looks up a pushed authorization request by its request URI.
+// Returns nil if the request doesn't exist or has expired.
+func (r *flowRepository) FindPushedAuthorizationRequest(ctx context.Context, requestURI string) (*oauth.PushedAuthorizationRequest, error) {
+	data, err := r.client.Get(ctx, parKeyPrefix+requestURI).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindPushedAuthorizationRequest, err.Error()))
+	}
+
+	var request oauth.PushedAuthorizationRequest
+	if err := json.Unmarshal([]byte(data), &request); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindPushedAuthorizationRequest, err.Error()))
+	}
+
+	return &request, nil
+}
+
+// DeletePushedAuthorizationRequest removes a pushed authorization request from Redis.
+func (r *flowRepository) DeletePushedAuthorizationRequest(ctx context.Context, requestURI string) error {
+	if err := r.client.Del(ctx, parKeyPrefix+requestURI).Err(); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToDeletePushedAuthorizationRequest, err.Error()))
+	}
+	return nil
+}
+
+// SaveAuthorizationSession stores an authorization session in Redis
+// with a TTL matching its expiry.
+func (r *flowRepository) SaveAuthorizationSession(ctx context.Context, session *oauth.AuthorizationSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToMarshalAuthorizationSession)
+	}
+
+	if err := r.client.Set(ctx, sessionKeyPrefix+session.ID, data, time.Until(session.ExpiresAt)).Err(); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveAuthorizationSession, err.Error()))
+	}
+
+	return nil
+}
+
+// FindAuthorizationSession looks up an authorization session by its ID.
+// Returns nil if the session doesn't exist or has expired.
+func (r *flowRepository) FindAuthorizationSession(ctx context.Context, id string) (*oauth.AuthorizationSession, error) {
+	data, err := r.client.Get(ctx, sessionKeyPrefix+id).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindAuthorizationSession, err.Error()))
+	}
+
+	var session oauth.AuthorizationSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindAuthorizationSession, err.Error()))
+	}
+
+	return &session, nil
+}
+
+// DeleteAuthorizationSession removes an authorization session from Redis.
+// It reports whether the session still existed, which makes deletion the point
+// at which a session is completed.
+func (r *flowRepository) DeleteAuthorizationSession(ctx context.Context, id string) (bool, error) {
+	deleted, err := r.client.Del(ctx, sessionKeyPrefix+id).Result()
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToDeleteAuthorizationSession, err.Error()))
+	}
+	return deleted > 0, nil
+}
+
+// RecordClientAssertion marks a client assertion as used until it expires.
+// SETNX makes the first use win, so a replayed assertion is reported with false.
+func (r *flowRepository) RecordClientAssertion(ctx context.Context, clientID, jti string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	ok, err := r.client.SetNX(ctx, assertionKeyPrefix+clientID+":"+jti, time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToRecordClientAssertion, err.Error()))
+	}
+
+	return ok, nil
+}
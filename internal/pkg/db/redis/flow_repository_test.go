@@ -0,0 +1,135 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/app/oauth"
+)
+
+// newTestClient returns a client connected to an in-memory Redis server.
+func newTestClient(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+// savePendingDeviceAuthorization stores a pending device authorization with a 5 second interval.
+func savePendingDeviceAuthorization(t *testing.T, repo oauth.FlowRepository) *oauth.DeviceAuthorization {
+	t.Helper()
+
+	authorization := &oauth.DeviceAuthorization{
+		DeviceCode: "device-code",
+		UserCode:   "ABCD-EFGH",
+		ClientID:   "client-a",
+		Scope:      "openid",
+		Status:     oauth.DeviceStatusPending,
+		Interval:   5,
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+		CreatedAt:  time.Now(),
+	}
+	if err := repo.SaveDeviceAuthorization(context.Background(), authorization); err != nil {
+		t.Fatalf("SaveDeviceAuthorization: %v", err)
+	}
+	return authorization
+}
+
+func TestUpdateDeviceAuthorization(t *testing.T) {
+	client, _ := newTestClient(t)
+	repo := NewFlowRepository(client)
+	ctx := context.Background()
+	authorization := savePendingDeviceAuthorization(t, repo)
+
+	updated, err := repo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *oauth.DeviceAuthorization) bool {
+		current.Interval += 5
+		return true
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeviceAuthorization: %v", err)
+	}
+	if !updated {
+		t.Fatal("existing authorization was not updated")
+	}
+
+	found, err := repo.FindDeviceAuthorization(ctx, authorization.DeviceCode)
+	if err != nil {
+		t.Fatalf("FindDeviceAuthorization: %v", err)
+	}
+	if found.Interval != 10 {
+		t.Errorf("interval = %d, want 10", found.Interval)
+	}
+
+	// The update keeps the authorization reachable by its user code
+	found, err = repo.FindDeviceAuthorizationByUserCode(ctx, authorization.UserCode)
+	if err != nil || found == nil {
+		t.Fatalf("FindDeviceAuthorizationByUserCode: %v, %v", found, err)
+	}
+}
+
+func TestUpdateDeviceAuthorizationDecidesOnce(t *testing.T) {
+	client, _ := newTestClient(t)
+	repo := NewFlowRepository(client)
+	ctx := context.Background()
+	authorization := savePendingDeviceAuthorization(t, repo)
+
+	decide := func(status oauth.DeviceAuthorizationStatus, userID uint) bool {
+		updated, err := repo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *oauth.DeviceAuthorization) bool {
+			if current.Status != oauth.DeviceStatusPending {
+				return false
+			}
+			current.Status = status
+			current.UserID = userID
+			return true
+		})
+		if err != nil {
+			t.Fatalf("UpdateDeviceAuthorization: %v", err)
+		}
+		return updated
+	}
+
+	if !decide(oauth.DeviceStatusApproved, 1) {
+		t.Fatal("pending authorization was not approved")
+	}
+	if decide(oauth.DeviceStatusDenied, 2) {
+		t.Fatal("decided authorization was decided again")
+	}
+
+	found, err := repo.FindDeviceAuthorization(ctx, authorization.DeviceCode)
+	if err != nil {
+		t.Fatalf("FindDeviceAuthorization: %v", err)
+	}
+	if found.Status != oauth.DeviceStatusApproved || found.UserID != 1 {
+		t.Errorf("second decision overwrote the first: %+v", found)
+	}
+}
+
+func TestUpdateDeviceAuthorizationAfterDelete(t *testing.T) {
+	client, _ := newTestClient(t)
+	repo := NewFlowRepository(client)
+	ctx := context.Background()
+	authorization := savePendingDeviceAuthorization(t, repo)
+
+	if deleted, err := repo.DeleteDeviceAuthorization(ctx, authorization); err != nil || !deleted {
+		t.Fatalf("DeleteDeviceAuthorization: %v, %v", deleted, err)
+	}
+
+	updated, err := repo.UpdateDeviceAuthorization(ctx, authorization.DeviceCode, func(current *oauth.DeviceAuthorization) bool {
+		t.Error("update called for a deleted authorization")
+		return true
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeviceAuthorization: %v", err)
+	}
+	if updated {
+		t.Error("deleted authorization reported as updated")
+	}
+	if found, _ := repo.FindDeviceAuthorization(ctx, authorization.DeviceCode); found != nil {
+		t.Error("update recreated a deleted authorization")
+	}
+}
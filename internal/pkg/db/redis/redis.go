@@ -24,7 +24,7 @@ func NewConnection() (*redis.Client, error) {
 		db = 0
 	}
 
-	client = redis.NewClient(&redis.Options{
+	client = NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", config.AppConfig.RedisHost, config.AppConfig.RedisPort),
 		Password: config.AppConfig.RedisPassword,
 		DB:       db,
@@ -38,6 +38,13 @@ func NewConnection() (*redis.Client, error) {
 	return client, nil
 }
 
+// NewClient creates a Redis client with the given options whose commands are traced.
+func NewClient(options *redis.Options) *redis.Client {
+	client := redis.NewClient(options)
+	client.AddHook(tracingHook{})
+	return client
+}
+
 // GetClient returns the shared Redis client instance.
 // This allows reusing the same connection throughout the application.
 func GetClient() *redis.Client {
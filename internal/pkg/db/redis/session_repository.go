@@ -0,0 +1,141 @@
+// Package redis provides Redis-based implementations of the application's repositories.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/app/session"
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+)
+
+const (
+	// loginSessionKeyPrefix is the Redis key prefix for browser login sessions by session ID
+	loginSessionKeyPrefix = "session:"
+	// userSessionsKeyPrefix is the Redis key prefix for the set of login session IDs of a user
+	userSessionsKeyPrefix = "user_sessions:"
+)
+
+// sessionRepository implements the session.Repository interface using Redis for storage.
+type sessionRepository struct {
+	client *redis.Client
+}
+
+// NewSessionRepository creates a Redis-based repository for browser login sessions.
+func NewSessionRepository(client *redis.Client) session.Repository {
+	return &sessionRepository{client: client}
+}
+
+// userSessionsKey returns the key of the set of login session IDs of a user.
+func userSessionsKey(userID uint) string {
+	return userSessionsKeyPrefix + fmt.Sprintf("%d", userID)
+}
+
+// Save stores a login session in Redis. The key expires together with the session.
+// The session is also added to the user's index, which lives as long as the user's latest session.
+func (r *sessionRepository) Save(ctx context.Context, s *session.Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Internal(errors.ErrMsgFailedToMarshalSession)
+	}
+
+	indexKey := userSessionsKey(s.UserID)
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, loginSessionKeyPrefix+s.ID, data, time.Until(s.ExpiresAt))
+	pipe.SAdd(ctx, indexKey, s.ID)
+	if ttl, err := r.client.TTL(ctx, indexKey).Result(); err != nil || ttl < time.Until(s.ExpiresAt) {
+		pipe.ExpireAt(ctx, indexKey, s.ExpiresAt)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToSaveSession, err.Error()))
+	}
+
+	return nil
+}
+
+// FindByID looks up a login session by its ID.
+// Returns nil if the session doesn't exist.
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*session.Session, error) {
+	data, err := r.client.Get(ctx, loginSessionKeyPrefix+id).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSession, err.Error()))
+	}
+
+	var s session.Session
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSession, err.Error()))
+	}
+
+	return &s, nil
+}
+
+// FindByUserID retrieves the stored login sessions of a user.
+// Sessions that have expired are removed from the user's index.
+func (r *sessionRepository) FindByUserID(ctx context.Context, userID uint) ([]session.Session, error) {
+	indexKey := userSessionsKey(userID)
+
+	ids, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil && err != redis.Nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSession, err.Error()))
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = loginSessionKeyPrefix + id
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSession, err.Error()))
+	}
+
+	var sessions []session.Session
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var s session.Session
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			return nil, errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToFindSession, err.Error()))
+		}
+		sessions = append(sessions, s)
+	}
+
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, indexKey, expired...).Err(); err != nil {
+			// Not critical, continue
+		}
+	}
+
+	return sessions, nil
+}
+
+// Delete removes a login session from Redis and from its user's index.
+func (r *sessionRepository) Delete(ctx context.Context, id string) error {
+	s, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, loginSessionKeyPrefix+id)
+	if s != nil {
+		pipe.SRem(ctx, userSessionsKey(s.UserID), id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Internal(fmt.Sprintf("%s: %s", errors.ErrMsgFailedToDeleteSession, err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/session"
+)
+
+// saveLoginSession stores a login session of a user that expires after ttl.
+func saveLoginSession(t *testing.T, repo session.Repository, id string, userID uint, ttl time.Duration) {
+	t.Helper()
+
+	now := time.Now()
+	s := &session.Session{ID: id, UserID: userID, AuthTime: now, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	if err := repo.Save(context.Background(), s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestFindLoginSessionsByUserID(t *testing.T) {
+	client, server := newTestClient(t)
+	repo := NewSessionRepository(client)
+	ctx := context.Background()
+
+	saveLoginSession(t, repo, "short", 1, time.Minute)
+	saveLoginSession(t, repo, "long", 1, time.Hour)
+	saveLoginSession(t, repo, "other", 2, time.Hour)
+
+	// The index lives as long as the user's latest session
+	if ttl := server.TTL(userSessionsKey(1)); ttl < 59*time.Minute {
+		t.Errorf("index TTL = %v, want about 1h", ttl)
+	}
+
+	sessions, err := repo.FindByUserID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("found %d sessions, want 2", len(sessions))
+	}
+
+	// An expired session is dropped from the index
+	server.FastForward(2 * time.Minute)
+	sessions, err = repo.FindByUserID(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByUserID: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "long" {
+		t.Errorf("sessions after expiry = %+v, want only long", sessions)
+	}
+	if ok, _ := server.SIsMember(userSessionsKey(1), "short"); ok {
+		t.Error("expired session still indexed")
+	}
+}
+
+func TestDeleteLoginSessionRemovesIndexEntry(t *testing.T) {
+	client, server := newTestClient(t)
+	repo := NewSessionRepository(client)
+	ctx := context.Background()
+
+	saveLoginSession(t, repo, "session", 1, time.Hour)
+	if err := repo.Delete(ctx, "session"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if found, _ := repo.FindByID(ctx, "session"); found != nil {
+		t.Error("deleted session still found")
+	}
+	if ok, _ := server.SIsMember(userSessionsKey(1), "session"); ok {
+		t.Error("deleted session still indexed")
+	}
+
+	// Deleting a session that doesn't exist is not an error
+	if err := repo.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of a missing session: %v", err)
+	}
+}
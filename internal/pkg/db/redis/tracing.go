@@ -0,0 +1,92 @@
+// Package redis provides Redis connection and repository implementations
+// for caching and ephemeral data storage in the Verigate Server application.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook records a span for every Redis command and pipeline.
+type tracingHook struct{}
+
+// BeforeProcess starts the span of a command.
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = tracing.StartClient(ctx, "redis "+cmd.FullName(),
+		semconv.DBSystemRedis,
+		semconv.DBStatement(commandStatement(cmd)),
+	)
+	return ctx, nil
+}
+
+// AfterProcess ends the span of a command.
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endSpan(trace.SpanFromContext(ctx), cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline starts the span of a pipeline, with one statement per command.
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	statements := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		statements[i] = commandStatement(cmd)
+	}
+
+	ctx, _ = tracing.StartClient(ctx, "redis pipeline",
+		semconv.DBSystemRedis,
+		semconv.DBStatement(strings.Join(statements, "\n")),
+		attribute.Int("db.redis.num_cmd", len(cmds)),
+	)
+	return ctx, nil
+}
+
+// AfterProcessPipeline ends the span of a pipeline.
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	endSpan(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+// commandStatement returns the name of a command and the prefix of its first argument, which is
+// the key for most commands. Keys end in secrets such as session IDs, device codes and request
+// URIs, so everything after the last colon is replaced with a question mark, and a key without
+// a colon is left out entirely. The remaining arguments, such as stored tokens, are never recorded.
+func commandStatement(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return cmd.Name()
+	}
+	return cmd.Name() + " " + redactKey(fmt.Sprint(args[1]))
+}
+
+// redactKey keeps the prefix of a key up to its last colon and replaces the rest with a question mark.
+func redactKey(key string) string {
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		return key[:i+1] + "?"
+	}
+	return "?"
+}
+
+// endSpan marks the span as failed if the command failed, and ends it.
+// A missing key is not a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
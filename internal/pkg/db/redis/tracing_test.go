@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestCommandStatementRedactsKeys(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		cmd  redis.Cmder
+		want string
+	}{
+		{redis.NewStringCmd(ctx, "get", loginSessionKeyPrefix+"secret-session-id"), "get session:?"},
+		{redis.NewStatusCmd(ctx, "set", deviceCodeKeyPrefix+"secret-code", "value"), "set oauth:device_code:?"},
+		{redis.NewIntCmd(ctx, "del", parKeyPrefix+"urn:ietf:params:oauth:request_uri:secret"), "del oauth:par:urn:ietf:params:oauth:request_uri:?"},
+		{redis.NewStringCmd(ctx, "get", "secret"), "get ?"},
+		{redis.NewStatusCmd(ctx, "ping"), "ping"},
+	}
+
+	for _, tt := range tests {
+		if got := commandStatement(tt.cmd); got != tt.want {
+			t.Errorf("statement of %v = %q, want %q", tt.cmd.Args(), got, tt.want)
+		}
+	}
+}
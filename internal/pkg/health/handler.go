@@ -0,0 +1,51 @@
+// Package health reports whether the server is alive and whether it is ready to serve
+// requests, for use as liveness and readiness probes by orchestrators and load balancers.
+// Readiness depends on checks of the server's dependencies, and fails while the server
+// is draining before a shutdown.
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves the liveness and readiness probes.
+type Handler struct {
+	checker *Checker
+}
+
+// NewHandler creates a new probe handler with the given checker.
+func NewHandler(checker *Checker) *Handler {
+	return &Handler{checker: checker}
+}
+
+// RegisterRoutes registers the probe routes on the provided router.
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/livez", h.Live)   // Liveness probe
+	r.GET("/readyz", h.Ready) // Readiness probe
+}
+
+// Live handles the liveness probe. It succeeds as long as the server can handle requests,
+// regardless of its dependencies, so that an outage of the database does not get
+// every instance restarted.
+//
+// Route: GET /livez
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Ready handles the readiness probe. It responds with 503 Service Unavailable if a
+// dependency check fails or the server is draining.
+//
+// Route: GET /readyz
+func (h *Handler) Ready(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -0,0 +1,116 @@
+// Package health reports whether the server is alive and whether it is ready to serve
+// requests, for use as liveness and readiness probes by orchestrators and load balancers.
+// Readiness depends on checks of the server's dependencies, and fails while the server
+// is draining before a shutdown.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// Status values of a report and its components
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// Check verifies that a dependency is usable. It must return once the context is done.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the result of a single check.
+type ComponentStatus struct {
+	Status     string `json:"status"`      // ok or fail
+	DurationMS int64  `json:"duration_ms"` // How long the check took
+}
+
+// Report is the overall readiness of the server with the status of each component.
+type Report struct {
+	Status     string                     `json:"status"` // ok, fail or draining
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// Checker runs the readiness checks of the server's dependencies.
+type Checker struct {
+	timeout  time.Duration
+	logger   *zap.Logger
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewChecker creates a checker that gives each check up to the given timeout.
+func NewChecker(timeout time.Duration, logger *zap.Logger) *Checker {
+	return &Checker{
+		timeout: timeout,
+		logger:  logger,
+		checks:  make(map[string]Check),
+	}
+}
+
+// Add registers a check for the named component.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Drain makes the server report itself as not ready from now on, so that it stops
+// receiving new traffic before it shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs all checks concurrently and reports the server as ready if every check passed.
+// While draining the checks are not run. Failures are logged with their cause, which is
+// left out of the report as it may reveal details of the infrastructure.
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(checks))}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+			status := ComponentStatus{Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = StatusFail
+				c.logger.With(tracing.LogFields(ctx)...).Warn("Readiness check failed", zap.String("component", name), zap.Error(err))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[name] = status
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
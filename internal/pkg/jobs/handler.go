@@ -0,0 +1,34 @@
+// Package jobs runs periodic background jobs, such as purging expired authorization codes
+// and tokens. Every run takes a cluster-wide lock and records its start time, so when several
+// replicas of the server run the same jobs, each job still runs once per interval.
+package jobs
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler exposes the statistics of background jobs to administrators.
+type Handler struct {
+	scheduler *Scheduler
+}
+
+// NewHandler creates a new background job handler for the given scheduler.
+func NewHandler(scheduler *Scheduler) *Handler {
+	return &Handler{scheduler: scheduler}
+}
+
+// RegisterRoutes registers the background job routes on the provided router group.
+// The group is expected to be protected by admin authentication.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("", h.List) // List background jobs and their statistics
+}
+
+// List handles the GET request to list the background jobs with the statistics of their
+// runs on this replica, such as the number of items purged.
+//
+// Route: GET /admin/jobs
+func (h *Handler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": h.scheduler.Stats()})
+}
@@ -0,0 +1,191 @@
+// Package jobs runs periodic background jobs, such as purging expired authorization codes
+// and tokens. Every run takes a cluster-wide lock and records its start time, so when several
+// replicas of the server run the same jobs, each job still runs once per interval.
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// Job is a task that runs at a fixed interval.
+type Job struct {
+	Name     string        // Unique name, also used as the lock name
+	Interval time.Duration // Time between runs; jobs with a zero interval are disabled
+	// Run performs the job and returns the number of items it removed or processed
+	Run func(ctx context.Context) (int64, error)
+}
+
+// Locker grants locks that are exclusive across all replicas of the server.
+type Locker interface {
+	// TryLock acquires the named lock without waiting. It returns false if another holder
+	// has the lock, and otherwise a function that releases it.
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// RunRecorder keeps the start time of the last run of every job, shared by all replicas of the server.
+type RunRecorder interface {
+	// StartRun records that the named job starts now, unless it already started less than
+	// interval ago on any replica. It returns false if the run is not due yet.
+	StartRun(ctx context.Context, name string, interval time.Duration) (bool, error)
+}
+
+// runSlack is the fraction of a job's interval by which a run may come early. The ticks of
+// a replica are not exactly one interval apart as seen by the shared clock, and a replica must
+// not skip its own next run because it arrived a moment too soon.
+const runSlack = 10
+
+// Stats describes the runs of a job on this replica.
+type Stats struct {
+	Name         string        `json:"name"`
+	Runs         int64         `json:"runs"`          // Completed runs, including failed ones
+	Skipped      int64         `json:"skipped"`       // Runs skipped because another replica held the lock or had already run the job
+	Failures     int64         `json:"failures"`      // Runs that returned an error or could not be locked or recorded
+	Purged       int64         `json:"purged"`        // Total number of items removed
+	LastRun      time.Time     `json:"last_run"`      // Start of the last completed run
+	LastDuration time.Duration `json:"last_duration"` // Duration of the last completed run
+}
+
+// Scheduler runs jobs in the background and keeps statistics about their runs.
+type Scheduler struct {
+	locker Locker
+	runs   RunRecorder
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	jobs  []Job
+	stats map[string]*Stats
+}
+
+// NewScheduler creates a scheduler that coordinates runs with the given locker,
+// and skips runs that the recorder shows another replica has already made.
+func NewScheduler(locker Locker, runs RunRecorder, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		runs:   runs,
+		logger: logger,
+		stats:  make(map[string]*Stats),
+	}
+}
+
+// Add registers a job. Jobs must be added before Run is called.
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+	s.stats[job.Name] = &Stats{Name: job.Name}
+}
+
+// Run runs every enabled job once and then at its interval, until the context is cancelled.
+// It blocks until all jobs have stopped.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			s.logger.Info("Background job disabled", zap.String("job", job.Name))
+			continue
+		}
+
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// Stats returns a snapshot of the statistics of all jobs, in the order they were added.
+func (s *Scheduler) Stats() []Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]Stats, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		stats = append(stats, *s.stats[job.Name])
+	}
+	return stats
+}
+
+// loop runs a job immediately and then on every tick of its interval.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs a job if no other replica is running it or has run it within its interval,
+// and records the outcome. A run whose lock or start cannot be recorded counts as a failure.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	ctx, span := tracing.Start(ctx, "job "+job.Name)
+	defer span.End()
+	logger := s.logger.With(tracing.LogFields(ctx)...)
+
+	unlock, acquired, err := s.locker.TryLock(ctx, job.Name)
+	if err != nil {
+		s.record(job.Name, func(stats *Stats) { stats.Failures++ })
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Failed to lock background job", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+	if !acquired {
+		s.record(job.Name, func(stats *Stats) { stats.Skipped++ })
+		return
+	}
+	defer unlock()
+
+	due, err := s.runs.StartRun(ctx, job.Name, job.Interval-job.Interval/runSlack)
+	if err != nil {
+		s.record(job.Name, func(stats *Stats) { stats.Failures++ })
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Failed to record background job run", zap.String("job", job.Name), zap.Error(err))
+		return
+	}
+	if !due {
+		s.record(job.Name, func(stats *Stats) { stats.Skipped++ })
+		return
+	}
+
+	start := time.Now()
+	count, err := job.Run(ctx)
+	duration := time.Since(start)
+
+	s.record(job.Name, func(stats *Stats) {
+		stats.Runs++
+		stats.Purged += count
+		stats.LastRun = start
+		stats.LastDuration = duration
+		if err != nil {
+			stats.Failures++
+		}
+	})
+
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Background job failed", zap.String("job", job.Name), zap.Int64("count", count), zap.Error(err))
+		return
+	}
+	logger.Info("Background job completed", zap.String("job", job.Name), zap.Int64("count", count), zap.Duration("duration", duration))
+}
+
+// record updates the statistics of a job.
+func (s *Scheduler) record(name string, update func(*Stats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(s.stats[name])
+}
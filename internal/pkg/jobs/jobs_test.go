@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeLocker is an in-memory Locker shared by the schedulers of a test.
+type fakeLocker struct {
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked[name] {
+		return nil, false, nil
+	}
+	l.locked[name] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.locked, name)
+	}, true, nil
+}
+
+// fakeRunRecorder is an in-memory RunRecorder with a clock that tests move forward.
+type fakeRunRecorder struct {
+	mu      sync.Mutex
+	now     time.Time
+	lastRun map[string]time.Time
+}
+
+func (r *fakeRunRecorder) StartRun(ctx context.Context, name string, interval time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.lastRun[name]; ok && r.now.Sub(last) < interval {
+		return false, nil
+	}
+	r.lastRun[name] = r.now
+	return true, nil
+}
+
+func (r *fakeRunRecorder) advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = r.now.Add(d)
+}
+
+// failingLocker is a Locker whose store is unavailable.
+type failingLocker struct{}
+
+func (failingLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	return nil, false, errors.New("lock store unavailable")
+}
+
+// failingRunRecorder is a RunRecorder whose store is unavailable.
+type failingRunRecorder struct{}
+
+func (failingRunRecorder) StartRun(ctx context.Context, name string, interval time.Duration) (bool, error) {
+	return false, errors.New("run store unavailable")
+}
+
+func TestJobRunsOncePerIntervalAcrossReplicas(t *testing.T) {
+	locker := &fakeLocker{locked: make(map[string]bool)}
+	runs := &fakeRunRecorder{now: time.Now(), lastRun: make(map[string]time.Time)}
+
+	var mu sync.Mutex
+	count := 0
+	job := Job{
+		Name:     "purge",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) (int64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			count++
+			return 0, nil
+		},
+	}
+
+	replicas := make([]*Scheduler, 3)
+	for i := range replicas {
+		replicas[i] = NewScheduler(locker, runs, zap.NewNop())
+		replicas[i].Add(job)
+	}
+	runAll := func() {
+		for _, replica := range replicas {
+			replica.runOnce(context.Background(), job)
+		}
+	}
+
+	// Every replica ticks, but only the first one runs the job
+	runAll()
+	if count != 1 {
+		t.Fatalf("job ran %d times in one interval, want 1", count)
+	}
+	for i, replica := range replicas[1:] {
+		if stats := replica.Stats()[0]; stats.Runs != 0 || stats.Skipped != 1 {
+			t.Errorf("replica %d: %+v, want one skipped run", i+1, stats)
+		}
+	}
+
+	// A tick that arrives slightly before a full interval has passed still runs the job
+	runs.advance(time.Hour - time.Minute)
+	runAll()
+	if count != 2 {
+		t.Errorf("job ran %d times in two intervals, want 2", count)
+	}
+
+	// Ticks well within the interval are skipped
+	runs.advance(10 * time.Minute)
+	runAll()
+	if count != 2 {
+		t.Errorf("job ran again within its interval: %d runs", count)
+	}
+}
+
+func TestUnrecordedRunCountsAsFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		locker Locker
+		runs   RunRecorder
+	}{
+		{"lock", failingLocker{}, &fakeRunRecorder{lastRun: make(map[string]time.Time)}},
+		{"start", &fakeLocker{locked: make(map[string]bool)}, failingRunRecorder{}},
+	}
+
+	for _, tt := range tests {
+		ran := false
+		job := Job{
+			Name:     "purge",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) (int64, error) {
+				ran = true
+				return 0, nil
+			},
+		}
+		scheduler := NewScheduler(tt.locker, tt.runs, zap.NewNop())
+		scheduler.Add(job)
+
+		scheduler.runOnce(context.Background(), job)
+		if ran {
+			t.Errorf("%s failed: job ran anyway", tt.name)
+		}
+		if stats := scheduler.Stats()[0]; stats.Failures != 1 || stats.Runs != 0 || stats.Skipped != 0 {
+			t.Errorf("%s failed: %+v, want one failure", tt.name, stats)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package metrics defines the Prometheus metrics of the authorization server
+// and exposes them for scraping.
+// Metrics are registered on a registry of their own rather than the global default one,
+// so only the metrics listed here are exported.
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/verigate/verigate-server/internal/pkg/jobs"
+)
+
+// RegisterPostgres exports the connection pool statistics of the PostgreSQL database.
+func RegisterPostgres(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// RegisterRedis exports the connection pool statistics of the Redis client.
+func RegisterRedis(client *redis.Client) {
+	Registry.MustRegister(&redisCollector{client: client})
+}
+
+// RegisterJobs exports the run statistics of the background jobs.
+func RegisterJobs(scheduler *jobs.Scheduler) {
+	Registry.MustRegister(&jobsCollector{scheduler: scheduler})
+}
+
+var (
+	redisPoolHits = prometheus.NewDesc(
+		namespace+"_redis_pool_hits_total", "Times a free connection was found in the Redis pool.", nil, nil)
+	redisPoolMisses = prometheus.NewDesc(
+		namespace+"_redis_pool_misses_total", "Times a free connection was not found in the Redis pool.", nil, nil)
+	redisPoolTimeouts = prometheus.NewDesc(
+		namespace+"_redis_pool_timeouts_total", "Times a wait for a Redis connection timed out.", nil, nil)
+	redisPoolConnections = prometheus.NewDesc(
+		namespace+"_redis_pool_connections", "Connections in the Redis pool.", []string{"state"}, nil)
+)
+
+// redisCollector reads the pool statistics of a Redis client on every scrape.
+type redisCollector struct {
+	client *redis.Client
+}
+
+// Describe sends the descriptors of the Redis metrics.
+func (c *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisPoolHits
+	ch <- redisPoolMisses
+	ch <- redisPoolTimeouts
+	ch <- redisPoolConnections
+}
+
+// Collect sends the current Redis pool statistics.
+func (c *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisPoolHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisPoolMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisPoolTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisPoolConnections, prometheus.GaugeValue, float64(stats.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(redisPoolConnections, prometheus.GaugeValue, float64(stats.TotalConns-stats.IdleConns), "in_use")
+	ch <- prometheus.MustNewConstMetric(redisPoolConnections, prometheus.GaugeValue, float64(stats.StaleConns), "stale")
+}
+
+var (
+	jobRuns = prometheus.NewDesc(
+		namespace+"_job_runs_total", "Completed runs of a background job on this instance.", []string{"job"}, nil)
+	jobSkipped = prometheus.NewDesc(
+		namespace+"_job_skipped_total", "Runs of a background job skipped because another instance held its lock or had already run it.", []string{"job"}, nil)
+	jobFailures = prometheus.NewDesc(
+		namespace+"_job_failures_total", "Failed runs of a background job.", []string{"job"}, nil)
+	jobPurged = prometheus.NewDesc(
+		namespace+"_job_purged_total", "Rows or entries removed by a background job.", []string{"job"}, nil)
+	jobLastRun = prometheus.NewDesc(
+		namespace+"_job_last_run_timestamp_seconds", "Start time of the last completed run of a background job.", []string{"job"}, nil)
+	jobLastDuration = prometheus.NewDesc(
+		namespace+"_job_last_run_duration_seconds", "Duration of the last completed run of a background job.", []string{"job"}, nil)
+)
+
+// jobsCollector reads the statistics of the background jobs on every scrape.
+type jobsCollector struct {
+	scheduler *jobs.Scheduler
+}
+
+// Describe sends the descriptors of the job metrics.
+func (c *jobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jobRuns
+	ch <- jobSkipped
+	ch <- jobFailures
+	ch <- jobPurged
+	ch <- jobLastRun
+	ch <- jobLastDuration
+}
+
+// Collect sends the current statistics of every job.
+func (c *jobsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range c.scheduler.Stats() {
+		ch <- prometheus.MustNewConstMetric(jobRuns, prometheus.CounterValue, float64(stats.Runs), stats.Name)
+		ch <- prometheus.MustNewConstMetric(jobSkipped, prometheus.CounterValue, float64(stats.Skipped), stats.Name)
+		ch <- prometheus.MustNewConstMetric(jobFailures, prometheus.CounterValue, float64(stats.Failures), stats.Name)
+		ch <- prometheus.MustNewConstMetric(jobPurged, prometheus.CounterValue, float64(stats.Purged), stats.Name)
+		if !stats.LastRun.IsZero() {
+			ch <- prometheus.MustNewConstMetric(jobLastRun, prometheus.GaugeValue, float64(stats.LastRun.Unix()), stats.Name)
+			ch <- prometheus.MustNewConstMetric(jobLastDuration, prometheus.GaugeValue, stats.LastDuration.Seconds(), stats.Name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package metrics defines the Prometheus metrics of the authorization server
+// and exposes them for scraping.
+// Metrics are registered on a registry of their own rather than the global default one,
+// so only the metrics listed here are exported.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all metrics.
+const namespace = "verigate"
+
+// Login results
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+// Revocation reasons
+const (
+	RevokedByClient         = "client_request"           // The client revoked the token (RFC 7009)
+	RevokedByUser           = "user_request"             // The user revoked the token
+	RevokedConsentWithdrawn = "consent_withdrawn"        // The user disconnected the client
+	RevokedRefreshReuse     = "refresh_token_reuse"      // A rotated-out refresh token was presented
+	RevokedAuthCodeReuse    = "authorization_code_reuse" // A used authorization code was presented
+)
+
+// Registry holds all metrics of the server.
+var Registry = prometheus.NewRegistry()
+
+var (
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_issued_total",
+		Help:      "Tokens issued by the token endpoint, by grant type and client.",
+	}, []string{"grant_type", "client_id"})
+
+	tokenErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_errors_total",
+		Help:      "Error responses of the token endpoint, by OAuth error code.",
+	}, []string{"error"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "User login attempts with a password, by result.",
+	}, []string{"result"})
+
+	consentDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "consent_decisions_total",
+		Help:      "Consent decisions for authorization requests, by outcome.",
+	}, []string{"outcome"})
+
+	tokenRevocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_revocations_total",
+		Help:      "Token revocations, by reason.",
+	}, []string{"reason"})
+
+	rateLimitRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests, by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		tokensIssued,
+		tokenErrors,
+		logins,
+		consentDecisions,
+		tokenRevocations,
+		rateLimitRejections,
+		httpRequestDuration,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// TokenIssued counts a successful token response.
+func TokenIssued(grantType, clientID string) {
+	tokensIssued.WithLabelValues(grantType, clientID).Inc()
+}
+
+// TokenError counts an error response of the token endpoint.
+func TokenError(code string) {
+	tokenErrors.WithLabelValues(code).Inc()
+}
+
+// Login counts a login attempt with the given result.
+func Login(result string) {
+	logins.WithLabelValues(result).Inc()
+}
+
+// ConsentDecision counts a consent decision with the given outcome.
+func ConsentDecision(outcome string) {
+	consentDecisions.WithLabelValues(outcome).Inc()
+}
+
+// TokenRevoked counts a revocation with the given reason. A revocation may cover several tokens.
+func TokenRevoked(reason string) {
+	tokenRevocations.WithLabelValues(reason).Inc()
+}
+
+// RateLimitRejected counts a request rejected by the rate limiter.
+func RateLimitRejected() {
+	rateLimitRejections.Inc()
+}
+
+// ObserveRequest records the latency of an HTTP request.
+// The route is the registered path pattern, so that path parameters do not create new series.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
@@ -0,0 +1,38 @@
+// Package middleware provides HTTP middleware functions for the application.
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth is an authentication middleware for administrative APIs.
+// It requires the request to carry the configured admin API key as a bearer token.
+// When no API key is configured, all administrative requests are rejected.
+func AdminAuth(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" {
+			c.Error(errors.Forbidden(errors.ErrMsgAdminAPIDisabled))
+			c.Abort()
+			return
+		}
+
+		// Extract bearer token from Authorization header
+		tokenString, ok := extractBearerToken(c)
+		if !ok {
+			return // Error already handled in the function
+		}
+
+		// Compare in constant time to avoid leaking the key through timing
+		if subtle.ConstantTimeCompare([]byte(tokenString), []byte(apiKey)) != 1 {
+			c.Error(errors.Unauthorized(errors.ErrMsgInvalidAdminCredentials))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 	"github.com/verigate/verigate-server/internal/pkg/utils/jwt"
@@ -21,10 +22,12 @@ const (
 	ErrMsgMissingAuthHeader = "missing authorization header"
 	ErrMsgInvalidAuthFormat = "invalid authorization header format"
 	ErrMsgInvalidToken      = "invalid token"
+	ErrMsgUserTokenRequired = "token is not issued to a user"
 
 	// Context keys for authentication data
-	ContextKeyUserID = "user_id" // Must match jwt.ClaimKeyUserID
-	ContextKeyClaims = "claims"
+	ContextKeyUserID       = "user_id" // Must match jwt.ClaimKeyUserID
+	ContextKeyClaims       = "claims"
+	ContextKeyWebSessionID = "web_session_id" // Login session of a web app access token
 )
 
 // Auth is an authentication middleware for OAuth APIs.
@@ -35,7 +38,8 @@ const (
 // 1. Extracts the Authorization header from the request
 // 2. Validates the bearer token format
 // 3. Verifies the token signature and validity using the JWT utility
-// 4. Sets the authenticated user ID and claims in the request context
+// 4. Rejects client-only tokens (client_credentials grant), which carry no user
+// 5. Sets the authenticated user ID and claims in the request context
 //
 // If authentication fails, the middleware aborts the request with an appropriate error.
 func Auth() gin.HandlerFunc {
@@ -54,6 +58,13 @@ func Auth() gin.HandlerFunc {
 			return
 		}
 
+		// The protected endpoints act on behalf of a user
+		if claims.UserID == 0 {
+			c.Error(errors.Unauthorized(ErrMsgUserTokenRequired))
+			c.Abort()
+			return
+		}
+
 		// Store user ID and claims in context for downstream handlers
 		c.Set(ContextKeyUserID, claims.UserID)
 		c.Set(ContextKeyClaims, claims)
@@ -62,6 +73,16 @@ func Auth() gin.HandlerFunc {
 	}
 }
 
+// AuthTime returns the time at which the authenticated user logged in, as recorded by the
+// browser login session. Access tokens do not carry the login time, so it returns the zero
+// time for requests authenticated only by a token.
+func AuthTime(c *gin.Context) time.Time {
+	if s := CurrentSession(c); s != nil {
+		return s.AuthTime
+	}
+	return time.Time{}
+}
+
 // extractBearerToken extracts the bearer token from the Authorization header.
 // It returns the token string and a boolean indicating if extraction was successful.
 // If extraction fails, it aborts the request with an appropriate error.
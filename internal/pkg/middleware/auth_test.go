@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/session"
+	"github.com/verigate/verigate-server/internal/pkg/utils/jwt"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
+)
+
+func TestAuthTimeComesOnlyFromLoginSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// The issue time of an access token is not a login time
+	c.Set(ContextKeyClaims, &jwt.Claims{RegisteredClaims: gojwt.RegisteredClaims{
+		IssuedAt: gojwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}})
+	if authTime := AuthTime(c); !authTime.IsZero() {
+		t.Errorf("AuthTime without a login session = %v, want the zero time", authTime)
+	}
+
+	loggedIn := time.Now().Add(-time.Hour).Truncate(time.Second)
+	c.Set(ContextKeySession, &session.Session{AuthTime: loggedIn})
+	if authTime := AuthTime(c); !authTime.Equal(loggedIn) {
+		t.Errorf("AuthTime = %v, want the login time %v", authTime, loggedIn)
+	}
+}
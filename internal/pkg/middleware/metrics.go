@@ -0,0 +1,30 @@
+// Package middleware provides HTTP middleware functions for the application.
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
+)
+
+// routeUnmatched labels requests that did not match a registered route, so that
+// arbitrary request paths do not create new metric series.
+const routeUnmatched = "unmatched"
+
+// Metrics creates a middleware that records the latency of every request by route.
+// It should be added before the error handler, so that the recorded status is the one
+// the error handler writes.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = routeUnmatched
+		}
+		metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
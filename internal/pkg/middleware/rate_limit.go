@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/pkg/metrics"
 	"github.com/verigate/verigate-server/internal/pkg/utils/errors"
 
 	"github.com/gin-gonic/gin"
@@ -88,6 +89,7 @@ func RateLimitMiddleware(limiter *RedisRateLimiter) gin.HandlerFunc {
 		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", now+int64(limiter.window.Seconds())))
 
 		if count > int64(limiter.limitPerMin) {
+			metrics.RateLimitRejected()
 			c.Error(errors.TooManyRequests(errors.ErrMsgRateLimitExceeded))
 			c.Abort()
 			return
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
 	"go.uber.org/zap"
 )
 
@@ -16,7 +17,7 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("panic recovered",
+				logger.With(tracing.LogFields(c.Request.Context())...).Error("panic recovered",
 					zap.Any("error", err),
 					zap.String("method", c.Request.Method),
 					zap.String("path", c.Request.URL.Path),
@@ -47,7 +48,7 @@ func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 
 		// Log request details
-		logger.Info("request processed",
+		logger.With(tracing.LogFields(c.Request.Context())...).Info("request processed",
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
 			zap.Int("status", c.Writer.Status()),
@@ -0,0 +1,82 @@
+// Package middleware provides HTTP middleware functions for the application.
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/app/session"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeySession is the context key of the browser login session set by BrowserSession
+const ContextKeySession = "session"
+
+// BrowserSession is an authentication middleware for pages opened in a browser,
+// such as the authorization endpoint and the hosted login page.
+// It loads the login session referenced by the session cookie and, if the session is valid,
+// sets the user ID and the session in the request context.
+//
+// The middleware never rejects a request: handlers decide what to do without a session,
+// for example redirecting to the login page.
+func BrowserSession(sessionService *session.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID, err := c.Cookie(config.AppConfig.SessionCookieName)
+		if err == nil && sessionID != "" {
+			s, err := sessionService.Get(c.Request.Context(), sessionID)
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if s != nil {
+				c.Set(ContextKeyUserID, s.UserID)
+				c.Set(ContextKeySession, s)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentSession returns the login session set by BrowserSession, or nil if there is none.
+func CurrentSession(c *gin.Context) *session.Session {
+	if value, exists := c.Get(ContextKeySession); exists {
+		if s, ok := value.(*session.Session); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// SetSessionCookie sends the cookie holding the ID of a login session.
+// The cookie is HttpOnly and Secure unless SESSION_COOKIE_SECURE is disabled.
+// SameSite=Lax keeps it on the top-level redirect from a client to the authorization
+// endpoint, while cross-site form posts to the consent and login pages go without it.
+func SetSessionCookie(c *gin.Context, s *session.Session) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.AppConfig.SessionCookieName,
+		Value:    s.ID,
+		Path:     "/",
+		Expires:  s.ExpiresAt,
+		MaxAge:   int(time.Until(s.ExpiresAt).Seconds()),
+		Secure:   config.AppConfig.SessionCookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearSessionCookie tells the browser to drop the session cookie.
+func ClearSessionCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.AppConfig.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   config.AppConfig.SessionCookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
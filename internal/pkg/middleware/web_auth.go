@@ -15,12 +15,18 @@ import (
 // The middleware:
 // 1. Extracts the Authorization header from the request
 // 2. Validates the bearer token format
-// 3. Verifies the token signature and validity using the auth service
-// 4. Sets the authenticated user ID in the request context for downstream handlers
+// 3. Verifies the token signature, validity and login session using the auth service
+// 4. Sets the authenticated user ID and login session ID in the request context for downstream handlers
 //
+// Requests already authenticated by a browser login session (see BrowserSession) are let through.
 // If authentication fails, the middleware aborts the request with an appropriate error.
 func WebAuth(authService *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if CurrentSession(c) != nil {
+			c.Next()
+			return
+		}
+
 		// Extract bearer token from Authorization header
 		tokenString, ok := extractBearerToken(c)
 		if !ok {
@@ -28,7 +34,7 @@ func WebAuth(authService *auth.Service) gin.HandlerFunc {
 		}
 
 		// Validate token and extract user ID
-		userID, err := authService.ValidateAccessToken(tokenString)
+		userID, sessionID, err := authService.ValidateAccessToken(c.Request.Context(), tokenString)
 		if err != nil {
 			c.Error(errors.Unauthorized(ErrMsgInvalidToken))
 			c.Abort()
@@ -37,6 +43,9 @@ func WebAuth(authService *auth.Service) gin.HandlerFunc {
 
 		// Store user ID in context for downstream handlers
 		c.Set(ContextKeyUserID, userID)
+		if sessionID != "" {
+			c.Set(ContextKeyWebSessionID, sessionID)
+		}
 
 		c.Next()
 	}
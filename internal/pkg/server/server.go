@@ -0,0 +1,149 @@
+// Package server runs the HTTP server of the application with configurable timeouts,
+// optional TLS and a graceful shutdown that lets in-flight requests finish.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"go.uber.org/zap"
+)
+
+// Server wraps an http.Server and manages its lifecycle.
+type Server struct {
+	httpServer      *http.Server
+	certs           *certReloader // nil when TLS is disabled
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	drainHooks      []func() // Called when shutdown is requested, before the shutdown delay
+	logger          *zap.Logger
+}
+
+// New creates a server for the handler from the application configuration.
+// TLS is enabled when both a certificate and a key file are configured.
+func New(handler http.Handler, logger *zap.Logger) (*Server, error) {
+	var readTimeout, readHeaderTimeout, writeTimeout, idleTimeout, shutdownDelay, shutdownTimeout time.Duration
+	durations := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", config.AppConfig.ServerReadTimeout, &readTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", config.AppConfig.ServerReadHeaderTimeout, &readHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", config.AppConfig.ServerWriteTimeout, &writeTimeout},
+		{"SERVER_IDLE_TIMEOUT", config.AppConfig.ServerIdleTimeout, &idleTimeout},
+		{"SERVER_SHUTDOWN_DELAY", config.AppConfig.ServerShutdownDelay, &shutdownDelay},
+		{"SERVER_SHUTDOWN_TIMEOUT", config.AppConfig.ServerShutdownTimeout, &shutdownTimeout},
+	}
+	for _, d := range durations {
+		duration, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.name, err)
+		}
+		*d.dest = duration
+	}
+
+	s := &Server{
+		httpServer: &http.Server{
+			Addr:              ":" + config.AppConfig.AppPort,
+			Handler:           handler,
+			ReadTimeout:       readTimeout,
+			ReadHeaderTimeout: readHeaderTimeout,
+			WriteTimeout:      writeTimeout,
+			IdleTimeout:       idleTimeout,
+			MaxHeaderBytes:    config.AppConfig.ServerMaxHeaderBytes,
+			ErrorLog:          zap.NewStdLog(logger),
+		},
+		shutdownDelay:   shutdownDelay,
+		shutdownTimeout: shutdownTimeout,
+		logger:          logger,
+	}
+
+	certFile, keyFile := config.AppConfig.TLSCertFile, config.AppConfig.TLSKeyFile
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		}
+
+		reloadInterval, err := time.ParseDuration(config.AppConfig.TLSReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS_RELOAD_INTERVAL: %w", err)
+		}
+
+		certs, err := newCertReloader(certFile, keyFile, reloadInterval, logger)
+		if err != nil {
+			return nil, err
+		}
+		s.certs = certs
+		s.httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	return s, nil
+}
+
+// OnDrain registers a function to call as soon as shutdown is requested, while the server
+// still serves requests during the shutdown delay, such as failing readiness checks.
+// Functions must be registered before Run is called.
+func (s *Server) OnDrain(f func()) {
+	s.drainHooks = append(s.drainHooks, f)
+}
+
+// Run serves requests until the context is cancelled, and then shuts the server down.
+// On shutdown the server waits for the shutdown delay while still accepting requests,
+// giving load balancers time to stop routing to it, then stops accepting connections and
+// waits up to the shutdown timeout for in-flight requests to complete.
+// Returns an error if the server fails to start or does not shut down cleanly.
+func (s *Server) Run(ctx context.Context) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.certs != nil {
+			s.logger.Info("Starting server with TLS", zap.String("addr", s.httpServer.Addr))
+			serveErr <- s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			s.logger.Info("Starting server", zap.String("addr", s.httpServer.Addr))
+			serveErr <- s.httpServer.ListenAndServe()
+		}
+	}()
+
+	if s.certs != nil {
+		go s.certs.Run(ctx)
+	}
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+	}
+
+	for _, drain := range s.drainHooks {
+		drain()
+	}
+
+	if s.shutdownDelay > 0 {
+		s.logger.Info("Shutdown requested, waiting before closing the listener", zap.Duration("delay", s.shutdownDelay))
+		time.Sleep(s.shutdownDelay)
+	}
+
+	s.logger.Info("Shutting down server", zap.Duration("timeout", s.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		// Requests still running after the deadline are cut off
+		s.httpServer.Close()
+		return fmt.Errorf("failed to shut down server gracefully: %w", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped with an error: %w", err)
+	}
+
+	return nil
+}
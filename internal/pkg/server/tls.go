@@ -0,0 +1,117 @@
+// Package server runs the HTTP server of the application with configurable timeouts,
+// optional TLS and a graceful shutdown that lets in-flight requests finish.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// certReloader serves a TLS certificate from files and reloads it when the files change,
+// so that renewed certificates are picked up without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time // Latest modification time of the two files when they were loaded
+}
+
+// newCertReloader loads the certificate and key, failing if they cannot be used.
+func newCertReloader(certFile, keyFile string, interval time.Duration, logger *zap.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger,
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate; it is used as tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Run checks the files for changes at every interval until the context is cancelled.
+// A certificate that fails to load is logged and the previous one stays in use.
+// Reloading is disabled when the interval is zero.
+func (r *certReloader) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				r.logger.Error("Failed to reload TLS certificate", zap.Error(err))
+			} else if reloaded {
+				r.logger.Info("Reloaded TLS certificate", zap.String("cert_file", r.certFile))
+			}
+		}
+	}
+}
+
+// reload loads the certificate if either file has changed since it was last loaded.
+// Returns whether a new certificate was loaded.
+func (r *certReloader) reload() (bool, error) {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// latestModTime returns the most recent modification time of the given files.
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read TLS certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,99 @@
+// Package tracing sets up OpenTelemetry tracing of requests through the handlers, services
+// and repositories, with spans exported to an OTLP collector. Tracing is optional: without
+// an exporter endpoint, spans are not recorded, but trace context is still propagated.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/verigate/verigate-server/internal/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// instrumentationName identifies the spans created by this application.
+const instrumentationName = "github.com/verigate/verigate-server"
+
+// Setup installs the global tracer provider and W3C trace context propagation.
+// Spans are exported over OTLP/HTTP when an exporter endpoint is configured; the exporter
+// also honours the other standard OTEL_EXPORTER_OTLP_* variables, such as headers.
+// Returns a function that flushes the remaining spans and stops the exporter.
+func Setup(ctx context.Context, logger *zap.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if config.AppConfig.TracingEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := NewTracerProvider(exporter, config.AppConfig.TracingSampleRatio)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("Tracing error", zap.Error(err))
+	}))
+
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider that samples the given ratio of new traces,
+// follows the sampling decision of incoming requests, and exports spans in batches.
+// Tests can pass an in-memory exporter from go.opentelemetry.io/otel/sdk/trace/tracetest
+// in place of a collector, and flush the provider before reading the spans.
+func NewTracerProvider(exporter sdktrace.SpanExporter, sampleRatio float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.AppConfig.TracingServiceName))),
+	)
+}
+
+// Start starts a span as a child of the span in the context, if any.
+// The caller must end the returned span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts the span of an incoming request.
+func StartServer(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// StartClient starts the span of a call to another service, such as a database.
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// LogFields returns the trace and span IDs of the span in the context as log fields,
+// so that log entries can be matched with their trace. Returns nil without a span.
+func LogFields(ctx context.Context) []zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	}
+}
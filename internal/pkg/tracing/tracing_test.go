@@ -0,0 +1,178 @@
+package tracing_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/verigate/verigate-server/internal/app/auth"
+	"github.com/verigate/verigate-server/internal/app/client"
+	"github.com/verigate/verigate-server/internal/app/scope"
+	"github.com/verigate/verigate-server/internal/app/token"
+	"github.com/verigate/verigate-server/internal/pkg/config"
+	"github.com/verigate/verigate-server/internal/pkg/db/postgres"
+	"github.com/verigate/verigate-server/internal/pkg/db/redis"
+	"github.com/verigate/verigate-server/internal/pkg/middleware"
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
+	jwtutil "github.com/verigate/verigate-server/internal/pkg/utils/jwt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// statement returns the db.statement attribute of a span.
+func statement(span tracetest.SpanStub) string {
+	for _, attr := range span.Attributes {
+		if attr.Key == semconv.DBStatementKey {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestRequestTrace(t *testing.T) {
+	config.AppConfig.JWTAccessExpiry = "15m"
+	config.AppConfig.JWTRefreshExpiry = "168h"
+	config.AppConfig.JWTIDTokenExpiry = "1h"
+	config.AppConfig.ClientJWKSCacheTTL = "1h"
+	config.AppConfig.TracingServiceName = "verigate-test"
+
+	// Spans are recorded in memory; the global provider must be set before the database is opened
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider(exporter, 1)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := jwtutil.NewSigningKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jwtutil.SetKeys([]*jwtutil.SigningKey{key}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// PostgreSQL and Redis are replaced by sqlmock and miniredis behind the traced clients
+	_, mock, err := sqlmock.NewWithDSN("tracing-test", sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := postgres.Open("sqlmock", "tracing-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mock.ExpectQuery("SELECT is_revoked FROM access_tokens WHERE token_id = $1").
+		WithArgs("token-id").
+		WillReturnRows(sqlmock.NewRows([]string{"is_revoked"}).AddRow(false))
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	authService := auth.NewService(redis.NewAuthRepository(redisClient), nil)
+	tokenService := token.NewService(
+		postgres.NewTokenRepository(db),
+		redis.NewCacheRepository(redisClient),
+		authService,
+		client.NewService(postgres.NewClientRepository(db), authService),
+		scope.NewService(postgres.NewScopeRepository(db)),
+	)
+
+	core, logs := observer.New(zap.InfoLevel)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Tracing(), middleware.RequestLogger(zap.New(core)))
+	router.GET("/validate", func(c *gin.Context) {
+		if _, err := tokenService.ValidateAccessToken(c.Request.Context(), c.Query("token")); err != nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	accessToken, err := jwtutil.GenerateCustomToken(1, jwtutil.TokenIssuer, jwtutil.TokenTypeAccess, "token-id", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/validate?token="+accessToken, nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNoContent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+
+	var request, service, redisSpan, postgresSpan *tracetest.SpanStub
+	for i := range spans {
+		span := &spans[i]
+		switch {
+		case span.Name == "GET /validate":
+			request = span
+		case span.Name == "token.Service.ValidateAccessToken":
+			service = span
+		case span.Name == "redis get":
+			redisSpan = span
+		case strings.Contains(statement(*span), "access_tokens"):
+			postgresSpan = span
+		}
+	}
+	if request == nil || service == nil || redisSpan == nil || postgresSpan == nil {
+		t.Fatalf("missing spans: request %v, service %v, redis %v, postgres %v", request != nil, service != nil, redisSpan != nil, postgresSpan != nil)
+	}
+
+	// The spans form one tree: request, then service, then the database calls
+	if request.SpanKind != trace.SpanKindServer {
+		t.Errorf("request span kind = %v, want server", request.SpanKind)
+	}
+	if service.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Error("service span is not a child of the request span")
+	}
+	for name, span := range map[string]*tracetest.SpanStub{"redis": redisSpan, "postgres": postgresSpan} {
+		if span.SpanContext.TraceID() != request.SpanContext.TraceID() {
+			t.Errorf("%s span is in another trace", name)
+		}
+		if span.Parent.SpanID() != service.SpanContext.SpanID() {
+			t.Errorf("%s span is not a child of the service span", name)
+		}
+	}
+
+	// Statements name the operation, but neither the secret part of keys nor parameter values
+	if got := statement(*redisSpan); got != "get access_token:?" {
+		t.Errorf("redis statement = %q, want %q", got, "get access_token:?")
+	}
+	if got := statement(*postgresSpan); got != "SELECT is_revoked FROM access_tokens WHERE token_id = $1" {
+		t.Errorf("postgres statement = %q", got)
+	}
+
+	// The request log entry carries the trace ID
+	entries := logs.FilterMessage("request processed").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d requests, want 1", len(entries))
+	}
+	if traceID := entries[0].ContextMap()["trace_id"]; traceID != request.SpanContext.TraceID().String() {
+		t.Errorf("logged trace_id = %v, want %s", traceID, request.SpanContext.TraceID())
+	}
+}
@@ -49,6 +49,11 @@ const (
 	ErrMsgUnsupportedResponseType = "unsupported_response_type"
 	ErrMsgInvalidClient           = "invalid_client"
 	ErrMsgInvalidGrant            = "invalid_grant"
+	ErrMsgUnauthorizedClient      = "unauthorized_client"
+	ErrMsgAuthorizationPending    = "authorization_pending"
+	ErrMsgSlowDown                = "slow_down"
+	ErrMsgExpiredToken            = "expired_token"
+	ErrMsgInvalidTarget           = "invalid_target"
 	ErrMsgAccessDenied            = "access_denied"
 	ErrMsgUserDeniedAccess        = "user denied access"
 
@@ -65,6 +70,7 @@ const (
 	ErrMsgTokenIdRequired               = "token ID is required"
 	ErrMsgFailedToGenerateAccessToken   = "failed to generate access token"
 	ErrMsgFailedToGenerateRefreshToken  = "failed to generate refresh token"
+	ErrMsgFailedToGenerateIDToken       = "failed to generate ID token"
 	ErrMsgRefreshTokenNotIssuedToClient = "refresh token was not issued to this client"
 	ErrMsgRequestedScopeExceedsOriginal = "requested scope exceeds original scope"
 	ErrMsgTokenNotBelongToClient        = "token does not belong to client"
@@ -79,6 +85,21 @@ const (
 	ErrMsgNotAuthorizedForClient      = "not authorized to update this client"
 	ErrMsgNotAuthorizedToDeleteClient = "not authorized to delete this client"
 
+	// Client registration errors
+	ErrMsgUnsupportedClientGrantType         = "unsupported grant type '%s'"
+	ErrMsgUnsupportedClientResponseType      = "unsupported response type '%s'"
+	ErrMsgUnsupportedTokenEndpointAuthMethod = "unsupported token_endpoint_auth_method '%s'"
+	ErrMsgAuthMethodNotAllowedForClientType  = "token_endpoint_auth_method '%s' is not allowed for this client type"
+	ErrMsgResponseTypeRequiresGrantType      = "response type '%s' requires the '%s' grant type"
+	ErrMsgInvalidTokenLifetime               = "token lifetimes must not be negative"
+	ErrMsgInvalidConsentLifetime             = "consent_lifetime must not be negative"
+	ErrMsgRequiredScopeNotAllowed            = "required scope '%s' is not in the client's scope"
+	ErrMsgClientKeysRequired                 = "token_endpoint_auth_method '%s' requires exactly one of jwks and jwks_uri"
+	ErrMsgInvalidClientJWKS                  = "invalid jwks: %s"
+	ErrMsgInvalidClientJWKSURI               = "jwks_uri must be an absolute https URL"
+	ErrMsgFailedToFetchClientJWKS            = "failed to fetch client JWK set"
+	ErrMsgFailedToRecordClientAssertion      = "failed to record client assertion"
+
 	// OAuth-related additional errors
 	ErrMsgAuthorizationCodeNotFound  = "authorization code not found"
 	ErrMsgInvalidRedirectUri         = "invalid_redirect_uri"
@@ -96,6 +117,53 @@ const (
 	ErrMsgFailedToDeleteExpiredCodes = "failed to delete expired codes"
 	ErrMsgInvalidBasicAuthFormat     = "invalid basic auth format"
 	ErrMsgMissingClientId            = "missing client_id"
+	ErrMsgInvalidClientAssertion     = "invalid client assertion"
+
+	// Pushed authorization request errors
+	ErrMsgInvalidRequestURI                         = "invalid_request_uri"
+	ErrMsgPushedAuthorizationRequired               = "pushed authorization request required"
+	ErrMsgFailedToGenerateRequestURI                = "failed to generate request URI"
+	ErrMsgFailedToSavePushedAuthorizationRequest    = "failed to save pushed authorization request"
+	ErrMsgFailedToFindPushedAuthorizationRequest    = "failed to find pushed authorization request"
+	ErrMsgFailedToDeletePushedAuthorizationRequest  = "failed to delete pushed authorization request"
+	ErrMsgFailedToMarshalPushedAuthorizationRequest = "failed to marshal pushed authorization request"
+
+	// Login session errors
+	ErrMsgLoginRequired          = "login_required"
+	ErrMsgConsentRequired        = "consent_required"
+	ErrMsgFailedToCreateSession  = "failed to create session"
+	ErrMsgFailedToSaveSession    = "failed to save session"
+	ErrMsgFailedToFindSession    = "failed to find session"
+	ErrMsgFailedToDeleteSession  = "failed to delete session"
+	ErrMsgFailedToMarshalSession = "failed to marshal session"
+	ErrMsgFailedToRenderPage     = "failed to render page"
+	ErrMsgAssetNotFound          = "asset not found"
+
+	// Web session errors
+	ErrMsgWebSessionNotFound       = "session not found"
+	ErrMsgWebSessionRevoked        = "session has been revoked"
+	ErrMsgFailedToRevokeWebSession = "failed to revoke session"
+	ErrMsgFailedToCheckWebSession  = "failed to check session"
+
+	// Authorization session errors
+	ErrMsgInvalidAuthorizationSession         = "invalid or expired authorization session"
+	ErrMsgInvalidCSRFToken                    = "invalid CSRF token"
+	ErrMsgFailedToCreateAuthorizationSession  = "failed to create authorization session"
+	ErrMsgFailedToSaveAuthorizationSession    = "failed to save authorization session"
+	ErrMsgFailedToFindAuthorizationSession    = "failed to find authorization session"
+	ErrMsgFailedToDeleteAuthorizationSession  = "failed to delete authorization session"
+	ErrMsgFailedToMarshalAuthorizationSession = "failed to marshal authorization session"
+	ErrMsgRequiredScopeDeclined               = "scope '%s' is required by the client and cannot be declined"
+
+	// Device authorization errors
+	ErrMsgInvalidUserCode                    = "invalid or expired user code"
+	ErrMsgDeviceAuthorizationAlreadyHandled  = "device authorization has already been approved or denied"
+	ErrMsgFailedToGenerateDeviceCode         = "failed to generate device code"
+	ErrMsgFailedToSaveDeviceAuthorization    = "failed to save device authorization"
+	ErrMsgFailedToFindDeviceAuthorization    = "failed to find device authorization"
+	ErrMsgFailedToDeleteDeviceAuthorization  = "failed to delete device authorization"
+	ErrMsgFailedToRecordDevicePoll           = "failed to record device poll"
+	ErrMsgFailedToMarshalDeviceAuthorization = "failed to marshal device authorization"
 
 	// IP control errors
 	ErrMsgAccessDeniedIp    = "access denied from your IP address"
@@ -114,6 +182,7 @@ const (
 	ErrMsgErrorIteratingRefreshTokens          = "error iterating refresh tokens"
 	ErrMsgFailedToRevokeRefreshToken           = "failed to revoke refresh token"
 	ErrMsgFailedToRevokeRefreshTokens          = "failed to revoke refresh tokens"
+	ErrMsgFailedToDeleteExpiredTokens          = "failed to delete expired tokens"
 	ErrMsgFailedToFindAuthCode                 = "Failed to find authorization code"
 	ErrMsgFailedToUpdateUserConsent            = "Failed to update user consent"
 	ErrMsgUserConsentNotFoundForUser           = "User consent not found for user ID %d"
@@ -121,6 +190,8 @@ const (
 	ErrMsgUserConsentNotFoundForUserAndClient  = "User consent not found for user ID %d and client ID %s"
 	ErrMsgFailedToDeleteUserConsent            = "Failed to delete user consent"
 	ErrMsgFailedToFindUserConsent              = "Failed to find user consent"
+	ErrMsgFailedToListUserConsents             = "failed to list user consents"
+	ErrMsgConsentNotFound                      = "consent not found"
 	ErrMsgFailedToFindRefreshTokenByHash       = "failed to find refresh token by hash"
 	ErrMsgFailedToCountRefreshTokens           = "failed to count refresh tokens"
 	ErrMsgFailedToGetRefreshTokens             = "failed to get refresh tokens"
@@ -138,6 +209,24 @@ const (
 	ErrMsgFailedToDeleteClient             = "Failed to delete client"
 	ErrMsgFailedToUpdateClientStatus       = "Failed to update client status"
 	ErrMsgClientWithIDNotFound             = "Client with ID %d not found"
+	ErrMsgUnsupportedIDTokenSigningAlg     = "unsupported id_token_signed_response_alg"
+
+	// Signing key errors
+	ErrMsgKeyRotationDisabled        = "signing key rotation is not enabled"
+	ErrMsgNoActiveSigningKey         = "no active signing key"
+	ErrMsgSigningKeyRotatedElsewhere = "signing key was rotated concurrently"
+	ErrMsgFailedToGenerateSigningKey = "failed to generate signing key"
+	ErrMsgFailedToEncryptSigningKey  = "failed to encrypt signing key"
+	ErrMsgFailedToDecryptSigningKey  = "failed to decrypt signing key"
+	ErrMsgFailedToLoadSigningKeys    = "failed to load signing keys"
+	ErrMsgFailedToSaveSigningKey     = "failed to save signing key"
+	ErrMsgFailedToFindSigningKeys    = "failed to find signing keys"
+	ErrMsgFailedToScanSigningKey     = "failed to scan signing key"
+	ErrMsgErrorIteratingSigningKeys  = "error iterating signing keys"
+	ErrMsgFailedToRotateSigningKey   = "failed to rotate signing key"
+	ErrMsgFailedToRetireSigningKeys  = "failed to retire signing keys"
+	ErrMsgAdminAPIDisabled           = "admin API is not enabled"
+	ErrMsgInvalidAdminCredentials    = "invalid admin credentials"
 
 	// User Repository Errors
 
@@ -152,11 +241,23 @@ const (
 	ErrMsgFailedToScanDefaultScopeData      = "Failed to scan default scope data"
 	ErrMsgErrorIteratingDefaultScopeResults = "Error iterating default scope results"
 
+	// Audit log errors
+	ErrMsgFailedToSaveAuditLog    = "Failed to save audit log"
+	ErrMsgFailedToCountAuditLogs  = "Failed to count audit logs"
+	ErrMsgFailedToFindAuditLogs   = "Failed to find audit logs"
+	ErrMsgFailedToScanAuditLog    = "Failed to scan audit log"
+	ErrMsgErrorIteratingAuditLogs = "Error iterating audit logs"
+
 	// Redis cache errors
 	ErrMsgFailedToMarshalRefreshToken        = "failed to marshal refresh token"
 	ErrMsgFailedToUnmarshalRefreshToken      = "failed to unmarshal refresh token"
 	ErrMsgFailedToMarshalUpdatedRefreshToken = "failed to marshal updated refresh token"
 	ErrMsgFailedToGetRefreshToken            = "failed to get refresh token"
+	ErrMsgFailedToPruneUserTokenSets         = "failed to prune user token sets"
+
+	// Background job errors
+	ErrMsgFailedToAcquireJobLock = "failed to acquire job lock"
+	ErrMsgFailedToRecordJobRun   = "failed to record job run"
 
 	// Generic errors
 	ErrMsgInternalServerError = "internal_server_error"
@@ -1,8 +1,15 @@
 // Package hash provides password hashing and verification functions.
-// It uses bcrypt for secure password management.
+// It uses bcrypt for secure password management, and keyed SHA-256 digests
+// for random tokens, which are long enough not to need a slow hash.
 package hash
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/verigate/verigate-server/internal/pkg/tracing"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -20,3 +27,27 @@ func HashPassword(password string) (string, error) {
 func CompareHashAndPassword(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
+
+// CompareHashAndPasswordContext is CompareHashAndPassword recorded as a span of the trace
+// in the context. bcrypt is slow by design, and often accounts for most of a request's latency.
+func CompareHashAndPasswordContext(ctx context.Context, hash, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	return CompareHashAndPassword(hash, password)
+}
+
+// Token computes the digest of a random token for storage: the hex-encoded HMAC-SHA256
+// of the token under a server-side key. Unlike bcrypt it is deterministic and fast,
+// which is safe only for tokens with enough entropy to rule out guessing.
+func Token(token, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CompareToken verifies a token against a digest computed by Token, in constant time.
+// Returns true if the token matches.
+func CompareToken(digest, token, key string) bool {
+	return hmac.Equal([]byte(digest), []byte(Token(token, key)))
+}
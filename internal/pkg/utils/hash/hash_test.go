@@ -0,0 +1,20 @@
+package hash
+
+import "testing"
+
+func TestToken(t *testing.T) {
+	digest := Token("token", "key")
+
+	if digest != Token("token", "key") {
+		t.Error("digest is not deterministic")
+	}
+	if digest == Token("token", "other-key") {
+		t.Error("digest does not depend on the key")
+	}
+	if !CompareToken(digest, "token", "key") {
+		t.Error("token does not match its digest")
+	}
+	if CompareToken(digest, "other-token", "key") || CompareToken(digest, "token", "other-key") {
+		t.Error("digest matched another token or key")
+	}
+}
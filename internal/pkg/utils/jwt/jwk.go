@@ -0,0 +1,239 @@
+// Package jwt provides utilities for creating and validating JWT tokens
+// used throughout the application for authentication and authorization.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// JWK represents a single public JSON Web Key as defined in RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`           // Key type ("RSA", "EC" or "OKP")
+	Use string `json:"use,omitempty"` // Intended use of the key ("sig")
+	Alg string `json:"alg,omitempty"` // Algorithm the key is used with
+	Kid string `json:"kid,omitempty"` // Key identifier matching the JWT "kid" header
+	Crv string `json:"crv,omitempty"` // Curve name for EC and OKP keys
+	N   string `json:"n,omitempty"`   // RSA modulus (base64url)
+	E   string `json:"e,omitempty"`   // RSA public exponent (base64url)
+	X   string `json:"x,omitempty"`   // EC x coordinate or OKP public key (base64url)
+	Y   string `json:"y,omitempty"`   // EC y coordinate (base64url)
+}
+
+// JWKS represents a JSON Web Key Set as served from the jwks_uri endpoint.
+type JWKS struct {
+	Keys []JWK `json:"keys"` // Published public keys
+}
+
+// ParseJWKS parses a JSON Web Key Set, such as one registered by a client.
+func ParseJWKS(data []byte) (JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return JWKS{}, fmt.Errorf("invalid JWK set: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		return JWKS{}, fmt.Errorf("JWK set contains no keys")
+	}
+	return set, nil
+}
+
+// PublicKey converts the JWK into a public key usable for signature verification.
+// RSA keys, P-256 EC keys and Ed25519 OKP keys are supported.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKParam(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKParam(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA public exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if k.Crv != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("unsupported elliptic curve: %s", k.Crv)
+		}
+		x, err := decodeJWKParam(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKParam(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve: %s", k.Crv)
+		}
+		x, err := decodeJWKParam(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// ErrKeyNotFound is returned by the key function of a JWK set when no key matches the token.
+var ErrKeyNotFound = errors.New("no matching key in JWK set")
+
+// KeyFuncForJWKS returns a key function that verifies tokens with the signing keys of a JWK set.
+// The key is selected by the "kid" header, or used directly when the set holds a single key.
+// The token's algorithm must be the one used with the key type (RS256, ES256 or EdDSA)
+// and match the key's "alg" member when present.
+func KeyFuncForJWKS(set JWKS) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header[HeaderKeyID].(string)
+
+		var candidates []JWK
+		for _, key := range set.Keys {
+			if key.Use != "" && key.Use != "sig" {
+				continue
+			}
+			if kid == "" || key.Kid == kid {
+				candidates = append(candidates, key)
+			}
+		}
+		if len(candidates) != 1 {
+			return nil, ErrKeyNotFound
+		}
+		key := candidates[0]
+
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		algorithm, err := algorithmForKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != algorithm || (key.Alg != "" && key.Alg != algorithm) {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return publicKey, nil
+	}
+}
+
+// decodeJWKParam decodes a base64url-encoded JWK member.
+func decodeJWKParam(value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing JWK parameter")
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK parameter: %w", err)
+	}
+	return decoded, nil
+}
+
+// PublicJWKS returns the JSON Web Key Set containing every key currently accepted
+// for verification, including the upcoming and previous keys around a rotation.
+// Resource servers use it to verify issued tokens.
+func PublicJWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range verificationKeys() {
+		jwk, err := publicJWK(key.PublicKey)
+		if err != nil {
+			continue
+		}
+		jwk.Use = "sig"
+		jwk.Alg = key.Algorithm
+		jwk.Kid = key.ID
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// publicJWK converts a public key into the key-type specific members of its JWK representation.
+func publicJWK(publicKey crypto.PublicKey) (JWK, error) {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the full field size as required by RFC 7518
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of a public key.
+// The required members are serialized in lexicographic order without whitespace.
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	var canonical []byte
+	switch jwk.Kty {
+	case "RSA":
+		canonical, err = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{E: jwk.E, Kty: jwk.Kty, N: jwk.N})
+	case "EC":
+		canonical, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X, Y: jwk.Y})
+	default:
+		canonical, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X})
+	}
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
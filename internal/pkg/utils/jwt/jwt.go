@@ -3,7 +3,9 @@
 package jwt
 
 import (
-	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -19,15 +21,28 @@ const (
 	TokenIssuer      = "oauth-server" // Issuer value for all JWT tokens
 
 	// JWT claim key constants
-	ClaimKeyJTI    = "jti"     // JWT ID claim
-	ClaimKeySub    = "sub"     // Subject claim (user ID)
-	ClaimKeyAud    = "aud"     // Audience claim (client ID)
-	ClaimKeyScope  = "scope"   // Scope claim
-	ClaimKeyIAT    = "iat"     // Issued At claim
-	ClaimKeyEXP    = "exp"     // Expiration claim
-	ClaimKeyISS    = "iss"     // Issuer claim
-	ClaimKeyType   = "type"    // Token type claim
-	ClaimKeyUserID = "user_id" // Custom user ID claim
+	ClaimKeyJTI      = "jti"       // JWT ID claim
+	ClaimKeySub      = "sub"       // Subject claim (user ID, or client ID for client-only tokens)
+	ClaimKeyAud      = "aud"       // Audience claim (client ID)
+	ClaimKeyScope    = "scope"     // Scope claim
+	ClaimKeyIAT      = "iat"       // Issued At claim
+	ClaimKeyEXP      = "exp"       // Expiration claim
+	ClaimKeyISS      = "iss"       // Issuer claim
+	ClaimKeyType     = "type"      // Token type claim
+	ClaimKeyUserID   = "user_id"   // Custom user ID claim
+	ClaimKeyClientID = "client_id" // Client the token was issued to (RFC 9068)
+	ClaimKeyAct      = "act"       // Acting party of a delegated token (RFC 8693)
+	ClaimKeySID      = "sid"       // Web login session the token belongs to
+
+	// OpenID Connect claim key constants
+	ClaimKeyAuthTime          = "auth_time"          // Time of the end-user authentication
+	ClaimKeyNonce             = "nonce"              // Client nonce echoed from the authorization request
+	ClaimKeyAtHash            = "at_hash"            // Access token hash
+	ClaimKeyName              = "name"               // End-user's full name
+	ClaimKeyPreferredUsername = "preferred_username" // End-user's preferred username
+	ClaimKeyPicture           = "picture"            // End-user's profile picture URL
+	ClaimKeyEmail             = "email"              // End-user's email address
+	ClaimKeyEmailVerified     = "email_verified"     // Whether the email address has been verified
 )
 
 // Claims represents the custom claims structure for JWT tokens.
@@ -38,38 +53,53 @@ type Claims struct {
 	jwt.RegisteredClaims        // Standard JWT claims (iss, exp, etc.)
 }
 
-var (
-	privateKey *rsa.PrivateKey // RSA private key for token signing
-	publicKey  *rsa.PublicKey  // RSA public key for token validation
-)
-
-// InitKeys initializes the JWT package by loading the RSA keys from configuration.
+// InitKeys initializes the JWT package by loading the key pair from configuration.
+// The configured key pair becomes the initial active signing key; it is replaced
+// by the persisted key set once the key service has loaded it.
 // Returns an error if the keys cannot be parsed or are not provided.
 func InitKeys() error {
+	key, err := BootstrapKey()
+	if err != nil {
+		return err
+	}
+	return SetKeys([]*SigningKey{key}, nil)
+}
+
+// BootstrapKey parses the key pair configured in JWT_PRIVATE_KEY and JWT_PUBLIC_KEY.
+// RSA, ECDSA P-256 and Ed25519 keys are accepted; the key type selects the algorithm.
+// Returns an error if the keys are missing, cannot be parsed or do not belong together.
+func BootstrapKey() (*SigningKey, error) {
 	// Validate that keys are provided
 	if config.AppConfig.JWTPrivateKey == "" {
-		return fmt.Errorf("JWT_PRIVATE_KEY environment variable is not set")
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY environment variable is not set")
 	}
 
 	if config.AppConfig.JWTPublicKey == "" {
-		return fmt.Errorf("JWT_PUBLIC_KEY environment variable is not set")
+		return nil, fmt.Errorf("JWT_PUBLIC_KEY environment variable is not set")
 	}
 
 	// Parse the private key
-	pk, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.AppConfig.JWTPrivateKey))
+	pk, err := ParsePrivateKeyPEM([]byte(config.AppConfig.JWTPrivateKey))
 	if err != nil {
-		return fmt.Errorf("failed to parse private key: %w", err)
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, err := NewSigningKey(pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load private key: %w", err)
 	}
-	privateKey = pk
 
 	// Parse the public key
-	pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(config.AppConfig.JWTPublicKey))
+	pub, err := ParsePublicKeyPEM([]byte(config.AppConfig.JWTPublicKey))
 	if err != nil {
-		return fmt.Errorf("failed to parse public key: %w", err)
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	if kid, err := thumbprint(pub); err != nil || kid != key.ID {
+		return nil, fmt.Errorf("JWT_PUBLIC_KEY does not match JWT_PRIVATE_KEY")
 	}
-	publicKey = pub
 
-	return nil
+	return key, nil
 }
 
 // GenerateToken creates a new JWT token for the specified user.
@@ -90,19 +120,14 @@ func GenerateToken(userID uint) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(privateKey)
+	return Sign(claims)
 }
 
 // GenerateCustomToken creates a JWT token with custom parameters.
 // It allows specifying the issuer, token type, and expiration duration.
+// A non-empty session ID is added as the sid claim.
 // Returns the signed token string or an error if signing fails.
-func GenerateCustomToken(userID uint, issuer string, tokenType string, tokenID string, expiry time.Duration) (string, error) {
-	// Verify that the private key is available
-	if privateKey == nil {
-		return "", fmt.Errorf("JWT private key not initialized")
-	}
-
+func GenerateCustomToken(userID uint, issuer string, tokenType string, tokenID string, sessionID string, expiry time.Duration) (string, error) {
 	now := time.Now()
 
 	claims := jwt.MapClaims{
@@ -114,21 +139,18 @@ func GenerateCustomToken(userID uint, issuer string, tokenType string, tokenID s
 		ClaimKeyType:   tokenType,
 		ClaimKeyUserID: userID,
 	}
+	if sessionID != "" {
+		claims[ClaimKeySID] = sessionID
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(privateKey)
+	return Sign(claims)
 }
 
 // ValidateToken validates a JWT token and returns the claims if valid.
 // This function verifies the token signature, expiration, and other standard validations.
 // Returns the parsed claims or an error if validation fails.
 func ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return publicKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, KeyFunc)
 
 	if err != nil {
 		return nil, err
@@ -146,12 +168,7 @@ func ValidateToken(tokenString string) (*Claims, error) {
 // It additionally verifies the token issuer matches the expected value.
 // Returns the parsed claims or an error if validation fails.
 func ValidateCustomToken(tokenString string, issuer string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return publicKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, KeyFunc)
 
 	if err != nil {
 		return nil, err
@@ -171,61 +188,53 @@ func ValidateCustomToken(tokenString string, issuer string) (*Claims, error) {
 // ValidateAccessTokenWithClaims validates an access token and verifies specific claims.
 // It checks the token's signature, expiration, type, and issuer.
 // This function is a more comprehensive validation suitable for access tokens.
-// Returns the user ID from the token or a detailed error if validation fails.
-func ValidateAccessTokenWithClaims(tokenString string, expectedIssuer string) (uint, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return publicKey, nil
-	})
+// Returns the user ID and the session ID (empty for tokens without a sid claim)
+// from the token or a detailed error if validation fails.
+func ValidateAccessTokenWithClaims(tokenString string, expectedIssuer string) (uint, string, error) {
+	token, err := jwt.Parse(tokenString, KeyFunc)
 
 	if err != nil {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidToken + ": " + err.Error())
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidToken + ": " + err.Error())
 	}
 
 	if !token.Valid {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidToken)
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidToken)
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidTokenClaims)
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidTokenClaims)
 	}
 
 	// Check token type
 	tokenType, ok := claims[ClaimKeyType].(string)
 	if !ok || tokenType != TokenTypeAccess {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidTokenType)
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidTokenType)
 	}
 
 	// Check issuer
 	issuer, ok := claims[ClaimKeyISS].(string)
 	if !ok || issuer != expectedIssuer {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidTokenIssuer)
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidTokenIssuer)
 	}
 
 	// Extract user ID
 	userIDFloat, ok := claims[ClaimKeyUserID].(float64)
 	if !ok {
-		return 0, errors.Unauthorized(errors.ErrMsgInvalidUserID)
+		return 0, "", errors.Unauthorized(errors.ErrMsgInvalidUserID)
 	}
 
-	return uint(userIDFloat), nil
+	sessionID, _ := claims[ClaimKeySID].(string)
+
+	return uint(userIDFloat), sessionID, nil
 }
 
 // ValidateTokenForRevocation validates a token's format and extracts the token ID (jti).
 // This function is used when checking if a token has been revoked.
 // Returns the token ID from the token or an error if basic validation fails.
 func ValidateTokenForRevocation(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, errors.Unauthorized(errors.ErrMsgInvalidTokenFormat)
-		}
-		return publicKey, nil
-	})
+	token, err := jwt.Parse(tokenString, KeyFunc)
 
 	if err != nil {
 		return "", errors.Unauthorized(errors.ErrMsgInvalidToken)
@@ -244,3 +253,19 @@ func ValidateTokenForRevocation(tokenString string) (string, error) {
 
 	return tokenID, nil
 }
+
+// AccessTokenHash computes the OpenID Connect at_hash value for an access token.
+// It is the base64url encoding of the left-most half of the hash of the token,
+// using the hash function of the ID token's signing algorithm: SHA-256 for RS256
+// and ES256, and SHA-512 for EdDSA with Ed25519.
+func AccessTokenHash(accessToken, algorithm string) string {
+	var sum []byte
+	if algorithm == AlgorithmEdDSA {
+		digest := sha512.Sum512([]byte(accessToken))
+		sum = digest[:]
+	} else {
+		digest := sha256.Sum256([]byte(accessToken))
+		sum = digest[:]
+	}
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
@@ -0,0 +1,387 @@
+// Package jwt provides utilities for creating and validating JWT tokens
+// used throughout the application for authentication and authorization.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Header and algorithm constants
+const (
+	HeaderKeyID      = "kid"   // JWS header carrying the signing key identifier
+	AlgorithmRS256   = "RS256" // RSASSA-PKCS1-v1_5 using SHA-256
+	AlgorithmES256   = "ES256" // ECDSA using P-256 and SHA-256
+	AlgorithmEdDSA   = "EdDSA" // EdDSA using Ed25519
+	rsaKeySizeInBits = 2048    // Size of generated RSA signing keys
+)
+
+// supportedAlgorithms lists the signing algorithms keys can be created for.
+var supportedAlgorithms = []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA}
+
+// SigningKey is a key held in the in-memory key set.
+// Keys without a private key can only be used to verify tokens.
+type SigningKey struct {
+	ID         string           // Key identifier stamped in the JWT "kid" header
+	Algorithm  string           // JWS algorithm the key is used with
+	PrivateKey crypto.Signer    // Private key used for signing; nil for verification-only keys
+	PublicKey  crypto.PublicKey // Public key used for verification and published in the JWKS
+}
+
+// keySet holds the active signing keys and every key accepted for verification.
+// It is safe for concurrent use and can be swapped at runtime during key rotation.
+type keySet struct {
+	mu          sync.RWMutex
+	active      map[string]*SigningKey // Keys used to sign new tokens, indexed by algorithm
+	defaultAlg  string                 // Algorithm used when the caller does not request one
+	keys        map[string]*SigningKey // Verification keys indexed by key ID
+	order       []string               // Key IDs in publication order
+	legacyKeyID string                 // Key used to verify tokens issued without a kid header
+}
+
+// keys is the process-wide key set used by all signing and validation helpers.
+var keys = &keySet{
+	active: make(map[string]*SigningKey),
+	keys:   make(map[string]*SigningKey),
+}
+
+// SetKeys replaces the key set with new active signing keys and the keys accepted
+// for verification. There is at most one active key per algorithm; the algorithm
+// of the first active key becomes the default. Active keys are always accepted for verification.
+// Returns an error if no active key is given or an active key cannot be used for signing.
+func SetKeys(active []*SigningKey, verification []*SigningKey) error {
+	if len(active) == 0 {
+		return fmt.Errorf("at least one active signing key is required")
+	}
+
+	activeByAlg := make(map[string]*SigningKey, len(active))
+	indexed := make(map[string]*SigningKey)
+	var order []string
+
+	for _, key := range active {
+		if key == nil || key.PrivateKey == nil {
+			return fmt.Errorf("active signing key must include a private key")
+		}
+		if _, exists := activeByAlg[key.Algorithm]; exists {
+			return fmt.Errorf("more than one active signing key for %s", key.Algorithm)
+		}
+		activeByAlg[key.Algorithm] = key
+		indexed[key.ID] = key
+		order = append(order, key.ID)
+	}
+
+	for _, key := range verification {
+		if key == nil {
+			continue
+		}
+		if _, exists := indexed[key.ID]; exists {
+			continue
+		}
+		indexed[key.ID] = key
+		order = append(order, key.ID)
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	keys.active = activeByAlg
+	keys.defaultAlg = active[0].Algorithm
+	keys.keys = indexed
+	keys.order = order
+	if keys.legacyKeyID == "" {
+		keys.legacyKeyID = active[0].ID
+	}
+
+	return nil
+}
+
+// HasKeys reports whether an active signing key has been loaded.
+func HasKeys() bool {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	return len(keys.active) > 0
+}
+
+// ActiveKeyID returns the identifier of the key currently signing tokens with the given
+// algorithm, or with the default algorithm if none is given.
+func ActiveKeyID(algorithm string) string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	if algorithm == "" {
+		algorithm = keys.defaultAlg
+	}
+	if key, ok := keys.active[algorithm]; ok {
+		return key.ID
+	}
+	return ""
+}
+
+// DefaultAlgorithm returns the algorithm used to sign tokens when none is requested.
+func DefaultAlgorithm() string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	return keys.defaultAlg
+}
+
+// SigningAlgorithms returns the algorithms that currently have an active signing key,
+// starting with the default algorithm.
+func SigningAlgorithms() []string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	algorithms := []string{}
+	if keys.defaultAlg != "" {
+		algorithms = append(algorithms, keys.defaultAlg)
+	}
+	for _, alg := range supportedAlgorithms {
+		if _, ok := keys.active[alg]; ok && alg != keys.defaultAlg {
+			algorithms = append(algorithms, alg)
+		}
+	}
+	return algorithms
+}
+
+// IsSupportedAlgorithm reports whether signing keys can be created for the given algorithm.
+func IsSupportedAlgorithm(algorithm string) bool {
+	for _, alg := range supportedAlgorithms {
+		if alg == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign creates a JWT with the given claims, signed by the active key of the default algorithm.
+// The key identifier is stamped in the "kid" header so verifiers can
+// select the right key after a rotation.
+func Sign(claims jwt.Claims) (string, error) {
+	return SignWith("", claims)
+}
+
+// SignWith creates a JWT with the given claims, signed by the active key for the
+// requested algorithm. An empty algorithm selects the default algorithm.
+// Returns an error if no active key exists for the algorithm.
+func SignWith(algorithm string, claims jwt.Claims) (string, error) {
+	keys.mu.RLock()
+	if algorithm == "" {
+		algorithm = keys.defaultAlg
+	}
+	active := keys.active[algorithm]
+	keys.mu.RUnlock()
+
+	if active == nil {
+		return "", fmt.Errorf("no active signing key for algorithm %q", algorithm)
+	}
+
+	method := jwt.GetSigningMethod(active.Algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported signing algorithm: %s", active.Algorithm)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header[HeaderKeyID] = active.ID
+	return token.SignedString(active.PrivateKey)
+}
+
+// KeyFunc resolves the verification key for a parsed token.
+// The key is selected by the "kid" header; tokens issued before key identifiers
+// were introduced fall back to the originally configured key while it is still held.
+// The token's algorithm must match the algorithm registered for the key.
+func KeyFunc(token *jwt.Token) (interface{}, error) {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	kid, _ := token.Header[HeaderKeyID].(string)
+	if kid == "" {
+		kid = keys.legacyKeyID
+	}
+
+	key, ok := keys.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.PublicKey, nil
+}
+
+// verificationKeys returns a snapshot of all keys accepted for verification in publication order.
+func verificationKeys() []*SigningKey {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	result := make([]*SigningKey, 0, len(keys.order))
+	for _, id := range keys.order {
+		result = append(result, keys.keys[id])
+	}
+	return result
+}
+
+// NewSigningKey wraps a private key as a signing key.
+// The algorithm is derived from the key type: RSA keys sign with RS256, P-256 keys
+// with ES256 and Ed25519 keys with EdDSA. The key identifier is the RFC 7638
+// thumbprint of the public key.
+// Returns an error for unsupported key types or curves.
+func NewSigningKey(privateKey crypto.Signer) (*SigningKey, error) {
+	publicKey := privateKey.Public()
+
+	algorithm, err := algorithmForKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := thumbprint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:         kid,
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
+
+// GenerateSigningKey creates a new random signing key for the given algorithm.
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var privateKey crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, rsaKeySizeInBits)
+	case AlgorithmES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", algorithm, err)
+	}
+
+	return NewSigningKey(privateKey)
+}
+
+// MarshalPrivateKey encodes the private part of a signing key as a PKCS #8 PEM block.
+func MarshalPrivateKey(key *SigningKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKey encodes the public part of a signing key as a PKIX PEM block.
+func MarshalPublicKey(key *SigningKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKeyPEM decodes a PEM-encoded private key.
+// PKCS #8, PKCS #1 (RSA) and SEC 1 (EC) encodings are accepted.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key encoding")
+}
+
+// ParsePublicKeyPEM decodes a PEM-encoded public key.
+// PKIX and PKCS #1 (RSA) encodings are accepted.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key encoding")
+}
+
+// ParseSigningKey restores a signing key from its stored identifier, algorithm and PEM encodings.
+// The private key PEM may be empty, in which case a verification-only key is returned.
+func ParseSigningKey(kid, algorithm, publicKeyPEM string, privateKeyPEM []byte) (*SigningKey, error) {
+	publicKey, err := ParsePublicKeyPEM([]byte(publicKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", kid, err)
+	}
+
+	if keyAlgorithm, err := algorithmForKey(publicKey); err != nil || keyAlgorithm != algorithm {
+		return nil, fmt.Errorf("key %s cannot be used with %s", kid, algorithm)
+	}
+
+	key := &SigningKey{
+		ID:        kid,
+		Algorithm: algorithm,
+		PublicKey: publicKey,
+	}
+
+	if len(privateKeyPEM) > 0 {
+		privateKey, err := ParsePrivateKeyPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key %s: %w", kid, err)
+		}
+		key.PrivateKey = privateKey
+	}
+
+	return key, nil
+}
+
+// algorithmForKey returns the JWS algorithm used with the given public key type.
+func algorithmForKey(publicKey crypto.PublicKey) (string, error) {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported elliptic curve: %s", pub.Curve.Params().Name)
+		}
+		return AlgorithmES256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
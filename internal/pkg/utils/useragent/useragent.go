@@ -0,0 +1,164 @@
+// Package useragent extracts the browser, operating system and device type from
+// User-Agent headers, so that users can recognize their sessions.
+// It only knows the common browsers and platforms; anything else is reported as unknown.
+package useragent
+
+import (
+	"strings"
+)
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceUnknown = "unknown"
+)
+
+// Unknown is reported for a browser or operating system that is not recognized.
+const Unknown = "Unknown"
+
+// UserAgent describes the client software of a request.
+type UserAgent struct {
+	Browser string `json:"browser"` // Browser name and major version, e.g. "Chrome 124"
+	OS      string `json:"os"`      // Operating system name and version, e.g. "macOS 10.15"
+	Device  string `json:"device"`  // Device type: desktop, mobile, tablet, bot or unknown
+}
+
+// browsers lists browser tokens in the order they are checked.
+// Many browsers include the tokens of others (Edge and Opera claim to be Chrome,
+// Chrome claims to be Safari), so more specific tokens come first.
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"EdgiOS/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Vivaldi/", "Vivaldi"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"}, // Safari reports its version in the Version token
+	{"curl/", "curl"},
+	{"PostmanRuntime/", "Postman"},
+}
+
+// Parse extracts the browser, operating system and device type from a User-Agent header.
+func Parse(header string) UserAgent {
+	if strings.TrimSpace(header) == "" {
+		return UserAgent{Browser: Unknown, OS: Unknown, Device: DeviceUnknown}
+	}
+
+	return UserAgent{
+		Browser: parseBrowser(header),
+		OS:      parseOS(header),
+		Device:  parseDevice(header),
+	}
+}
+
+// String returns a short description such as "Chrome 124 on Windows 10".
+func (ua UserAgent) String() string {
+	return ua.Browser + " on " + ua.OS
+}
+
+// parseBrowser returns the name and major version of the browser.
+func parseBrowser(header string) string {
+	for _, b := range browsers {
+		version, ok := tokenVersion(header, b.token)
+		if !ok {
+			continue
+		}
+		if b.token == "Version/" && !strings.Contains(header, "Safari/") {
+			continue
+		}
+		if major := strings.SplitN(version, ".", 2)[0]; major != "" {
+			return b.name + " " + major
+		}
+		return b.name
+	}
+	return Unknown
+}
+
+// parseOS returns the name and version of the operating system.
+func parseOS(header string) string {
+	switch {
+	case strings.Contains(header, "Windows NT"):
+		version, _ := tokenVersion(header, "Windows NT ")
+		switch version {
+		case "10.0":
+			return "Windows 10"
+		case "6.3":
+			return "Windows 8.1"
+		case "6.2":
+			return "Windows 8"
+		case "6.1":
+			return "Windows 7"
+		}
+		return "Windows"
+	case strings.Contains(header, "iPhone OS "), strings.Contains(header, "CPU OS "):
+		version, ok := tokenVersion(header, "iPhone OS ")
+		if !ok {
+			version, _ = tokenVersion(header, "CPU OS ")
+		}
+		if strings.Contains(header, "iPad") {
+			return withVersion("iPadOS", strings.ReplaceAll(version, "_", "."))
+		}
+		return withVersion("iOS", strings.ReplaceAll(version, "_", "."))
+	case strings.Contains(header, "Mac OS X"):
+		version, _ := tokenVersion(header, "Mac OS X ")
+		return withVersion("macOS", strings.ReplaceAll(version, "_", "."))
+	case strings.Contains(header, "Android"):
+		version, _ := tokenVersion(header, "Android ")
+		return withVersion("Android", version)
+	case strings.Contains(header, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(header, "Linux"):
+		return "Linux"
+	}
+	return Unknown
+}
+
+// parseDevice returns the type of device.
+func parseDevice(header string) string {
+	lower := strings.ToLower(header)
+	switch {
+	case strings.Contains(lower, "bot"), strings.Contains(lower, "crawler"), strings.Contains(lower, "spider"):
+		return DeviceBot
+	case strings.Contains(header, "iPad"), strings.Contains(header, "Tablet"),
+		strings.Contains(header, "Android") && !strings.Contains(header, "Mobile"):
+		return DeviceTablet
+	case strings.Contains(header, "Mobile"), strings.Contains(header, "iPhone"):
+		return DeviceMobile
+	case strings.Contains(header, "Windows"), strings.Contains(header, "Macintosh"),
+		strings.Contains(header, "X11"), strings.Contains(header, "CrOS"):
+		return DeviceDesktop
+	}
+	return DeviceUnknown
+}
+
+// tokenVersion returns the text following a token up to the next space, if the token occurs.
+func tokenVersion(header, token string) (string, bool) {
+	i := strings.Index(header, token)
+	if i < 0 {
+		return "", false
+	}
+	rest := header[i+len(token):]
+	if end := strings.IndexAny(rest, " ;)"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest, true
+}
+
+// withVersion appends a version to a name if there is one.
+func withVersion(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + " " + version
+}
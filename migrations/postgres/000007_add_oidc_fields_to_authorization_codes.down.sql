@@ -0,0 +1,4 @@
+-- Remove OpenID Connect fields from authorization codes
+ALTER TABLE authorization_codes
+DROP COLUMN IF EXISTS auth_time,
+DROP COLUMN IF EXISTS nonce;
@@ -0,0 +1,4 @@
+-- Add OpenID Connect fields to authorization codes
+ALTER TABLE authorization_codes
+ADD COLUMN nonce VARCHAR(255),
+ADD COLUMN auth_time TIMESTAMP;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id SERIAL PRIMARY KEY,
+    kid VARCHAR(255) NOT NULL UNIQUE,
+    algorithm VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    encrypted_private_key BYTEA,
+    public_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    activated_at TIMESTAMP,
+    deactivated_at TIMESTAMP,
+    retired_at TIMESTAMP
+);
+
+-- Only one key may be signing and only one key may be waiting to take over
+CREATE UNIQUE INDEX idx_signing_keys_single_active ON signing_keys(status) WHERE status IN ('active', 'next');
+
+CREATE INDEX idx_signing_keys_status ON signing_keys(status);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_signing_keys_single_active;
+
+CREATE UNIQUE INDEX idx_signing_keys_single_active ON signing_keys(status) WHERE status IN ('active', 'next');
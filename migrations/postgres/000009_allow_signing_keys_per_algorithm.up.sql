@@ -0,0 +1,4 @@
+-- Allow one active and one next key for each signing algorithm
+DROP INDEX IF EXISTS idx_signing_keys_single_active;
+
+CREATE UNIQUE INDEX idx_signing_keys_single_active ON signing_keys(algorithm, status) WHERE status IN ('active', 'next');
@@ -0,0 +1,3 @@
+-- Algorithm the client expects ID tokens to be signed with; empty means the server default
+ALTER TABLE clients
+ADD COLUMN id_token_signed_response_alg VARCHAR(20) NOT NULL DEFAULT '';
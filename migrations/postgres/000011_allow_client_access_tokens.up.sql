@@ -0,0 +1,3 @@
+-- Access tokens issued through the client_credentials grant have no user
+ALTER TABLE access_tokens
+ALTER COLUMN user_id DROP NOT NULL;
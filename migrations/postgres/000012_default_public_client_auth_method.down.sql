@@ -0,0 +1,3 @@
+UPDATE clients
+SET token_endpoint_auth_method = 'client_secret_basic'
+WHERE is_confidential = FALSE;
@@ -0,0 +1,5 @@
+-- Public clients cannot authenticate with a secret; the column previously defaulted
+-- to client_secret_basic for every client because it was never written
+UPDATE clients
+SET token_endpoint_auth_method = 'none'
+WHERE is_confidential = FALSE;
@@ -0,0 +1,3 @@
+-- Token exchange policy of the client (allowed audiences, impersonation); empty means no exchange
+ALTER TABLE clients
+ADD COLUMN token_exchange_policy JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+-- Clients that must push their authorization requests before redirecting the user (RFC 9126)
+ALTER TABLE clients
+ADD COLUMN require_pushed_authorization_requests BOOLEAN NOT NULL DEFAULT FALSE;
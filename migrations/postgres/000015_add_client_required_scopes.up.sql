@@ -0,0 +1,3 @@
+-- Scopes the user cannot deselect when granting consent to a client
+ALTER TABLE clients
+ADD COLUMN required_scopes TEXT[];
@@ -0,0 +1,3 @@
+ALTER TABLE clients
+DROP COLUMN IF EXISTS is_trusted,
+DROP COLUMN IF EXISTS consent_lifetime;
@@ -0,0 +1,5 @@
+-- Per-client consent lifetime in seconds (NULL uses the server default, 0 means consents
+-- never expire) and the trusted first-party flag that skips the consent page
+ALTER TABLE clients
+ADD COLUMN consent_lifetime INTEGER,
+ADD COLUMN is_trusted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+-- Refresh tokens record the family (grant) they were rotated from, so that a reused
+-- token can revoke all of its descendants. Existing tokens start their own family.
+ALTER TABLE refresh_tokens
+ADD COLUMN family_id VARCHAR(255);
+
+UPDATE refresh_tokens SET family_id = token_id;
+
+ALTER TABLE refresh_tokens
+ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+
+-- Token hashes used to be salted bcrypt hashes, which no presented token can be looked up by.
+-- Such tokens were never usable, so they are revoked rather than left looking active.
+UPDATE refresh_tokens SET is_revoked = true WHERE token_hash LIKE '$2%';
@@ -0,0 +1,6 @@
+-- Start time of the last run of each background job, shared by all replicas of the server,
+-- so that a job runs once per interval no matter how many replicas are running it.
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(255) PRIMARY KEY,
+    last_run_at TIMESTAMP WITH TIME ZONE NOT NULL
+);